@@ -0,0 +1,234 @@
+// Package api holds the public data transfer objects of the deck api.
+// They are shared by the server and the Go client.
+package api
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// Card represents a playing card. Fields that are not selected when opening a deck are empty and omitted.
+// FaceDown marks a card that lies face down, callers other than the owner of the deck see only its back,
+// which is a card without value, suit and code.
+type Card struct {
+	Value    string `json:"value,omitempty" xml:"value,omitempty"`
+	Suit     string `json:"suit,omitempty" xml:"suit,omitempty"`
+	Code     string `json:"code,omitempty" xml:"code,omitempty"`
+	FaceDown bool   `json:"face_down,omitempty" xml:"face_down,omitempty"`
+}
+
+// CreateResponse represents a response for creating a deck.
+type CreateResponse struct {
+	DeckId    string `json:"deck_id" xml:"deck_id"`
+	Shuffled  bool   `json:"shuffled" xml:"shuffled"`
+	Remaining int    `json:"remaining" xml:"remaining"`
+}
+
+// OpenResponse represents a response for opening a deck.
+// Version is the number of operations applied to the deck, e.g. draws, shuffles, batches and restores.
+// OnEmpty is the policy applied when a draw runs out of cards, it is omitted for the policy none.
+// Penetration is the state of the cut card of a shoe, it is omitted for decks without one.
+// Cards are null in summary mode, which counts the remaining cards per suit instead.
+// Hidden is the number of face down cards whose faces the caller does not see,
+// players see their backs in the cards while spectators do not see them at all.
+type OpenResponse struct {
+	DeckId      string       `json:"deck_id" xml:"deck_id"`
+	Shuffled    bool         `json:"shuffled" xml:"shuffled"`
+	Remaining   int          `json:"remaining" xml:"remaining"`
+	Version     int          `json:"version" xml:"version"`
+	OnEmpty     string       `json:"on_empty,omitempty" xml:"on_empty,omitempty"`
+	Penetration *Penetration `json:"penetration,omitempty" xml:"penetration,omitempty"`
+	FaceDown    bool         `json:"face_down,omitempty" xml:"face_down,omitempty"`
+	Hidden      int          `json:"hidden,omitempty" xml:"hidden,omitempty"`
+	Cards       []Card       `json:"cards" xml:"cards>card"`
+	Page        *Page        `json:"page,omitempty" xml:"page,omitempty"`
+	Suits       []SuitCount  `json:"suits,omitempty" xml:"suits>suit,omitempty"`
+}
+
+// Page describes a page of the remaining cards of a deck.
+type Page struct {
+	Offset int `json:"offset" xml:"offset"`
+	Limit  int `json:"limit" xml:"limit"`
+	// Total is the number of remaining cards of the deck the caller sees.
+	Total int `json:"total" xml:"total"`
+	// NextOffset is the offset of the next page, it is omitted on the last page.
+	NextOffset int `json:"next_offset,omitempty" xml:"next_offset,omitempty"`
+}
+
+// SuitCount is the number of remaining cards of a suit.
+type SuitCount struct {
+	Suit  string `json:"suit" xml:"suit"`
+	Count int    `json:"count" xml:"count"`
+}
+
+// DrawRequest represents a request to draw cards from a deck.
+type DrawRequest struct {
+	DeckId string `json:"deck_id" xml:"deck_id"`
+	Count  int    `json:"count" xml:"count"`
+}
+
+// DrawResponse represents a response for drawing cards from a deck.
+// Reshuffled is set when the deck ran out of cards during the draw and was restocked, or when the shoe
+// was reshuffled before the draw because its cut card came out. Penetration is the state of the cut card after the draw.
+type DrawResponse struct {
+	Cards       []Card       `json:"cards" xml:"cards>card"`
+	Reshuffled  *Reshuffle   `json:"reshuffled,omitempty" xml:"reshuffled,omitempty"`
+	Penetration *Penetration `json:"penetration,omitempty" xml:"penetration,omitempty"`
+}
+
+// Reshuffle describes a deck that ran out of cards during a draw: After cards were drawn when Cards cards
+// were shuffled into the deck according to its policy, e.g. reshuffle_discards, and the draw continued.
+// The policy cut_card is a reshuffle of the whole shoe after its cut card came out, see Penetration.
+type Reshuffle struct {
+	Policy string `json:"policy" xml:"policy"`
+	After  int    `json:"after" xml:"after"`
+	Cards  int    `json:"cards" xml:"cards"`
+}
+
+// Penetration is the state of the cut card of a shoe. The cut card comes out after CutCard cards,
+// Percent of the shoe, are dealt, Dealt counts the cards dealt since the shoe was shuffled.
+// ReshuffleNeeded reports that the cut card came out, AutoReshuffle reshuffles the shoe before the next round.
+type Penetration struct {
+	Percent         int  `json:"percent" xml:"percent"`
+	CutCard         int  `json:"cut_card" xml:"cut_card"`
+	Dealt           int  `json:"dealt" xml:"dealt"`
+	ReshuffleNeeded bool `json:"reshuffle_needed" xml:"reshuffle_needed"`
+	AutoReshuffle   bool `json:"auto_reshuffle" xml:"auto_reshuffle"`
+}
+
+// ShuffleRequest represents a request to shuffle the remaining cards of a deck.
+type ShuffleRequest struct {
+	DeckId string `json:"deck_id" xml:"deck_id"`
+}
+
+// ShuffleResponse represents a response for shuffling a deck.
+type ShuffleResponse struct {
+	DeckId    string `json:"deck_id" xml:"deck_id"`
+	Shuffled  bool   `json:"shuffled" xml:"shuffled"`
+	Remaining int    `json:"remaining" xml:"remaining"`
+}
+
+// BatchStep is a step of a batch: draw count cards, discard the drawn cards with the given codes
+// or shuffle the remaining cards.
+type BatchStep struct {
+	Op    string   `json:"op" xml:"op"`
+	Count int      `json:"count,omitempty" xml:"count,omitempty"`
+	Cards []string `json:"cards,omitempty" xml:"cards>card,omitempty"`
+}
+
+// BatchRequest represents a request to apply steps to a deck atomically.
+type BatchRequest struct {
+	Steps []BatchStep `json:"steps" xml:"steps>step"`
+}
+
+// StepResult is the result of a step of a batch, the cards it drew or discarded
+// and the number of remaining cards after the step. Reshuffled is set like in DrawResponse.
+type StepResult struct {
+	Op         string     `json:"op" xml:"op"`
+	Cards      []Card     `json:"cards,omitempty" xml:"cards>card,omitempty"`
+	Remaining  int        `json:"remaining" xml:"remaining"`
+	Reshuffled *Reshuffle `json:"reshuffled,omitempty" xml:"reshuffled,omitempty"`
+}
+
+// BatchResponse represents a response for applying a batch to a deck, it holds a result per step.
+type BatchResponse struct {
+	DeckId    string       `json:"deck_id" xml:"deck_id"`
+	Remaining int          `json:"remaining" xml:"remaining"`
+	Results   []StepResult `json:"results" xml:"results>result"`
+}
+
+// DeckExport is the portable representation of the state of a deck, it is exported from one environment
+// and imported into another one. Format and Version identify the representation, readers reject other versions.
+// Cards are the remaining cards from the top of the deck, Discards the discard pile from the bottom.
+// Cards are identified by their code, their value and suit are informative.
+// OnEmpty is omitted for the policy none, exports without it have the policy none.
+// Penetration and AutoReshuffle are omitted for decks without a cut card, an import places the cut card anew.
+// Composition are the cards the deck was created with, which the policies reshuffle_all and refill restock,
+// exports without it restock the remaining and the discarded cards.
+// The representation holds the current state only. Decks are shuffled from an unseeded source, so there is no
+// shuffle seed, and the history, i.e. the version, the earlier versions and the snapshots, is not exported:
+// an imported deck starts at version 0.
+type DeckExport struct {
+	Format   string `json:"format" xml:"format"`
+	Version  int    `json:"version" xml:"version"`
+	Id       string `json:"id" xml:"id"`
+	Shuffled bool   `json:"shuffled" xml:"shuffled"`
+	FaceDown bool   `json:"face_down" xml:"face_down"`
+	OnEmpty  string `json:"on_empty,omitempty" xml:"on_empty,omitempty"`
+	// Penetration is the percentage of the shoe dealt before its cut card comes out.
+	Penetration   int    `json:"penetration,omitempty" xml:"penetration,omitempty"`
+	AutoReshuffle bool   `json:"auto_reshuffle,omitempty" xml:"auto_reshuffle,omitempty"`
+	Cards         []Card `json:"cards" xml:"cards>card"`
+	Discards      []Card `json:"discards" xml:"discards>card"`
+	Composition   []Card `json:"composition,omitempty" xml:"composition>card,omitempty"`
+}
+
+// Snapshot describes a named snapshot of a deck, Version and Remaining are those of the deck
+// when the snapshot was taken.
+type Snapshot struct {
+	Name      string    `json:"name" xml:"name"`
+	DeckId    string    `json:"deck_id" xml:"deck_id"`
+	Version   int       `json:"version" xml:"version"`
+	Remaining int       `json:"remaining" xml:"remaining"`
+	TakenAt   time.Time `json:"taken_at" xml:"taken_at"`
+}
+
+// SnapshotList represents the snapshots of a deck ordered by name.
+type SnapshotList struct {
+	DeckId    string     `json:"deck_id" xml:"deck_id"`
+	Snapshots []Snapshot `json:"snapshots" xml:"snapshots>snapshot"`
+}
+
+// RestoreResponse represents a response for restoring a deck from a snapshot.
+// Version is the new version of the deck, restoring is an operation that does not rewrite the history.
+type RestoreResponse struct {
+	DeckId    string `json:"deck_id" xml:"deck_id"`
+	Snapshot  string `json:"snapshot" xml:"snapshot"`
+	Version   int    `json:"version" xml:"version"`
+	Remaining int    `json:"remaining" xml:"remaining"`
+}
+
+// ShareRequest represents a request to share a deck with other clients.
+type ShareRequest struct {
+	Scope      string `json:"scope" xml:"scope"`
+	MaxCards   int    `json:"max_cards" xml:"max_cards"`
+	TTLSeconds int    `json:"ttl_seconds" xml:"ttl_seconds"`
+}
+
+// ShareResponse represents a response for sharing a deck.
+type ShareResponse struct {
+	Token     string    `json:"token" xml:"token"`
+	DeckId    string    `json:"deck_id" xml:"deck_id"`
+	Scope     string    `json:"scope" xml:"scope"`
+	MaxCards  int       `json:"max_cards,omitempty" xml:"max_cards,omitempty"`
+	ExpiresAt time.Time `json:"expires_at" xml:"expires_at"`
+}
+
+// Problem is an error response in the RFC 7807 problem details format.
+// Type is a stable machine-readable identifier of the error, e.g. /problems/deck-not-found,
+// Title a short summary of the type and Detail an explanation of this occurrence.
+type Problem struct {
+	XMLName   xml.Name     `json:"-" xml:"urn:ietf:rfc:7807 problem"`
+	Type      string       `json:"type" xml:"type"`
+	Title     string       `json:"title" xml:"title"`
+	Status    int          `json:"status" xml:"status"`
+	Detail    string       `json:"detail,omitempty" xml:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty" xml:"instance,omitempty"`
+	RequestId string       `json:"request_id,omitempty" xml:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty" xml:"errors>error,omitempty"`
+}
+
+// FieldError describes why a field of a request is invalid.
+type FieldError struct {
+	Field  string `json:"field" xml:"field"`
+	Reason string `json:"reason" xml:"reason"`
+}
+
+func (x Problem) Error() string {
+	msg := fmt.Sprintf("%s (%d)", x.Title, x.Status)
+	if x.Detail != "" {
+		msg += ": " + x.Detail
+	}
+	return msg
+}
@@ -0,0 +1,189 @@
+// Package apiv2 holds the public data transfer objects of version 2 of the deck api.
+// Unlike version 1, whose DTOs double as the results of the deck service, these types are only
+// the public representation and are converted from the service results by the http layer.
+package apiv2
+
+import "time"
+
+// Card represents a playing card. FaceDown cards whose faces the caller does not see have no value, suit and code.
+type Card struct {
+	Value    string `json:"value,omitempty" xml:"value,omitempty"`
+	Suit     string `json:"suit,omitempty" xml:"suit,omitempty"`
+	Code     string `json:"code,omitempty" xml:"code,omitempty"`
+	FaceDown bool   `json:"face_down,omitempty" xml:"face_down,omitempty"`
+}
+
+// Page describes a page of the remaining cards of a deck. Total is the number of remaining cards
+// the caller sees, NextOffset the offset of the next page, it is omitted on the last page.
+type Page struct {
+	Offset     int `json:"offset" xml:"offset"`
+	Limit      int `json:"limit" xml:"limit"`
+	Total      int `json:"total" xml:"total"`
+	NextOffset int `json:"next_offset,omitempty" xml:"next_offset,omitempty"`
+}
+
+// SuitCount is the number of remaining cards of a suit.
+type SuitCount struct {
+	Suit  string `json:"suit" xml:"suit"`
+	Count int    `json:"count" xml:"count"`
+}
+
+// BatchStep is a step of a batch, it draws, discards or shuffles cards.
+type BatchStep struct {
+	Op    string   `json:"op" xml:"op"`
+	Count int      `json:"count,omitempty" xml:"count,omitempty"`
+	Cards []string `json:"cards,omitempty" xml:"cards>card,omitempty"`
+}
+
+// BatchRequest represents the steps of a batch, they are applied to a deck atomically.
+type BatchRequest struct {
+	Steps []BatchStep `json:"steps" xml:"steps>step"`
+}
+
+// StepResult is the result of a step of a batch, the cards it drew or discarded
+// and the number of remaining cards after the step.
+type StepResult struct {
+	Op         string     `json:"op" xml:"op"`
+	Cards      []Card     `json:"cards,omitempty" xml:"cards>card,omitempty"`
+	Remaining  int        `json:"remaining" xml:"remaining"`
+	Reshuffled *Reshuffle `json:"reshuffled,omitempty" xml:"reshuffled,omitempty"`
+}
+
+// Batch represents an applied batch, it holds a result per step.
+type Batch struct {
+	DeckId    string       `json:"deck_id" xml:"deck_id"`
+	Remaining int          `json:"remaining" xml:"remaining"`
+	Results   []StepResult `json:"results" xml:"results>result"`
+}
+
+// Links are the urls of a deck and of its sub-resources.
+type Links struct {
+	Self  string `json:"self" xml:"self"`
+	Draw  string `json:"draw" xml:"draw"`
+	Share string `json:"share" xml:"share"`
+}
+
+// Deck represents a deck without its cards, it is returned when a deck is created.
+type Deck struct {
+	Id        string `json:"id" xml:"id"`
+	Shuffled  bool   `json:"shuffled" xml:"shuffled"`
+	Remaining int    `json:"remaining" xml:"remaining"`
+	Links     Links  `json:"links" xml:"links"`
+}
+
+// DeckDetail represents a deck with its remaining cards, it is returned when a deck is opened.
+// Cards are null in summary mode, which counts the remaining cards per suit instead.
+// Hidden is the number of face down cards whose faces the caller does not see.
+// Version is the number of operations applied to the deck, OnEmpty the policy applied when a draw runs out of cards.
+// Penetration is the state of the cut card of a shoe.
+type DeckDetail struct {
+	Id          string       `json:"id" xml:"id"`
+	Shuffled    bool         `json:"shuffled" xml:"shuffled"`
+	Remaining   int          `json:"remaining" xml:"remaining"`
+	Version     int          `json:"version" xml:"version"`
+	OnEmpty     string       `json:"on_empty,omitempty" xml:"on_empty,omitempty"`
+	Penetration *Penetration `json:"penetration,omitempty" xml:"penetration,omitempty"`
+	FaceDown    bool         `json:"face_down,omitempty" xml:"face_down,omitempty"`
+	Hidden      int          `json:"hidden,omitempty" xml:"hidden,omitempty"`
+	Cards       []Card       `json:"cards" xml:"cards>card"`
+	Page        *Page        `json:"page,omitempty" xml:"page,omitempty"`
+	Suits       []SuitCount  `json:"suits,omitempty" xml:"suits>suit,omitempty"`
+	Links       Links        `json:"links" xml:"links"`
+}
+
+// DrawRequest represents the optional body of a draw, the count can be given as a query parameter instead.
+type DrawRequest struct {
+	Count int `json:"count" xml:"count"`
+}
+
+// Snapshot represents a named snapshot of a deck at one of its versions.
+type Snapshot struct {
+	Name      string    `json:"name" xml:"name"`
+	DeckId    string    `json:"deck_id" xml:"deck_id"`
+	Version   int       `json:"version" xml:"version"`
+	Remaining int       `json:"remaining" xml:"remaining"`
+	TakenAt   time.Time `json:"taken_at" xml:"taken_at"`
+}
+
+// SnapshotList represents the snapshots of a deck ordered by name.
+type SnapshotList struct {
+	DeckId    string     `json:"deck_id" xml:"deck_id"`
+	Snapshots []Snapshot `json:"snapshots" xml:"snapshots>snapshot"`
+}
+
+// Restore represents a deck restored from a snapshot, Version is the new version of the deck.
+type Restore struct {
+	DeckId    string `json:"deck_id" xml:"deck_id"`
+	Snapshot  string `json:"snapshot" xml:"snapshot"`
+	Version   int    `json:"version" xml:"version"`
+	Remaining int    `json:"remaining" xml:"remaining"`
+}
+
+// DeckExport is the portable representation of the state of a deck, exports of both versions of the api
+// have the same format and are imported by either of them. Cards are the remaining cards from the top of the deck,
+// Discards the discard pile from the bottom and Composition the cards the deck was created with.
+type DeckExport struct {
+	Format        string `json:"format" xml:"format"`
+	Version       int    `json:"version" xml:"version"`
+	Id            string `json:"id" xml:"id"`
+	Shuffled      bool   `json:"shuffled" xml:"shuffled"`
+	FaceDown      bool   `json:"face_down" xml:"face_down"`
+	OnEmpty       string `json:"on_empty,omitempty" xml:"on_empty,omitempty"`
+	Penetration   int    `json:"penetration,omitempty" xml:"penetration,omitempty"`
+	AutoReshuffle bool   `json:"auto_reshuffle,omitempty" xml:"auto_reshuffle,omitempty"`
+	Cards         []Card `json:"cards" xml:"cards>card"`
+	Discards      []Card `json:"discards" xml:"discards>card"`
+	Composition   []Card `json:"composition,omitempty" xml:"composition>card,omitempty"`
+}
+
+// Reshuffle describes a deck that ran out of cards during a draw and was restocked, or a reshuffled shoe.
+// After is the number of cards drawn before the restock, Cards the number of cards shuffled in.
+type Reshuffle struct {
+	Policy string `json:"policy" xml:"policy"`
+	After  int    `json:"after" xml:"after"`
+	Cards  int    `json:"cards" xml:"cards"`
+}
+
+// Penetration describes the cut card of a shoe, it comes out after CutCard cards, Percent of the shoe, are dealt.
+type Penetration struct {
+	Percent         int  `json:"percent" xml:"percent"`
+	CutCard         int  `json:"cut_card" xml:"cut_card"`
+	Dealt           int  `json:"dealt" xml:"dealt"`
+	ReshuffleNeeded bool `json:"reshuffle_needed" xml:"reshuffle_needed"`
+	AutoReshuffle   bool `json:"auto_reshuffle" xml:"auto_reshuffle"`
+}
+
+// Draw represents the cards drawn from a deck.
+type Draw struct {
+	DeckId      string       `json:"deck_id" xml:"deck_id"`
+	Cards       []Card       `json:"cards" xml:"cards>card"`
+	Reshuffled  *Reshuffle   `json:"reshuffled,omitempty" xml:"reshuffled,omitempty"`
+	Penetration *Penetration `json:"penetration,omitempty" xml:"penetration,omitempty"`
+}
+
+// ShareRequest represents a request to share a deck with other clients.
+type ShareRequest struct {
+	Scope      string `json:"scope" xml:"scope"`
+	MaxCards   int    `json:"max_cards" xml:"max_cards"`
+	TTLSeconds int    `json:"ttl_seconds" xml:"ttl_seconds"`
+}
+
+// Share represents a capability token of a deck.
+type Share struct {
+	Token     string    `json:"token" xml:"token"`
+	DeckId    string    `json:"deck_id" xml:"deck_id"`
+	Scope     string    `json:"scope" xml:"scope"`
+	MaxCards  int       `json:"max_cards,omitempty" xml:"max_cards,omitempty"`
+	ExpiresAt time.Time `json:"expires_at" xml:"expires_at"`
+}
+
+// DeckPath returns the path of the deck in version 2 of the api.
+func DeckPath(deckId string) string {
+	return "/api/v2/decks/" + deckId
+}
+
+// DeckLinks returns the links of the deck.
+func DeckLinks(deckId string) Links {
+	self := DeckPath(deckId)
+	return Links{Self: self, Draw: self + "/draw", Share: self + "/share"}
+}
@@ -0,0 +1,980 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: deckv1/deck.proto
+
+// Package deck.v1 is the gRPC transport of the deck api. It mirrors the
+// create, open and draw operations of the HTTP api.
+
+package deckv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Card represents a playing card.
+type Card struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Value string `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Suit  string `protobuf:"bytes,2,opt,name=suit,proto3" json:"suit,omitempty"`
+	Code  string `protobuf:"bytes,3,opt,name=code,proto3" json:"code,omitempty"`
+	// FaceDown hides the value and suit of the card, only the owner of the deck sees them.
+	FaceDown bool `protobuf:"varint,4,opt,name=face_down,json=faceDown,proto3" json:"face_down,omitempty"`
+}
+
+func (x *Card) Reset() {
+	*x = Card{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_deckv1_deck_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Card) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Card) ProtoMessage() {}
+
+func (x *Card) ProtoReflect() protoreflect.Message {
+	mi := &file_deckv1_deck_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Card.ProtoReflect.Descriptor instead.
+func (*Card) Descriptor() ([]byte, []int) {
+	return file_deckv1_deck_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Card) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *Card) GetSuit() string {
+	if x != nil {
+		return x.Suit
+	}
+	return ""
+}
+
+func (x *Card) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Card) GetFaceDown() bool {
+	if x != nil {
+		return x.FaceDown
+	}
+	return false
+}
+
+// Reshuffle reports that cards were shuffled into the deck during a draw.
+type Reshuffle struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Policy is the on_empty policy of the deck, or cut_card when the cut card of a shoe came out.
+	Policy string `protobuf:"bytes,1,opt,name=policy,proto3" json:"policy,omitempty"`
+	// After is the number of cards drawn before the reshuffle.
+	After int32 `protobuf:"varint,2,opt,name=after,proto3" json:"after,omitempty"`
+	// Cards is the number of cards shuffled into the deck.
+	Cards int32 `protobuf:"varint,3,opt,name=cards,proto3" json:"cards,omitempty"`
+}
+
+func (x *Reshuffle) Reset() {
+	*x = Reshuffle{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_deckv1_deck_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Reshuffle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reshuffle) ProtoMessage() {}
+
+func (x *Reshuffle) ProtoReflect() protoreflect.Message {
+	mi := &file_deckv1_deck_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reshuffle.ProtoReflect.Descriptor instead.
+func (*Reshuffle) Descriptor() ([]byte, []int) {
+	return file_deckv1_deck_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Reshuffle) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+func (x *Reshuffle) GetAfter() int32 {
+	if x != nil {
+		return x.After
+	}
+	return 0
+}
+
+func (x *Reshuffle) GetCards() int32 {
+	if x != nil {
+		return x.Cards
+	}
+	return 0
+}
+
+// Penetration reports the position of the cut card of a shoe.
+type Penetration struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Percent         int32 `protobuf:"varint,1,opt,name=percent,proto3" json:"percent,omitempty"`
+	CutCard         int32 `protobuf:"varint,2,opt,name=cut_card,json=cutCard,proto3" json:"cut_card,omitempty"`
+	Dealt           int32 `protobuf:"varint,3,opt,name=dealt,proto3" json:"dealt,omitempty"`
+	ReshuffleNeeded bool  `protobuf:"varint,4,opt,name=reshuffle_needed,json=reshuffleNeeded,proto3" json:"reshuffle_needed,omitempty"`
+	AutoReshuffle   bool  `protobuf:"varint,5,opt,name=auto_reshuffle,json=autoReshuffle,proto3" json:"auto_reshuffle,omitempty"`
+}
+
+func (x *Penetration) Reset() {
+	*x = Penetration{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_deckv1_deck_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Penetration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Penetration) ProtoMessage() {}
+
+func (x *Penetration) ProtoReflect() protoreflect.Message {
+	mi := &file_deckv1_deck_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Penetration.ProtoReflect.Descriptor instead.
+func (*Penetration) Descriptor() ([]byte, []int) {
+	return file_deckv1_deck_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Penetration) GetPercent() int32 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
+func (x *Penetration) GetCutCard() int32 {
+	if x != nil {
+		return x.CutCard
+	}
+	return 0
+}
+
+func (x *Penetration) GetDealt() int32 {
+	if x != nil {
+		return x.Dealt
+	}
+	return 0
+}
+
+func (x *Penetration) GetReshuffleNeeded() bool {
+	if x != nil {
+		return x.ReshuffleNeeded
+	}
+	return false
+}
+
+func (x *Penetration) GetAutoReshuffle() bool {
+	if x != nil {
+		return x.AutoReshuffle
+	}
+	return false
+}
+
+type CreateDeckRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Shuffled shuffles the new deck, the server default is used when absent.
+	Shuffled *bool `protobuf:"varint,1,opt,name=shuffled,proto3,oneof" json:"shuffled,omitempty"`
+	// Cards are card codes, e.g. AS or 10H, a full deck is created when empty.
+	Cards []string `protobuf:"bytes,2,rep,name=cards,proto3" json:"cards,omitempty"`
+	// FaceDown lays the cards face down, FaceUp are card codes that are turned face up nevertheless.
+	FaceDown bool     `protobuf:"varint,3,opt,name=face_down,json=faceDown,proto3" json:"face_down,omitempty"`
+	FaceUp   []string `protobuf:"bytes,4,rep,name=face_up,json=faceUp,proto3" json:"face_up,omitempty"`
+	// OnEmpty is the policy applied when the deck runs empty: none, reshuffle_discards, reshuffle_all or refill.
+	OnEmpty string `protobuf:"bytes,5,opt,name=on_empty,json=onEmpty,proto3" json:"on_empty,omitempty"`
+	// Decks is the number of decks of a shoe, 0 for a single deck.
+	Decks int32 `protobuf:"varint,6,opt,name=decks,proto3" json:"decks,omitempty"`
+	// Penetration places the cut card at this percent of the shoe, 0 for no cut card.
+	Penetration int32 `protobuf:"varint,7,opt,name=penetration,proto3" json:"penetration,omitempty"`
+	// AutoReshuffle reshuffles the shoe when a draw passes the cut card.
+	AutoReshuffle bool `protobuf:"varint,8,opt,name=auto_reshuffle,json=autoReshuffle,proto3" json:"auto_reshuffle,omitempty"`
+}
+
+func (x *CreateDeckRequest) Reset() {
+	*x = CreateDeckRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_deckv1_deck_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateDeckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDeckRequest) ProtoMessage() {}
+
+func (x *CreateDeckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deckv1_deck_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDeckRequest.ProtoReflect.Descriptor instead.
+func (*CreateDeckRequest) Descriptor() ([]byte, []int) {
+	return file_deckv1_deck_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CreateDeckRequest) GetShuffled() bool {
+	if x != nil && x.Shuffled != nil {
+		return *x.Shuffled
+	}
+	return false
+}
+
+func (x *CreateDeckRequest) GetCards() []string {
+	if x != nil {
+		return x.Cards
+	}
+	return nil
+}
+
+func (x *CreateDeckRequest) GetFaceDown() bool {
+	if x != nil {
+		return x.FaceDown
+	}
+	return false
+}
+
+func (x *CreateDeckRequest) GetFaceUp() []string {
+	if x != nil {
+		return x.FaceUp
+	}
+	return nil
+}
+
+func (x *CreateDeckRequest) GetOnEmpty() string {
+	if x != nil {
+		return x.OnEmpty
+	}
+	return ""
+}
+
+func (x *CreateDeckRequest) GetDecks() int32 {
+	if x != nil {
+		return x.Decks
+	}
+	return 0
+}
+
+func (x *CreateDeckRequest) GetPenetration() int32 {
+	if x != nil {
+		return x.Penetration
+	}
+	return 0
+}
+
+func (x *CreateDeckRequest) GetAutoReshuffle() bool {
+	if x != nil {
+		return x.AutoReshuffle
+	}
+	return false
+}
+
+type CreateDeckResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeckId    string `protobuf:"bytes,1,opt,name=deck_id,json=deckId,proto3" json:"deck_id,omitempty"`
+	Shuffled  bool   `protobuf:"varint,2,opt,name=shuffled,proto3" json:"shuffled,omitempty"`
+	Remaining int32  `protobuf:"varint,3,opt,name=remaining,proto3" json:"remaining,omitempty"`
+}
+
+func (x *CreateDeckResponse) Reset() {
+	*x = CreateDeckResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_deckv1_deck_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateDeckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDeckResponse) ProtoMessage() {}
+
+func (x *CreateDeckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deckv1_deck_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDeckResponse.ProtoReflect.Descriptor instead.
+func (*CreateDeckResponse) Descriptor() ([]byte, []int) {
+	return file_deckv1_deck_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateDeckResponse) GetDeckId() string {
+	if x != nil {
+		return x.DeckId
+	}
+	return ""
+}
+
+func (x *CreateDeckResponse) GetShuffled() bool {
+	if x != nil {
+		return x.Shuffled
+	}
+	return false
+}
+
+func (x *CreateDeckResponse) GetRemaining() int32 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+type OpenDeckRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeckId string `protobuf:"bytes,1,opt,name=deck_id,json=deckId,proto3" json:"deck_id,omitempty"`
+}
+
+func (x *OpenDeckRequest) Reset() {
+	*x = OpenDeckRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_deckv1_deck_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OpenDeckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OpenDeckRequest) ProtoMessage() {}
+
+func (x *OpenDeckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deckv1_deck_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OpenDeckRequest.ProtoReflect.Descriptor instead.
+func (*OpenDeckRequest) Descriptor() ([]byte, []int) {
+	return file_deckv1_deck_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *OpenDeckRequest) GetDeckId() string {
+	if x != nil {
+		return x.DeckId
+	}
+	return ""
+}
+
+type OpenDeckResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeckId      string       `protobuf:"bytes,1,opt,name=deck_id,json=deckId,proto3" json:"deck_id,omitempty"`
+	Shuffled    bool         `protobuf:"varint,2,opt,name=shuffled,proto3" json:"shuffled,omitempty"`
+	Remaining   int32        `protobuf:"varint,3,opt,name=remaining,proto3" json:"remaining,omitempty"`
+	Cards       []*Card      `protobuf:"bytes,4,rep,name=cards,proto3" json:"cards,omitempty"`
+	Version     int32        `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	OnEmpty     string       `protobuf:"bytes,6,opt,name=on_empty,json=onEmpty,proto3" json:"on_empty,omitempty"`
+	Penetration *Penetration `protobuf:"bytes,7,opt,name=penetration,proto3" json:"penetration,omitempty"`
+	FaceDown    bool         `protobuf:"varint,8,opt,name=face_down,json=faceDown,proto3" json:"face_down,omitempty"`
+	// Hidden is the number of face down cards whose faces the caller cannot see.
+	Hidden int32 `protobuf:"varint,9,opt,name=hidden,proto3" json:"hidden,omitempty"`
+}
+
+func (x *OpenDeckResponse) Reset() {
+	*x = OpenDeckResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_deckv1_deck_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OpenDeckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OpenDeckResponse) ProtoMessage() {}
+
+func (x *OpenDeckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deckv1_deck_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OpenDeckResponse.ProtoReflect.Descriptor instead.
+func (*OpenDeckResponse) Descriptor() ([]byte, []int) {
+	return file_deckv1_deck_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *OpenDeckResponse) GetDeckId() string {
+	if x != nil {
+		return x.DeckId
+	}
+	return ""
+}
+
+func (x *OpenDeckResponse) GetShuffled() bool {
+	if x != nil {
+		return x.Shuffled
+	}
+	return false
+}
+
+func (x *OpenDeckResponse) GetRemaining() int32 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+func (x *OpenDeckResponse) GetCards() []*Card {
+	if x != nil {
+		return x.Cards
+	}
+	return nil
+}
+
+func (x *OpenDeckResponse) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *OpenDeckResponse) GetOnEmpty() string {
+	if x != nil {
+		return x.OnEmpty
+	}
+	return ""
+}
+
+func (x *OpenDeckResponse) GetPenetration() *Penetration {
+	if x != nil {
+		return x.Penetration
+	}
+	return nil
+}
+
+func (x *OpenDeckResponse) GetFaceDown() bool {
+	if x != nil {
+		return x.FaceDown
+	}
+	return false
+}
+
+func (x *OpenDeckResponse) GetHidden() int32 {
+	if x != nil {
+		return x.Hidden
+	}
+	return 0
+}
+
+type DrawCardsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeckId string `protobuf:"bytes,1,opt,name=deck_id,json=deckId,proto3" json:"deck_id,omitempty"`
+	Count  int32  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+}
+
+func (x *DrawCardsRequest) Reset() {
+	*x = DrawCardsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_deckv1_deck_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DrawCardsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DrawCardsRequest) ProtoMessage() {}
+
+func (x *DrawCardsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deckv1_deck_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DrawCardsRequest.ProtoReflect.Descriptor instead.
+func (*DrawCardsRequest) Descriptor() ([]byte, []int) {
+	return file_deckv1_deck_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DrawCardsRequest) GetDeckId() string {
+	if x != nil {
+		return x.DeckId
+	}
+	return ""
+}
+
+func (x *DrawCardsRequest) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type DrawCardsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cards []*Card `protobuf:"bytes,1,rep,name=cards,proto3" json:"cards,omitempty"`
+	// Reshuffled is set when the deck ran empty and was restocked during the draw.
+	Reshuffled *Reshuffle `protobuf:"bytes,2,opt,name=reshuffled,proto3" json:"reshuffled,omitempty"`
+	// Penetration is set for a shoe with a cut card.
+	Penetration *Penetration `protobuf:"bytes,3,opt,name=penetration,proto3" json:"penetration,omitempty"`
+}
+
+func (x *DrawCardsResponse) Reset() {
+	*x = DrawCardsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_deckv1_deck_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DrawCardsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DrawCardsResponse) ProtoMessage() {}
+
+func (x *DrawCardsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deckv1_deck_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DrawCardsResponse.ProtoReflect.Descriptor instead.
+func (*DrawCardsResponse) Descriptor() ([]byte, []int) {
+	return file_deckv1_deck_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DrawCardsResponse) GetCards() []*Card {
+	if x != nil {
+		return x.Cards
+	}
+	return nil
+}
+
+func (x *DrawCardsResponse) GetReshuffled() *Reshuffle {
+	if x != nil {
+		return x.Reshuffled
+	}
+	return nil
+}
+
+func (x *DrawCardsResponse) GetPenetration() *Penetration {
+	if x != nil {
+		return x.Penetration
+	}
+	return nil
+}
+
+var File_deckv1_deck_proto protoreflect.FileDescriptor
+
+var file_deckv1_deck_proto_rawDesc = []byte{
+	0x0a, 0x11, 0x64, 0x65, 0x63, 0x6b, 0x76, 0x31, 0x2f, 0x64, 0x65, 0x63, 0x6b, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x07, 0x64, 0x65, 0x63, 0x6b, 0x2e, 0x76, 0x31, 0x22, 0x61, 0x0a, 0x04,
+	0x43, 0x61, 0x72, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x75,
+	0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x75, 0x69, 0x74, 0x12, 0x12,
+	0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x6f,
+	0x64, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x61, 0x63, 0x65, 0x5f, 0x64, 0x6f, 0x77, 0x6e, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x66, 0x61, 0x63, 0x65, 0x44, 0x6f, 0x77, 0x6e, 0x22,
+	0x4f, 0x0a, 0x09, 0x52, 0x65, 0x73, 0x68, 0x75, 0x66, 0x66, 0x6c, 0x65, 0x12, 0x16, 0x0a, 0x06,
+	0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x6f,
+	0x6c, 0x69, 0x63, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x66, 0x74, 0x65, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x05, 0x61, 0x66, 0x74, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x61,
+	0x72, 0x64, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x63, 0x61, 0x72, 0x64, 0x73,
+	0x22, 0xaa, 0x01, 0x0a, 0x0b, 0x50, 0x65, 0x6e, 0x65, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x18, 0x0a, 0x07, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x07, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x63, 0x75,
+	0x74, 0x5f, 0x63, 0x61, 0x72, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x63, 0x75,
+	0x74, 0x43, 0x61, 0x72, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x64, 0x65, 0x61, 0x6c, 0x74, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x64, 0x65, 0x61, 0x6c, 0x74, 0x12, 0x29, 0x0a, 0x10, 0x72,
+	0x65, 0x73, 0x68, 0x75, 0x66, 0x66, 0x6c, 0x65, 0x5f, 0x6e, 0x65, 0x65, 0x64, 0x65, 0x64, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x72, 0x65, 0x73, 0x68, 0x75, 0x66, 0x66, 0x6c, 0x65,
+	0x4e, 0x65, 0x65, 0x64, 0x65, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x61, 0x75, 0x74, 0x6f, 0x5f, 0x72,
+	0x65, 0x73, 0x68, 0x75, 0x66, 0x66, 0x6c, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d,
+	0x61, 0x75, 0x74, 0x6f, 0x52, 0x65, 0x73, 0x68, 0x75, 0x66, 0x66, 0x6c, 0x65, 0x22, 0x87, 0x02,
+	0x0a, 0x11, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x44, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x08, 0x73, 0x68, 0x75, 0x66, 0x66, 0x6c, 0x65, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x08, 0x73, 0x68, 0x75, 0x66, 0x66, 0x6c, 0x65,
+	0x64, 0x88, 0x01, 0x01, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x61, 0x72, 0x64, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x05, 0x63, 0x61, 0x72, 0x64, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x61,
+	0x63, 0x65, 0x5f, 0x64, 0x6f, 0x77, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x66,
+	0x61, 0x63, 0x65, 0x44, 0x6f, 0x77, 0x6e, 0x12, 0x17, 0x0a, 0x07, 0x66, 0x61, 0x63, 0x65, 0x5f,
+	0x75, 0x70, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x66, 0x61, 0x63, 0x65, 0x55, 0x70,
+	0x12, 0x19, 0x0a, 0x08, 0x6f, 0x6e, 0x5f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x6f, 0x6e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x64,
+	0x65, 0x63, 0x6b, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x64, 0x65, 0x63, 0x6b,
+	0x73, 0x12, 0x20, 0x0a, 0x0b, 0x70, 0x65, 0x6e, 0x65, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x70, 0x65, 0x6e, 0x65, 0x74, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x25, 0x0a, 0x0e, 0x61, 0x75, 0x74, 0x6f, 0x5f, 0x72, 0x65, 0x73, 0x68,
+	0x75, 0x66, 0x66, 0x6c, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x61, 0x75, 0x74,
+	0x6f, 0x52, 0x65, 0x73, 0x68, 0x75, 0x66, 0x66, 0x6c, 0x65, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x73,
+	0x68, 0x75, 0x66, 0x66, 0x6c, 0x65, 0x64, 0x22, 0x67, 0x0a, 0x12, 0x43, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x44, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x17, 0x0a,
+	0x07, 0x64, 0x65, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x64, 0x65, 0x63, 0x6b, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x68, 0x75, 0x66, 0x66, 0x6c,
+	0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x73, 0x68, 0x75, 0x66, 0x66, 0x6c,
+	0x65, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67,
+	0x22, 0x2a, 0x0a, 0x0f, 0x4f, 0x70, 0x65, 0x6e, 0x44, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x65, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x65, 0x63, 0x6b, 0x49, 0x64, 0x22, 0xac, 0x02, 0x0a,
+	0x10, 0x4f, 0x70, 0x65, 0x6e, 0x44, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x65, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x64, 0x65, 0x63, 0x6b, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x68,
+	0x75, 0x66, 0x66, 0x6c, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x73, 0x68,
+	0x75, 0x66, 0x66, 0x6c, 0x65, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e,
+	0x69, 0x6e, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x72, 0x65, 0x6d, 0x61, 0x69,
+	0x6e, 0x69, 0x6e, 0x67, 0x12, 0x23, 0x0a, 0x05, 0x63, 0x61, 0x72, 0x64, 0x73, 0x18, 0x04, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x64, 0x65, 0x63, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61,
+	0x72, 0x64, 0x52, 0x05, 0x63, 0x61, 0x72, 0x64, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x19, 0x0a, 0x08, 0x6f, 0x6e, 0x5f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6f, 0x6e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x36,
+	0x0a, 0x0b, 0x70, 0x65, 0x6e, 0x65, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x64, 0x65, 0x63, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x65,
+	0x6e, 0x65, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x70, 0x65, 0x6e, 0x65, 0x74,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x61, 0x63, 0x65, 0x5f, 0x64,
+	0x6f, 0x77, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x66, 0x61, 0x63, 0x65, 0x44,
+	0x6f, 0x77, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x69, 0x64, 0x64, 0x65, 0x6e, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x06, 0x68, 0x69, 0x64, 0x64, 0x65, 0x6e, 0x22, 0x41, 0x0a, 0x10, 0x44,
+	0x72, 0x61, 0x77, 0x43, 0x61, 0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x17, 0x0a, 0x07, 0x64, 0x65, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x64, 0x65, 0x63, 0x6b, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0xa4,
+	0x01, 0x0a, 0x11, 0x44, 0x72, 0x61, 0x77, 0x43, 0x61, 0x72, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x23, 0x0a, 0x05, 0x63, 0x61, 0x72, 0x64, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x64, 0x65, 0x63, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61,
+	0x72, 0x64, 0x52, 0x05, 0x63, 0x61, 0x72, 0x64, 0x73, 0x12, 0x32, 0x0a, 0x0a, 0x72, 0x65, 0x73,
+	0x68, 0x75, 0x66, 0x66, 0x6c, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e,
+	0x64, 0x65, 0x63, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x68, 0x75, 0x66, 0x66, 0x6c,
+	0x65, 0x52, 0x0a, 0x72, 0x65, 0x73, 0x68, 0x75, 0x66, 0x66, 0x6c, 0x65, 0x64, 0x12, 0x36, 0x0a,
+	0x0b, 0x70, 0x65, 0x6e, 0x65, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x14, 0x2e, 0x64, 0x65, 0x63, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x65, 0x6e,
+	0x65, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x70, 0x65, 0x6e, 0x65, 0x74, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x32, 0xd9, 0x01, 0x0a, 0x0b, 0x44, 0x65, 0x63, 0x6b, 0x53, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x45, 0x0a, 0x0a, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x44,
+	0x65, 0x63, 0x6b, 0x12, 0x1a, 0x2e, 0x64, 0x65, 0x63, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x44, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x1b, 0x2e, 0x64, 0x65, 0x63, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x44, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3f, 0x0a, 0x08,
+	0x4f, 0x70, 0x65, 0x6e, 0x44, 0x65, 0x63, 0x6b, 0x12, 0x18, 0x2e, 0x64, 0x65, 0x63, 0x6b, 0x2e,
+	0x76, 0x31, 0x2e, 0x4f, 0x70, 0x65, 0x6e, 0x44, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x19, 0x2e, 0x64, 0x65, 0x63, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x70, 0x65,
+	0x6e, 0x44, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x42, 0x0a,
+	0x09, 0x44, 0x72, 0x61, 0x77, 0x43, 0x61, 0x72, 0x64, 0x73, 0x12, 0x19, 0x2e, 0x64, 0x65, 0x63,
+	0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x72, 0x61, 0x77, 0x43, 0x61, 0x72, 0x64, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x64, 0x65, 0x63, 0x6b, 0x2e, 0x76, 0x31, 0x2e,
+	0x44, 0x72, 0x61, 0x77, 0x43, 0x61, 0x72, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x42, 0x23, 0x5a, 0x21, 0x74, 0x6f, 0x67, 0x67, 0x6c, 0x2d, 0x63, 0x61, 0x72, 0x64, 0x2d,
+	0x67, 0x61, 0x6d, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x64, 0x65, 0x63, 0x6b, 0x76, 0x31, 0x3b,
+	0x64, 0x65, 0x63, 0x6b, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_deckv1_deck_proto_rawDescOnce sync.Once
+	file_deckv1_deck_proto_rawDescData = file_deckv1_deck_proto_rawDesc
+)
+
+func file_deckv1_deck_proto_rawDescGZIP() []byte {
+	file_deckv1_deck_proto_rawDescOnce.Do(func() {
+		file_deckv1_deck_proto_rawDescData = protoimpl.X.CompressGZIP(file_deckv1_deck_proto_rawDescData)
+	})
+	return file_deckv1_deck_proto_rawDescData
+}
+
+var file_deckv1_deck_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_deckv1_deck_proto_goTypes = []any{
+	(*Card)(nil),               // 0: deck.v1.Card
+	(*Reshuffle)(nil),          // 1: deck.v1.Reshuffle
+	(*Penetration)(nil),        // 2: deck.v1.Penetration
+	(*CreateDeckRequest)(nil),  // 3: deck.v1.CreateDeckRequest
+	(*CreateDeckResponse)(nil), // 4: deck.v1.CreateDeckResponse
+	(*OpenDeckRequest)(nil),    // 5: deck.v1.OpenDeckRequest
+	(*OpenDeckResponse)(nil),   // 6: deck.v1.OpenDeckResponse
+	(*DrawCardsRequest)(nil),   // 7: deck.v1.DrawCardsRequest
+	(*DrawCardsResponse)(nil),  // 8: deck.v1.DrawCardsResponse
+}
+var file_deckv1_deck_proto_depIdxs = []int32{
+	0, // 0: deck.v1.OpenDeckResponse.cards:type_name -> deck.v1.Card
+	2, // 1: deck.v1.OpenDeckResponse.penetration:type_name -> deck.v1.Penetration
+	0, // 2: deck.v1.DrawCardsResponse.cards:type_name -> deck.v1.Card
+	1, // 3: deck.v1.DrawCardsResponse.reshuffled:type_name -> deck.v1.Reshuffle
+	2, // 4: deck.v1.DrawCardsResponse.penetration:type_name -> deck.v1.Penetration
+	3, // 5: deck.v1.DeckService.CreateDeck:input_type -> deck.v1.CreateDeckRequest
+	5, // 6: deck.v1.DeckService.OpenDeck:input_type -> deck.v1.OpenDeckRequest
+	7, // 7: deck.v1.DeckService.DrawCards:input_type -> deck.v1.DrawCardsRequest
+	4, // 8: deck.v1.DeckService.CreateDeck:output_type -> deck.v1.CreateDeckResponse
+	6, // 9: deck.v1.DeckService.OpenDeck:output_type -> deck.v1.OpenDeckResponse
+	8, // 10: deck.v1.DeckService.DrawCards:output_type -> deck.v1.DrawCardsResponse
+	8, // [8:11] is the sub-list for method output_type
+	5, // [5:8] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_deckv1_deck_proto_init() }
+func file_deckv1_deck_proto_init() {
+	if File_deckv1_deck_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_deckv1_deck_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*Card); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_deckv1_deck_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*Reshuffle); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_deckv1_deck_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*Penetration); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_deckv1_deck_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*CreateDeckRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_deckv1_deck_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*CreateDeckResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_deckv1_deck_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*OpenDeckRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_deckv1_deck_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*OpenDeckResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_deckv1_deck_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*DrawCardsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_deckv1_deck_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*DrawCardsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_deckv1_deck_proto_msgTypes[3].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_deckv1_deck_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_deckv1_deck_proto_goTypes,
+		DependencyIndexes: file_deckv1_deck_proto_depIdxs,
+		MessageInfos:      file_deckv1_deck_proto_msgTypes,
+	}.Build()
+	File_deckv1_deck_proto = out.File
+	file_deckv1_deck_proto_rawDesc = nil
+	file_deckv1_deck_proto_goTypes = nil
+	file_deckv1_deck_proto_depIdxs = nil
+}
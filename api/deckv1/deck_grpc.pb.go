@@ -0,0 +1,207 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: deckv1/deck.proto
+
+// Package deck.v1 is the gRPC transport of the deck api. It mirrors the
+// create, open and draw operations of the HTTP api.
+
+package deckv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	DeckService_CreateDeck_FullMethodName = "/deck.v1.DeckService/CreateDeck"
+	DeckService_OpenDeck_FullMethodName   = "/deck.v1.DeckService/OpenDeck"
+	DeckService_DrawCards_FullMethodName  = "/deck.v1.DeckService/DrawCards"
+)
+
+// DeckServiceClient is the client API for DeckService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// DeckService manages decks of playing cards.
+//
+// Credentials are passed as metadata: an api key in x-api-key or an identity
+// token in authorization ("Bearer <token>"). Shared decks accept a capability
+// token in x-capability-token.
+type DeckServiceClient interface {
+	// CreateDeck creates a new deck.
+	CreateDeck(ctx context.Context, in *CreateDeckRequest, opts ...grpc.CallOption) (*CreateDeckResponse, error)
+	// OpenDeck returns a deck and its remaining cards.
+	OpenDeck(ctx context.Context, in *OpenDeckRequest, opts ...grpc.CallOption) (*OpenDeckResponse, error)
+	// DrawCards draws cards from the top of a deck.
+	DrawCards(ctx context.Context, in *DrawCardsRequest, opts ...grpc.CallOption) (*DrawCardsResponse, error)
+}
+
+type deckServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDeckServiceClient(cc grpc.ClientConnInterface) DeckServiceClient {
+	return &deckServiceClient{cc}
+}
+
+func (c *deckServiceClient) CreateDeck(ctx context.Context, in *CreateDeckRequest, opts ...grpc.CallOption) (*CreateDeckResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateDeckResponse)
+	err := c.cc.Invoke(ctx, DeckService_CreateDeck_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deckServiceClient) OpenDeck(ctx context.Context, in *OpenDeckRequest, opts ...grpc.CallOption) (*OpenDeckResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OpenDeckResponse)
+	err := c.cc.Invoke(ctx, DeckService_OpenDeck_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deckServiceClient) DrawCards(ctx context.Context, in *DrawCardsRequest, opts ...grpc.CallOption) (*DrawCardsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DrawCardsResponse)
+	err := c.cc.Invoke(ctx, DeckService_DrawCards_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeckServiceServer is the server API for DeckService service.
+// All implementations must embed UnimplementedDeckServiceServer
+// for forward compatibility
+//
+// DeckService manages decks of playing cards.
+//
+// Credentials are passed as metadata: an api key in x-api-key or an identity
+// token in authorization ("Bearer <token>"). Shared decks accept a capability
+// token in x-capability-token.
+type DeckServiceServer interface {
+	// CreateDeck creates a new deck.
+	CreateDeck(context.Context, *CreateDeckRequest) (*CreateDeckResponse, error)
+	// OpenDeck returns a deck and its remaining cards.
+	OpenDeck(context.Context, *OpenDeckRequest) (*OpenDeckResponse, error)
+	// DrawCards draws cards from the top of a deck.
+	DrawCards(context.Context, *DrawCardsRequest) (*DrawCardsResponse, error)
+	mustEmbedUnimplementedDeckServiceServer()
+}
+
+// UnimplementedDeckServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedDeckServiceServer struct {
+}
+
+func (UnimplementedDeckServiceServer) CreateDeck(context.Context, *CreateDeckRequest) (*CreateDeckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateDeck not implemented")
+}
+func (UnimplementedDeckServiceServer) OpenDeck(context.Context, *OpenDeckRequest) (*OpenDeckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method OpenDeck not implemented")
+}
+func (UnimplementedDeckServiceServer) DrawCards(context.Context, *DrawCardsRequest) (*DrawCardsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DrawCards not implemented")
+}
+func (UnimplementedDeckServiceServer) mustEmbedUnimplementedDeckServiceServer() {}
+
+// UnsafeDeckServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DeckServiceServer will
+// result in compilation errors.
+type UnsafeDeckServiceServer interface {
+	mustEmbedUnimplementedDeckServiceServer()
+}
+
+func RegisterDeckServiceServer(s grpc.ServiceRegistrar, srv DeckServiceServer) {
+	s.RegisterService(&DeckService_ServiceDesc, srv)
+}
+
+func _DeckService_CreateDeck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateDeckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeckServiceServer).CreateDeck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeckService_CreateDeck_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeckServiceServer).CreateDeck(ctx, req.(*CreateDeckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeckService_OpenDeck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OpenDeckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeckServiceServer).OpenDeck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeckService_OpenDeck_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeckServiceServer).OpenDeck(ctx, req.(*OpenDeckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeckService_DrawCards_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DrawCardsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeckServiceServer).DrawCards(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeckService_DrawCards_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeckServiceServer).DrawCards(ctx, req.(*DrawCardsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DeckService_ServiceDesc is the grpc.ServiceDesc for DeckService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DeckService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "deck.v1.DeckService",
+	HandlerType: (*DeckServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateDeck",
+			Handler:    _DeckService_CreateDeck_Handler,
+		},
+		{
+			MethodName: "OpenDeck",
+			Handler:    _DeckService_OpenDeck_Handler,
+		},
+		{
+			MethodName: "DrawCards",
+			Handler:    _DeckService_DrawCards_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "deckv1/deck.proto",
+}
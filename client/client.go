@@ -0,0 +1,335 @@
+// Package client is a typed Go client of the deck api.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"toggl-card-game/api"
+
+	"github.com/google/uuid"
+)
+
+// Client calls version 1 of the deck api over HTTP. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	header     http.Header
+	retries    int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	// idempotencyKeys makes requests that are not idempotent retryable by sending them with an Idempotency-Key.
+	idempotencyKeys bool
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the underlying http client.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithAPIKey authenticates requests with an api key.
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.header.Set("X-API-Key", key) }
+}
+
+// WithBearerToken authenticates requests with a signed identity token.
+func WithBearerToken(token string) Option {
+	return func(c *Client) { c.header.Set("Authorization", "Bearer "+token) }
+}
+
+// WithCapabilityToken authorizes requests with a capability token of a shared deck.
+func WithCapabilityToken(token string) Option {
+	return func(c *Client) { c.header.Set("X-Capability-Token", token) }
+}
+
+// WithRetries sets how many times a failed request is retried and the initial backoff,
+// which doubles with every attempt.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+// WithIdempotencyKeys sends requests that are not idempotent, e.g. draws, with a generated Idempotency-Key header,
+// so that they are retried like idempotent requests. The server replays the response of the first attempt
+// instead of applying a retry again, it must have idempotency enabled.
+func WithIdempotencyKeys() Option {
+	return func(c *Client) { c.idempotencyKeys = true }
+}
+
+// New creates a client of the api served at baseURL, e.g. http://localhost:8080.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base url [%s], expected http or https scheme", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		header:     make(http.Header),
+		retries:    2,
+		backoff:    100 * time.Millisecond,
+		maxBackoff: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// CreateOptions holds the optional parameters of a new deck.
+type CreateOptions struct {
+	// Cards are the card codes of a partial deck, a full deck is created when empty.
+	Cards []string
+	// Shuffled shuffles the deck, the server default is used when nil.
+	Shuffled *bool
+	// FaceDown lays the deck face down, FaceUp are the codes of its cards that are turned face up nevertheless.
+	FaceDown bool
+	FaceUp   []string
+	// OnEmpty is the policy applied when a draw runs out of cards, e.g. reshuffle_discards, none when empty.
+	OnEmpty string
+	// Decks is the number of decks of a shoe, 1 when 0. Penetration places a cut card that comes out after
+	// the percentage of the shoe is dealt, AutoReshuffle reshuffles the shoe before the next round then.
+	Decks         int
+	Penetration   int
+	AutoReshuffle bool
+}
+
+// CreateDeck creates a new deck.
+func (c *Client) CreateDeck(ctx context.Context, opts CreateOptions) (*api.CreateResponse, error) {
+	q := url.Values{}
+	if len(opts.Cards) > 0 {
+		q.Set("cards", strings.Join(opts.Cards, ","))
+	}
+	if opts.Shuffled != nil {
+		q.Set("shuffled", strconv.FormatBool(*opts.Shuffled))
+	}
+	if opts.FaceDown {
+		q.Set("face_down", "true")
+	}
+	if len(opts.FaceUp) > 0 {
+		q.Set("face_up", strings.Join(opts.FaceUp, ","))
+	}
+	if opts.OnEmpty != "" {
+		q.Set("on_empty", opts.OnEmpty)
+	}
+	if opts.Decks > 0 {
+		q.Set("decks", strconv.Itoa(opts.Decks))
+	}
+	if opts.Penetration > 0 {
+		q.Set("penetration", strconv.Itoa(opts.Penetration))
+	}
+	if opts.AutoReshuffle {
+		q.Set("auto_reshuffle", "true")
+	}
+
+	res := new(api.CreateResponse)
+	return res, c.do(ctx, http.MethodPost, "/api/v1/deck", q, nil, false, res)
+}
+
+// CloneDeck copies the current state of the deck into a new deck, reshuffle shuffles the cards of the copy.
+func (c *Client) CloneDeck(ctx context.Context, deckId string, reshuffle bool) (*api.CreateResponse, error) {
+	q := url.Values{}
+	if reshuffle {
+		q.Set("reshuffle", "true")
+	}
+
+	res := new(api.CreateResponse)
+	return res, c.do(ctx, http.MethodPost, "/api/v1/deck/"+url.PathEscape(deckId)+"/clone", q, nil, false, res)
+}
+
+// ExportDeck returns the portable representation of the deck.
+func (c *Client) ExportDeck(ctx context.Context, deckId string) (*api.DeckExport, error) {
+	res := new(api.DeckExport)
+	return res, c.do(ctx, http.MethodGet, "/api/v1/deck/"+url.PathEscape(deckId)+"/export", nil, nil, true, res)
+}
+
+// ImportDeck creates the deck of a portable representation, e.g. exported from another server.
+func (c *Client) ImportDeck(ctx context.Context, export api.DeckExport) (*api.CreateResponse, error) {
+	res := new(api.CreateResponse)
+	return res, c.do(ctx, http.MethodPost, "/api/v1/deck/import", nil, export, false, res)
+}
+
+// OpenDeck returns the deck with its remaining cards.
+func (c *Client) OpenDeck(ctx context.Context, deckId string) (*api.OpenResponse, error) {
+	res := new(api.OpenResponse)
+	return res, c.do(ctx, http.MethodGet, "/api/v1/deck/"+url.PathEscape(deckId), nil, nil, true, res)
+}
+
+// OpenDeckVersion returns the deck as it was after the given number of operations.
+func (c *Client) OpenDeckVersion(ctx context.Context, deckId string, version int) (*api.OpenResponse, error) {
+	res := new(api.OpenResponse)
+	q := url.Values{"version": {strconv.Itoa(version)}}
+	return res, c.do(ctx, http.MethodGet, "/api/v1/deck/"+url.PathEscape(deckId), q, nil, true, res)
+}
+
+// TakeSnapshot stores the current state of the deck under the name, it replaces a snapshot with the same name.
+func (c *Client) TakeSnapshot(ctx context.Context, deckId, name string) (*api.Snapshot, error) {
+	res := new(api.Snapshot)
+	return res, c.do(ctx, http.MethodPut, snapshotPath(deckId, name), nil, nil, true, res)
+}
+
+// ListSnapshots returns the snapshots of the deck.
+func (c *Client) ListSnapshots(ctx context.Context, deckId string) (*api.SnapshotList, error) {
+	res := new(api.SnapshotList)
+	return res, c.do(ctx, http.MethodGet, "/api/v1/deck/"+url.PathEscape(deckId)+"/snapshots", nil, nil, true, res)
+}
+
+// RestoreSnapshot restores the state of the deck from the named snapshot.
+func (c *Client) RestoreSnapshot(ctx context.Context, deckId, name string) (*api.RestoreResponse, error) {
+	res := new(api.RestoreResponse)
+	return res, c.do(ctx, http.MethodPost, snapshotPath(deckId, name)+"/restore", nil, nil, false, res)
+}
+
+func snapshotPath(deckId, name string) string {
+	return "/api/v1/deck/" + url.PathEscape(deckId) + "/snapshots/" + url.PathEscape(name)
+}
+
+// DrawCards draws count cards from the deck.
+func (c *Client) DrawCards(ctx context.Context, deckId string, count int) (*api.DrawResponse, error) {
+	res := new(api.DrawResponse)
+	q := url.Values{"count": {strconv.Itoa(count)}}
+	return res, c.do(ctx, http.MethodPost, "/api/v1/deck/"+url.PathEscape(deckId)+"/draw", q, nil, false, res)
+}
+
+// ShareDeck mints a capability token of the deck.
+func (c *Client) ShareDeck(ctx context.Context, deckId string, req api.ShareRequest) (*api.ShareResponse, error) {
+	res := new(api.ShareResponse)
+	return res, c.do(ctx, http.MethodPost, "/api/v1/deck/"+url.PathEscape(deckId)+"/share", nil, req, false, res)
+}
+
+// Batch applies the steps to the deck atomically, either all of them are applied or none.
+func (c *Client) Batch(ctx context.Context, deckId string, steps ...api.BatchStep) (*api.BatchResponse, error) {
+	res := new(api.BatchResponse)
+	return res, c.do(ctx, http.MethodPost, "/api/v1/deck/"+url.PathEscape(deckId)+"/batch", nil, api.BatchRequest{Steps: steps}, false, res)
+}
+
+// do sends a request and decodes the JSON response into out.
+// Responses with an error status are decoded into api.Problem.
+// Rejected requests (429) are always retried, while network errors and 502, 503 and 504 responses
+// are retried only for idempotent requests, since the server might have processed them.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body any, idempotent bool, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	u := c.baseURL.JoinPath(path)
+	u.RawQuery = query.Encode()
+
+	// all attempts send the same key, the server applies the request once
+	var key string
+	if !idempotent && c.idempotencyKeys {
+		key = uuid.NewString()
+		idempotent = true
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		for k, v := range c.header {
+			req.Header[k] = v
+		}
+		req.Header.Set("Accept", "application/json, application/problem+json")
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			if ctx.Err() != nil || !idempotent || attempt >= c.retries {
+				return err
+			}
+			if err = c.wait(ctx, attempt, nil); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if resp.StatusCode < 300 {
+			defer resp.Body.Close()
+			return json.NewDecoder(resp.Body).Decode(out)
+		}
+
+		apiErr := decodeError(resp)
+		if attempt >= c.retries || !retryable(resp.StatusCode, idempotent) {
+			return apiErr
+		}
+		if err = c.wait(ctx, attempt, resp); err != nil {
+			return errors.Join(apiErr, err)
+		}
+	}
+}
+
+func retryable(code int, idempotent bool) bool {
+	switch code {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return idempotent
+	}
+	return false
+}
+
+// wait sleeps before the next attempt, honoring the Retry-After header of the response.
+func (c *Client) wait(ctx context.Context, attempt int, resp *http.Response) error {
+	delay := c.backoff << attempt
+	delay += time.Duration(rand.Int63n(int64(delay)/2 + 1))
+	if resp != nil {
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			delay = time.Duration(secs) * time.Second
+		}
+	}
+	if delay > c.maxBackoff {
+		delay = c.maxBackoff
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func decodeError(resp *http.Response) api.Problem {
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	problem := api.Problem{}
+	if err := json.Unmarshal(data, &problem); err != nil || problem.Status == 0 {
+		// not a problem details response, e.g. from a proxy
+		problem = api.Problem{Type: "about:blank", Title: http.StatusText(resp.StatusCode), Status: resp.StatusCode, Detail: strings.TrimSpace(string(data))}
+	}
+	if problem.RequestId == "" {
+		problem.RequestId = resp.Header.Get("X-Request-ID")
+	}
+	return problem
+}
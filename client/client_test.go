@@ -0,0 +1,252 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+	"toggl-card-game/api"
+	"toggl-card-game/client"
+	"toggl-card-game/internal/auth"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/repo"
+	"toggl-card-game/internal/server"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	signer := auth.NewSigner([]byte("secret"))
+	srv := &server.Server{
+		Auth:        auth.NewAuthenticator(map[string]string{"alice-key": "alice", "bob-key": "bob"}, nil),
+		Signer:      signer,
+		DeckService: deck.NewService(repo.NewInMemoryRepo()).WithGrantSigner(signer),
+	}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	server := newServer(t)
+
+	alice, err := client.New(server.URL, client.WithAPIKey("alice-key"))
+	assert.Nil(t, err)
+
+	shuffled := false
+	created, err := alice.CreateDeck(ctx, client.CreateOptions{Cards: []string{"AS", "KH", "10D"}, Shuffled: &shuffled})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, created.Remaining)
+	assert.False(t, created.Shuffled)
+
+	drawn, err := alice.DrawCards(ctx, created.DeckId, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, []api.Card{
+		{Value: "ACE", Suit: "SPADES", Code: "AS"},
+		{Value: "KING", Suit: "HEARTS", Code: "KH"},
+	}, drawn.Cards)
+
+	opened, err := alice.OpenDeck(ctx, created.DeckId)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, opened.Remaining)
+	assert.Equal(t, "10D", opened.Cards[0].Code)
+
+	shared, err := alice.ShareDeck(ctx, created.DeckId, api.ShareRequest{Scope: "read"})
+	assert.Nil(t, err)
+
+	spectator, err := client.New(server.URL, client.WithCapabilityToken(shared.Token))
+	assert.Nil(t, err)
+	opened, err = spectator.OpenDeck(ctx, created.DeckId)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, opened.Remaining)
+
+	cloned, err := alice.CloneDeck(ctx, created.DeckId, false)
+	assert.Nil(t, err)
+	assert.NotEqual(t, created.DeckId, cloned.DeckId)
+	assert.Equal(t, 1, cloned.Remaining)
+
+	snapshot, err := alice.TakeSnapshot(ctx, created.DeckId, "before-batch")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, snapshot.Version)
+
+	batch, err := alice.Batch(ctx, created.DeckId, api.BatchStep{Op: "discard", Cards: []string{"KH"}}, api.BatchStep{Op: "draw", Count: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, batch.Remaining)
+	assert.Equal(t, []api.StepResult{
+		{Op: "discard", Cards: []api.Card{{Value: "KING", Suit: "HEARTS", Code: "KH"}}, Remaining: 1},
+		{Op: "draw", Cards: []api.Card{{Value: "10", Suit: "DIAMONDS", Code: "10D"}}, Remaining: 0},
+	}, batch.Results)
+
+	snapshots, err := alice.ListSnapshots(ctx, created.DeckId)
+	assert.Nil(t, err)
+	assert.Equal(t, []api.Snapshot{*snapshot}, snapshots.Snapshots)
+
+	restored, err := alice.RestoreSnapshot(ctx, created.DeckId, "before-batch")
+	assert.Nil(t, err)
+	assert.Equal(t, api.RestoreResponse{DeckId: created.DeckId, Snapshot: "before-batch", Version: 3, Remaining: 1}, *restored)
+
+	opened, err = alice.OpenDeckVersion(ctx, created.DeckId, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, opened.Version)
+	assert.Equal(t, 0, opened.Remaining)
+}
+
+func TestClientErrors(t *testing.T) {
+	ctx := context.Background()
+	server := newServer(t)
+
+	alice, _ := client.New(server.URL, client.WithAPIKey("alice-key"))
+	bob, _ := client.New(server.URL, client.WithAPIKey("bob-key"))
+	anonymous, _ := client.New(server.URL)
+
+	created, err := alice.CreateDeck(ctx, client.CreateOptions{})
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name     string
+		call     func() error
+		wantCode int
+	}{
+		{
+			name:     "unauthorized test",
+			call:     func() error { _, err := anonymous.CreateDeck(ctx, client.CreateOptions{}); return err },
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "forbidden test",
+			call:     func() error { _, err := bob.OpenDeck(ctx, created.DeckId); return err },
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "unknown deck test",
+			call:     func() error { _, err := alice.DrawCards(ctx, uuid.NewString(), 1); return err },
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "unknown empty policy test",
+			call: func() error {
+				_, err := alice.CreateDeck(ctx, client.CreateOptions{OnEmpty: "sometimes"})
+				return err
+			},
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+
+			var problem api.Problem
+			if !errors.As(err, &problem) {
+				t.Fatalf("expected api.Problem, got %v", err)
+			}
+			assert.Equal(t, tt.wantCode, problem.Status)
+			assert.NotEmpty(t, problem.Type)
+			assert.NotEmpty(t, problem.RequestId)
+		})
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	ctx := context.Background()
+	realURL, _ := url.Parse(newServer(t).URL)
+	proxy := httputil.NewSingleHostReverseProxy(realURL)
+
+	tests := []struct {
+		name      string
+		status    int
+		failures  int32
+		opts      []client.Option
+		call      func(c *client.Client) error
+		wantCalls int32
+		wantErr   bool
+	}{
+		{
+			name:      "retry rejected create test",
+			status:    http.StatusTooManyRequests,
+			failures:  2,
+			call:      func(c *client.Client) error { _, err := c.CreateDeck(ctx, client.CreateOptions{}); return err },
+			wantCalls: 3,
+		},
+		{
+			name:      "retry unavailable open test",
+			status:    http.StatusServiceUnavailable,
+			failures:  1,
+			call:      func(c *client.Client) error { _, err := c.OpenDeck(ctx, uuid.NewString()); return err },
+			wantCalls: 2,
+			wantErr:   true, // the deck does not exist on the real server
+		},
+		{
+			name:      "do not retry unavailable draw test",
+			status:    http.StatusServiceUnavailable,
+			failures:  1,
+			call:      func(c *client.Client) error { _, err := c.DrawCards(ctx, uuid.NewString(), 1); return err },
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "retry unavailable draw with idempotency key test",
+			status:    http.StatusServiceUnavailable,
+			failures:  1,
+			opts:      []client.Option{client.WithIdempotencyKeys()},
+			call:      func(c *client.Client) error { _, err := c.DrawCards(ctx, uuid.NewString(), 1); return err },
+			wantCalls: 2,
+			wantErr:   true, // the deck does not exist on the real server
+		},
+		{
+			name:      "give up after retries test",
+			status:    http.StatusTooManyRequests,
+			failures:  10,
+			call:      func(c *client.Client) error { _, err := c.CreateDeck(ctx, client.CreateOptions{}); return err },
+			wantCalls: 3,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if calls.Add(1) <= tt.failures {
+					w.Header().Set("Retry-After", "0")
+					http.Error(w, http.StatusText(tt.status), tt.status)
+					return
+				}
+				r.Header.Set("X-API-Key", "alice-key")
+				proxy.ServeHTTP(w, r)
+			}))
+			defer flaky.Close()
+
+			c, _ := client.New(flaky.URL, append(tt.opts, client.WithRetries(2, time.Millisecond))...)
+			err := tt.call(c)
+
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			assert.Equal(t, tt.wantCalls, calls.Load())
+		})
+	}
+}
+
+func TestClientContextCancel(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer slow.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	c, _ := client.New(slow.URL, client.WithRetries(5, time.Millisecond))
+	start := time.Now()
+	_, err := c.CreateDeck(ctx, client.CreateOptions{})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
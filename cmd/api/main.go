@@ -1,17 +1,51 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"toggl-card-game/internal/config"
 	"toggl-card-game/internal/server"
+
+	_ "github.com/joho/godotenv/autoload"
 )
 
 func main() {
-	srv := server.New()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration: %s\n", err)
+		os.Exit(2)
+	}
+
+	slog.SetDefault(cfg.Logger(os.Stderr))
+	slog.Info("Configuration loaded", "config", cfg)
 
-	slog.Info("Server is starting", "port", srv.Addr)
-	err := srv.ListenAndServe()
+	srv, err := server.New(cfg)
 	if err != nil {
-		panic(fmt.Sprintf("cannot start server: %s", err))
+		fail(stop, "Cannot configure server", err)
 	}
+
+	err = srv.Run(ctx)
+	if err != nil {
+		fail(stop, "Cannot run server", err)
+	}
+	slog.Info("Server stopped")
+}
+
+// fail logs the error and exits with status 1, os.Exit does not run the deferred stop of the signal context.
+func fail(stop context.CancelFunc, msg string, err error) {
+	slog.Error(msg, "error", err)
+	stop()
+	os.Exit(1)
 }
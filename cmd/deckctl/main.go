@@ -0,0 +1,312 @@
+// Command deckctl talks to a running deck api server.
+//
+// Usage:
+//
+//	deckctl [global flags] <command> [flags] [deck id]
+//
+// Commands are create, open, draw, share, watch, export and import.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+	"toggl-card-game/api"
+	"toggl-card-game/client"
+)
+
+const usage = `Usage: deckctl [global flags] <command> [flags] [deck id]
+
+Commands:
+  create              create a new deck
+  open <deck id>      show a deck and its remaining cards
+  draw <deck id>      draw cards from a deck
+  share <deck id>     mint a capability token for a deck
+  watch <deck id>     print a deck whenever it changes
+  export <deck id>    write the portable representation of a deck as JSON
+  import <file>       create a deck of an exported representation, - reads it from stdin
+
+Global flags:
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		var problem api.Problem
+		if errors.As(err, &problem) {
+			fmt.Fprintf(os.Stderr, "deckctl: %s (status %d, type %s, request id %s)\n", problemMessage(problem), problem.Status, problem.Type, problem.RequestId)
+		} else {
+			fmt.Fprintf(os.Stderr, "deckctl: %s\n", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, in io.Reader, out io.Writer) error {
+	global := flag.NewFlagSet("deckctl", flag.ContinueOnError)
+	server := global.String("server", envOr("DECKCTL_SERVER", "http://localhost:8080"), "api server url (DECKCTL_SERVER)")
+	apiKey := global.String("api-key", os.Getenv("DECKCTL_API_KEY"), "api key (DECKCTL_API_KEY)")
+	token := global.String("token", os.Getenv("DECKCTL_TOKEN"), "capability token of a shared deck (DECKCTL_TOKEN)")
+	output := global.String("o", "pretty", "output format, one of: table, json, pretty")
+	global.Usage = func() {
+		fmt.Fprint(global.Output(), usage)
+		global.PrintDefaults()
+	}
+	if err := global.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := newPrinter(*output, out)
+	if err != nil {
+		return err
+	}
+
+	opts := []client.Option{}
+	if *apiKey != "" {
+		opts = append(opts, client.WithAPIKey(*apiKey))
+	}
+	if *token != "" {
+		opts = append(opts, client.WithCapabilityToken(*token))
+	}
+	c, err := client.New(*server, opts...)
+	if err != nil {
+		return err
+	}
+
+	if global.NArg() == 0 {
+		global.Usage()
+		return errors.New("missing command")
+	}
+
+	cmd, cmdArgs := global.Arg(0), global.Args()[1:]
+	switch cmd {
+	case "create":
+		return create(ctx, c, p, cmdArgs)
+	case "open":
+		return open(ctx, c, p, cmdArgs)
+	case "draw":
+		return draw(ctx, c, p, cmdArgs)
+	case "share":
+		return share(ctx, c, p, cmdArgs)
+	case "watch":
+		return watch(ctx, c, p, cmdArgs)
+	case "export":
+		return export(ctx, c, out, cmdArgs)
+	case "import":
+		return importDeck(ctx, c, p, in, cmdArgs)
+	default:
+		global.Usage()
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+func create(ctx context.Context, c *client.Client, p printer, args []string) error {
+	fs := flag.NewFlagSet("create", flag.ContinueOnError)
+	cards := fs.String("cards", "", "comma separated card codes, e.g. AS,10H,KC (default full deck)")
+	shuffled := fs.Bool("shuffled", false, "shuffle the deck")
+	onEmpty := fs.String("on-empty", "", "what a draw does when the deck runs out of cards: none, reshuffle_discards, reshuffle_all or refill")
+	decks := fs.Int("decks", 0, "number of decks of a shoe (default 1)")
+	penetration := fs.Int("penetration", 0, "percentage of the shoe dealt before the cut card comes out")
+	autoReshuffle := fs.Bool("auto-reshuffle", false, "reshuffle the shoe before the next round after the cut card came out")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	opts := client.CreateOptions{OnEmpty: *onEmpty, Decks: *decks, Penetration: *penetration, AutoReshuffle: *autoReshuffle}
+	if *cards != "" {
+		opts.Cards = strings.Split(*cards, ",")
+	}
+	// send shuffled only when it was given, so the server default applies otherwise
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "shuffled" {
+			opts.Shuffled = shuffled
+		}
+	})
+
+	res, err := c.CreateDeck(ctx, opts)
+	if err != nil {
+		return err
+	}
+	return p.created(res)
+}
+
+func open(ctx context.Context, c *client.Client, p printer, args []string) error {
+	fs := flag.NewFlagSet("open", flag.ContinueOnError)
+	id, err := deckArg(fs, args)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.OpenDeck(ctx, id)
+	if err != nil {
+		return err
+	}
+	return p.opened(res)
+}
+
+func draw(ctx context.Context, c *client.Client, p printer, args []string) error {
+	fs := flag.NewFlagSet("draw", flag.ContinueOnError)
+	count := fs.Int("count", 1, "number of cards to draw")
+	id, err := deckArg(fs, args)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.DrawCards(ctx, id, *count)
+	if err != nil {
+		return err
+	}
+	return p.drawn(res)
+}
+
+func share(ctx context.Context, c *client.Client, p printer, args []string) error {
+	fs := flag.NewFlagSet("share", flag.ContinueOnError)
+	scope := fs.String("scope", "read", "granted scope, one of: read, draw")
+	maxCards := fs.Int("max-cards", 0, "maximum number of cards drawn with a draw token, 0 means unlimited")
+	ttl := fs.Duration("ttl", 0, "token lifetime (default server default)")
+	id, err := deckArg(fs, args)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.ShareDeck(ctx, id, api.ShareRequest{Scope: *scope, MaxCards: *maxCards, TTLSeconds: int(ttl.Seconds())})
+	if err != nil {
+		return err
+	}
+	return p.shared(res)
+}
+
+func watch(ctx context.Context, c *client.Client, p printer, args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	interval := fs.Duration("interval", 2*time.Second, "polling interval")
+	id, err := deckArg(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *interval <= 0 {
+		fs.Usage()
+		return fmt.Errorf("watch: -interval must be positive, got %s", *interval)
+	}
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	// the version changes with every operation, also with shuffles and restores that keep the number of cards
+	last := -1
+	for {
+		res, err := c.OpenDeck(ctx, id)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if res.Version != last {
+			last = res.Version
+			if err = p.opened(res); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// export writes the exported deck as indented JSON whatever the output format, so that it can be imported.
+func export(ctx context.Context, c *client.Client, out io.Writer, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	id, err := deckArg(fs, args)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.ExportDeck(ctx, id)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(res)
+}
+
+func importDeck(ctx context.Context, c *client.Client, p printer, in io.Reader, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("import: missing file")
+	}
+
+	if name := fs.Arg(0); name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	var export api.DeckExport
+	if err := json.NewDecoder(in).Decode(&export); err != nil {
+		return fmt.Errorf("unable to read exported deck: %w", err)
+	}
+
+	res, err := c.ImportDeck(ctx, export)
+	if err != nil {
+		return err
+	}
+	return p.created(res)
+}
+
+// deckArg parses the flags of a command and returns its deck id argument.
+// Flags are accepted before and after the deck id.
+func deckArg(fs *flag.FlagSet, args []string) (string, error) {
+	if err := fs.Parse(args); err != nil {
+		return "", err
+	}
+	if fs.NArg() == 0 {
+		return "", fmt.Errorf("%s: missing deck id", fs.Name())
+	}
+	id := fs.Arg(0)
+	if err := fs.Parse(fs.Args()[1:]); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func envOr(key, def string) string {
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		return v
+	}
+	return def
+}
+
+// problemMessage returns the detail of a problem, falling back to its title, followed by the invalid fields.
+func problemMessage(p api.Problem) string {
+	msg := p.Detail
+	if msg == "" {
+		msg = p.Title
+	}
+	for _, f := range p.Errors {
+		msg += fmt.Sprintf("\n  %s: %s", f.Field, f.Reason)
+	}
+	return msg
+}
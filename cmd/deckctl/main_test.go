@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"toggl-card-game/api"
+	"toggl-card-game/internal/auth"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/render"
+	"toggl-card-game/internal/repo"
+	"toggl-card-game/internal/server"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	signer := auth.NewSigner([]byte("secret"))
+	srv := &server.Server{
+		Auth:        auth.NewAuthenticator(map[string]string{"alice-key": "alice"}, nil),
+		Signer:      signer,
+		DeckService: deck.NewService(repo.NewInMemoryRepo()).WithGrantSigner(signer),
+	}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	t.Cleanup(server.Close)
+	return server
+}
+
+// deckctl runs the command against the server with the api key of alice and returns its output.
+func deckctl(t *testing.T, server *httptest.Server, in string, args ...string) (string, error) {
+	out := new(bytes.Buffer)
+	args = append([]string{"-server", server.URL, "-api-key", "alice-key"}, args...)
+	err := run(context.Background(), args, strings.NewReader(in), out)
+	return out.String(), err
+}
+
+// createDeck creates an unshuffled deck of the given cards and returns its id.
+func createDeck(t *testing.T, server *httptest.Server, cards string) string {
+	out, err := deckctl(t, server, "", "-o", "json", "create", "-cards", cards, "-shuffled=false")
+	assert.NoError(t, err)
+	created := new(api.CreateResponse)
+	assert.NoError(t, json.Unmarshal([]byte(out), created))
+	return created.DeckId
+}
+
+func TestRun_Flags(t *testing.T) {
+	server := newServer(t)
+	id := createDeck(t, server, "AS,KH")
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantErr    string
+		wantStatus int
+		wantOut    string
+	}{
+		{name: "missing command test", args: nil, wantErr: "missing command"},
+		{name: "unknown command test", args: []string{"deal"}, wantErr: `unknown command "deal"`},
+		{name: "unknown output format test", args: []string{"-o", "yaml", "create"}, wantErr: `unknown output format "yaml"`},
+		{name: "unknown flag test", args: []string{"create", "-jokers"}, wantErr: "flag provided but not defined: -jokers"},
+		{name: "missing deck id test", args: []string{"draw", "-count", "2"}, wantErr: "draw: missing deck id"},
+		{name: "missing import file test", args: []string{"import"}, wantErr: "import: missing file"},
+		{name: "zero watch interval test", args: []string{"watch", id, "-interval", "0s"}, wantErr: "watch: -interval must be positive, got 0s"},
+		{name: "negative watch interval test", args: []string{"watch", id, "-interval", "-1s"}, wantErr: "watch: -interval must be positive, got -1s"},
+		{name: "invalid count is rejected by the server test", args: []string{"draw", id, "-count", "0"}, wantStatus: http.StatusBadRequest},
+		{name: "invalid shoe is rejected by the server test", args: []string{"create", "-decks", "9"}, wantStatus: http.StatusBadRequest},
+		{name: "flags after the deck id test", args: []string{"-o", "table", "draw", id, "-count", "2"}, wantOut: "#  CODE  VALUE  SUIT\n1  AS    ACE    SPADES\n2  KH    KING   HEARTS\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := deckctl(t, server, "", tt.args...)
+
+			switch {
+			case tt.wantErr != "":
+				assert.ErrorContains(t, err, tt.wantErr)
+			case tt.wantStatus != 0:
+				var problem api.Problem
+				if assert.True(t, errors.As(err, &problem), "expected api.Problem, got %v", err) {
+					assert.Equal(t, tt.wantStatus, problem.Status)
+				}
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantOut, out)
+			}
+		})
+	}
+}
+
+func TestRun_Output(t *testing.T) {
+	server := newServer(t)
+	cards := []api.Card{
+		{Value: "ACE", Suit: "SPADES", Code: "AS"},
+		{Value: "KING", Suit: "HEARTS", Code: "KH"},
+	}
+
+	tests := []struct {
+		name string
+		// drawn is the number of cards drawn before the command
+		drawn   int
+		args    func(id string) []string
+		wantOut func(id string) string
+	}{
+		{
+			name: "open table test",
+			args: func(id string) []string { return []string{"-o", "table", "open", id} },
+			wantOut: func(id string) string {
+				return "DECK ID                               SHUFFLED  REMAINING\n" + id + "  false     2\n\n" +
+					"#  CODE  VALUE  SUIT\n1  AS    ACE    SPADES\n2  KH    KING   HEARTS\n"
+			},
+		},
+		{
+			name: "open json test",
+			args: func(id string) []string { return []string{"-o", "json", "open", id} },
+			wantOut: func(id string) string {
+				b, _ := json.MarshalIndent(api.OpenResponse{DeckId: id, Remaining: 2, Cards: cards}, "", "  ")
+				return string(b) + "\n"
+			},
+		},
+		{
+			name: "open pretty test",
+			args: func(id string) []string { return []string{"open", id} },
+			wantOut: func(id string) string {
+				return "Deck " + id + "\n2 cards, in order\n" + render.Boxes(cards, 13, render.Plain)
+			},
+		},
+		{
+			name: "draw pretty test",
+			args: func(id string) []string { return []string{"draw", "-count", "2", id} },
+			wantOut: func(id string) string {
+				return render.Boxes(cards, 13, render.Plain)
+			},
+		},
+		{
+			name:    "draw from empty deck pretty test",
+			drawn:   2,
+			args:    func(id string) []string { return []string{"draw", id} },
+			wantOut: func(id string) string { return "No cards left\n" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := createDeck(t, server, "AS,KH")
+			if tt.drawn > 0 {
+				_, err := deckctl(t, server, "", "draw", "-count", strconv.Itoa(tt.drawn), id)
+				assert.NoError(t, err)
+			}
+
+			out, err := deckctl(t, server, "", tt.args(id)...)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantOut(id), out)
+		})
+	}
+}
+
+func TestRun_ExportImport(t *testing.T) {
+	server := newServer(t)
+	id := createDeck(t, server, "AS,KH")
+
+	exported, err := deckctl(t, server, "", "export", id)
+	assert.NoError(t, err)
+	// the export is imported from stdin under a new id
+	anonymous := strings.Replace(exported, id, "", 1)
+
+	out, err := deckctl(t, server, anonymous, "-o", "json", "import", "-")
+	assert.NoError(t, err)
+	imported := new(api.CreateResponse)
+	assert.NoError(t, json.Unmarshal([]byte(out), imported))
+	assert.NotEqual(t, id, imported.DeckId)
+	assert.Equal(t, 2, imported.Remaining)
+}
+
+// writes sends every write to a channel, so that a test can wait for the output of a running command.
+type writes chan []byte
+
+func (w writes) Write(b []byte) (int, error) {
+	w <- bytes.Clone(b)
+	return len(b), nil
+}
+
+func TestRun_Watch(t *testing.T) {
+	server := newServer(t)
+	id := createDeck(t, server, "AS,KH")
+	ctx, cancel := context.WithCancel(context.Background())
+	out := make(writes, 8)
+	done := make(chan error, 1)
+	go func() {
+		args := []string{"-server", server.URL, "-api-key", "alice-key", "-o", "json", "watch", id, "-interval", "10ms"}
+		done <- run(ctx, args, strings.NewReader(""), out)
+	}()
+	opened := func() api.OpenResponse {
+		var res api.OpenResponse
+		select {
+		case b := <-out:
+			assert.NoError(t, json.Unmarshal(b, &res))
+		case err := <-done:
+			t.Fatalf("watch stopped: %v", err)
+		}
+		return res
+	}
+
+	assert.Equal(t, 0, opened().Version)
+
+	// a shuffle keeps the number of remaining cards
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/api/v1/deck/"+id+"/batch", strings.NewReader(`{"steps": [{"op": "shuffle"}]}`))
+	req.Header.Set("X-API-Key", "alice-key")
+	resp, err := http.DefaultClient.Do(req)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	shuffled := opened()
+	assert.Equal(t, 1, shuffled.Version)
+	assert.Equal(t, 2, shuffled.Remaining)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+	"toggl-card-game/api"
+	"toggl-card-game/internal/render"
+)
+
+// printer writes api responses in one of the output formats.
+type printer interface {
+	created(res *api.CreateResponse) error
+	opened(res *api.OpenResponse) error
+	drawn(res *api.DrawResponse) error
+	shared(res *api.ShareResponse) error
+}
+
+func newPrinter(format string, out io.Writer) (printer, error) {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return jsonPrinter{enc: enc}, nil
+	case "table":
+		return tablePrinter{out: out}, nil
+	case "pretty":
+		return prettyPrinter{out: out}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q", format)
+}
+
+type jsonPrinter struct {
+	enc *json.Encoder
+}
+
+func (p jsonPrinter) created(res *api.CreateResponse) error { return p.enc.Encode(res) }
+func (p jsonPrinter) opened(res *api.OpenResponse) error    { return p.enc.Encode(res) }
+func (p jsonPrinter) drawn(res *api.DrawResponse) error     { return p.enc.Encode(res) }
+func (p jsonPrinter) shared(res *api.ShareResponse) error   { return p.enc.Encode(res) }
+
+type tablePrinter struct {
+	out io.Writer
+}
+
+func (p tablePrinter) table(write func(w io.Writer)) error {
+	tw := tabwriter.NewWriter(p.out, 0, 4, 2, ' ', 0)
+	write(tw)
+	return tw.Flush()
+}
+
+func (p tablePrinter) created(res *api.CreateResponse) error {
+	return p.table(func(w io.Writer) {
+		fmt.Fprintln(w, "DECK ID\tSHUFFLED\tREMAINING")
+		fmt.Fprintf(w, "%s\t%t\t%d\n", res.DeckId, res.Shuffled, res.Remaining)
+	})
+}
+
+func (p tablePrinter) opened(res *api.OpenResponse) error {
+	if err := p.created(&api.CreateResponse{DeckId: res.DeckId, Shuffled: res.Shuffled, Remaining: res.Remaining}); err != nil {
+		return err
+	}
+	fmt.Fprintln(p.out)
+	return p.cards(res.Cards)
+}
+
+func (p tablePrinter) drawn(res *api.DrawResponse) error {
+	return p.cards(res.Cards)
+}
+
+func (p tablePrinter) cards(cards []api.Card) error {
+	return p.table(func(w io.Writer) {
+		fmt.Fprintln(w, "#\tCODE\tVALUE\tSUIT")
+		for i, c := range cards {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", i+1, c.Code, c.Value, c.Suit)
+		}
+	})
+}
+
+func (p tablePrinter) shared(res *api.ShareResponse) error {
+	return p.table(func(w io.Writer) {
+		fmt.Fprintln(w, "DECK ID\tSCOPE\tMAX CARDS\tEXPIRES AT\tTOKEN")
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", res.DeckId, res.Scope, res.MaxCards, res.ExpiresAt.Format(time.RFC3339), res.Token)
+	})
+}
+
+type prettyPrinter struct {
+	out io.Writer
+}
+
+func (p prettyPrinter) created(res *api.CreateResponse) error {
+	_, err := fmt.Fprintf(p.out, "Deck %s\n%d cards, %s\n", res.DeckId, res.Remaining, shuffledLabel(res.Shuffled))
+	return err
+}
+
+func (p prettyPrinter) opened(res *api.OpenResponse) error {
+	if err := p.created(&api.CreateResponse{DeckId: res.DeckId, Shuffled: res.Shuffled, Remaining: res.Remaining}); err != nil {
+		return err
+	}
+	_, err := fmt.Fprint(p.out, render.Boxes(res.Cards, 13, render.Plain))
+	return err
+}
+
+func (p prettyPrinter) drawn(res *api.DrawResponse) error {
+	if len(res.Cards) == 0 {
+		_, err := fmt.Fprintln(p.out, "No cards left")
+		return err
+	}
+	if r := res.Reshuffled; r != nil && r.Policy == "cut_card" {
+		if _, err := fmt.Fprintf(p.out, "Shoe reshuffled, %d cards\n", r.Cards); err != nil {
+			return err
+		}
+	} else if r != nil {
+		if _, err := fmt.Fprintf(p.out, "Deck ran out after %d cards, %d cards shuffled in (%s)\n", r.After, r.Cards, r.Policy); err != nil {
+			return err
+		}
+	}
+	if pen := res.Penetration; pen != nil && pen.ReshuffleNeeded {
+		if _, err := fmt.Fprintf(p.out, "Cut card is out after %d of %d cards, reshuffle needed\n", pen.Dealt, pen.CutCard); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprint(p.out, render.Boxes(res.Cards, 13, render.Plain))
+	return err
+}
+
+func (p prettyPrinter) shared(res *api.ShareResponse) error {
+	limit := "unlimited"
+	if res.MaxCards > 0 {
+		limit = fmt.Sprintf("up to %d cards", res.MaxCards)
+	}
+	if res.Scope != "draw" {
+		limit = "read only"
+	}
+	_, err := fmt.Fprintf(p.out, "Deck %s shared, %s, expires %s\n%s\n", res.DeckId, limit, res.ExpiresAt.Format(time.RFC3339), res.Token)
+	return err
+}
+
+func shuffledLabel(shuffled bool) string {
+	if shuffled {
+		return "shuffled"
+	}
+	return "in order"
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"toggl-card-game/api"
+	"toggl-card-game/internal/render"
+)
+
+// game is a game played with the deck on the table.
+type game interface {
+	name() string
+	help() string
+	start(t *table) error
+	key(t *table, k byte) error
+	view(t *table, b *strings.Builder, paint render.Painter)
+}
+
+// freePlay lets the player draw, shuffle and sort a hand without any rules.
+type freePlay struct{}
+
+func (freePlay) name() string { return "Free play" }
+
+func (freePlay) help() string {
+	return "d draw · f draw five · s shuffle deck · o sort hand · c discard hand · n new deck"
+}
+
+func (freePlay) start(t *table) error {
+	t.message = "Draw some cards."
+	return t.newDeck(true)
+}
+
+func (g freePlay) key(t *table, k byte) error {
+	switch k {
+	case 'd', 'f':
+		n := 1
+		if k == 'f' {
+			n = 5
+		}
+		cards, err := t.draw(n)
+		if err != nil {
+			return err
+		}
+		if len(cards) == 0 {
+			t.message = "The deck is empty."
+			return nil
+		}
+		t.hand = append(t.hand, cards...)
+		t.message = fmt.Sprintf("Drew %d card(s).", len(cards))
+	case 's':
+		t.message = "Deck shuffled."
+		return t.shuffle()
+	case 'o':
+		sortCards(t.hand)
+		t.message = "Hand sorted."
+	case 'c':
+		t.hand = nil
+		t.message = "Hand discarded."
+	case 'n':
+		return g.start(t)
+	}
+	return nil
+}
+
+func (freePlay) view(t *table, b *strings.Builder, paint render.Painter) {
+	fmt.Fprintf(b, "Hand (%d cards):\n", len(t.hand))
+	b.WriteString(render.Boxes(t.hand, 10, paint))
+}
+
+// elevens is a solitaire: remove pairs of cards whose values add up to target,
+// or a jack, queen and king together, and refill the tableau from the deck.
+// The game is won when every card has been removed.
+type elevens struct {
+	target   int
+	slots    int
+	tableau  []*api.Card
+	selected map[int]bool
+}
+
+func newElevens(target, slots int) *elevens {
+	return &elevens{target: target, slots: slots}
+}
+
+func (g *elevens) name() string { return fmt.Sprintf("Elevens (target %d)", g.target) }
+
+func (g *elevens) help() string {
+	return fmt.Sprintf("1-%d select · space remove selected · n new game", g.slots)
+}
+
+func (g *elevens) start(t *table) error {
+	if err := t.newDeck(true); err != nil {
+		return err
+	}
+	g.tableau = make([]*api.Card, g.slots)
+	g.selected = make(map[int]bool)
+	if err := g.refill(t); err != nil {
+		return err
+	}
+	t.message = fmt.Sprintf("Remove pairs adding up to %d, or J Q K.", g.target)
+	return nil
+}
+
+func (g *elevens) refill(t *table) error {
+	for i, c := range g.tableau {
+		if c != nil {
+			continue
+		}
+		cards, err := t.draw(1)
+		if err != nil {
+			return err
+		}
+		if len(cards) == 1 {
+			g.tableau[i] = &cards[0]
+		}
+	}
+	return nil
+}
+
+func (g *elevens) key(t *table, k byte) error {
+	switch {
+	case k >= '1' && k <= '9' && int(k-'1') < g.slots:
+		i := int(k - '1')
+		if g.tableau[i] != nil {
+			g.selected[i] = !g.selected[i]
+		}
+	case k == ' ' || k == '\r':
+		return g.remove(t)
+	case k == 'n':
+		return g.start(t)
+	}
+	return nil
+}
+
+func (g *elevens) remove(t *table) error {
+	var cards []api.Card
+	for i, sel := range g.selected {
+		if sel {
+			cards = append(cards, *g.tableau[i])
+		}
+	}
+	if !g.valid(cards) {
+		t.message = "That is not a valid set."
+		return nil
+	}
+
+	for i, sel := range g.selected {
+		if sel {
+			g.tableau[i] = nil
+		}
+	}
+	g.selected = make(map[int]bool)
+	if err := g.refill(t); err != nil {
+		return err
+	}
+
+	switch {
+	case g.cleared():
+		t.message = "You won! Press n for a new game."
+	case !g.hasMove():
+		t.message = "No moves left, you lost. Press n for a new game."
+	default:
+		t.message = "Nice."
+	}
+	return nil
+}
+
+// valid returns true for two number cards adding up to the target or for a jack, queen and king.
+func (g *elevens) valid(cards []api.Card) bool {
+	switch len(cards) {
+	case 2:
+		a, b := rankValue(cards[0]), rankValue(cards[1])
+		return a <= 10 && b <= 10 && a+b == g.target
+	case 3:
+		faces := map[string]bool{}
+		for _, c := range cards {
+			faces[c.Value] = true
+		}
+		return faces["JACK"] && faces["QUEEN"] && faces["KING"]
+	}
+	return false
+}
+
+func (g *elevens) cleared() bool {
+	for _, c := range g.tableau {
+		if c != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *elevens) hasMove() bool {
+	var cards []api.Card
+	for _, c := range g.tableau {
+		if c != nil {
+			cards = append(cards, *c)
+		}
+	}
+	for i := range cards {
+		for j := i + 1; j < len(cards); j++ {
+			if g.valid([]api.Card{cards[i], cards[j]}) {
+				return true
+			}
+			for k := j + 1; k < len(cards); k++ {
+				if g.valid([]api.Card{cards[i], cards[j], cards[k]}) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (g *elevens) view(t *table, b *strings.Builder, paint render.Painter) {
+	for row := 0; row < g.slots; row += 3 {
+		var cards []api.Card
+		labels := new(strings.Builder)
+		for i := row; i < min(row+3, g.slots); i++ {
+			if g.tableau[i] == nil {
+				continue
+			}
+			cards = append(cards, *g.tableau[i])
+			mark := " "
+			if g.selected[i] {
+				mark = "*"
+			}
+			fmt.Fprintf(labels, "  %s%d%s   ", mark, i+1, mark)
+		}
+		b.WriteString(render.Boxes(cards, 3, paint))
+		b.WriteString(labels.String() + "\n")
+	}
+}
+
+// higherLower is a guessing game: guess whether the next card is higher or lower than the current one.
+type higherLower struct {
+	current *api.Card
+	score   int
+	streak  int
+}
+
+func (g *higherLower) name() string { return "Higher or lower" }
+
+func (g *higherLower) help() string { return "h higher · l lower · n new game" }
+
+func (g *higherLower) start(t *table) error {
+	if err := t.newDeck(true); err != nil {
+		return err
+	}
+	cards, err := t.draw(1)
+	if err != nil {
+		return err
+	}
+	g.current = &cards[0]
+	g.score, g.streak = 0, 0
+	t.message = "Is the next card higher or lower?"
+	return nil
+}
+
+func (g *higherLower) key(t *table, k byte) error {
+	if k == 'n' {
+		return g.start(t)
+	}
+	if (k != 'h' && k != 'l') || g.current == nil {
+		return nil
+	}
+
+	cards, err := t.draw(1)
+	if err != nil {
+		return err
+	}
+	if len(cards) == 0 {
+		t.message = fmt.Sprintf("The deck is empty, final score %d. Press n for a new game.", g.score)
+		return nil
+	}
+
+	next := cards[0]
+	a, b := rankValue(*g.current), rankValue(next)
+	switch {
+	case a == b:
+		t.message = fmt.Sprintf("%s is a tie, no points.", render.Short(next))
+	case (k == 'h') == (b > a):
+		g.score++
+		g.streak++
+		t.message = fmt.Sprintf("%s, correct! Streak %d.", render.Short(next), g.streak)
+	default:
+		g.streak = 0
+		t.message = fmt.Sprintf("%s, wrong.", render.Short(next))
+	}
+	g.current = &next
+	return nil
+}
+
+func (g *higherLower) view(t *table, b *strings.Builder, paint render.Painter) {
+	fmt.Fprintf(b, "Score %d · streak %d\n", g.score, g.streak)
+	if g.current != nil {
+		b.WriteString(render.Boxes([]api.Card{*g.current}, 1, paint))
+	}
+}
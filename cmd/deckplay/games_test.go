@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"toggl-card-game/api"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/render"
+	"toggl-card-game/internal/repo"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTable() *table {
+	return &table{ctx: context.Background(), svc: deck.NewService(repo.NewInMemoryRepo())}
+}
+
+func TestElevens_Valid(t *testing.T) {
+	card := func(v string) api.Card { return api.Card{Value: v, Suit: "SPADES"} }
+	g := newElevens(11, 9)
+
+	tests := []struct {
+		name  string
+		cards []api.Card
+		want  bool
+	}{
+		{"Pair adding up to eleven test", []api.Card{card("ACE"), card("10")}, true},
+		{"Pair not adding up to eleven test", []api.Card{card("2"), card("10")}, false},
+		{"Face cards do not count as numbers test", []api.Card{card("ACE"), card("JACK")}, false},
+		{"Jack queen king test", []api.Card{card("KING"), card("JACK"), card("QUEEN")}, true},
+		{"Three number cards test", []api.Card{card("2"), card("4"), card("5")}, false},
+		{"Single card test", []api.Card{card("ACE")}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, g.valid(tt.cards))
+		})
+	}
+}
+
+func TestSortCards(t *testing.T) {
+	cards := []api.Card{{Value: "KING", Suit: "HEARTS"}, {Value: "10", Suit: "SPADES"}, {Value: "ACE", Suit: "HEARTS"}, {Value: "2", Suit: "SPADES"}}
+	sortCards(cards)
+
+	var got []string
+	for _, c := range cards {
+		got = append(got, render.Short(c))
+	}
+	assert.Equal(t, []string{"2♠", "10♠", "A♥", "K♥"}, got)
+}
+
+func TestPlay(t *testing.T) {
+	tbl := newTable()
+	in := iotest.OneByteReader(strings.NewReader("dfxq"))
+
+	err := play(tbl, []game{freePlay{}}, in, io.Discard, render.Plain)
+
+	assert.NoError(t, err)
+	assert.Len(t, tbl.hand, 6)
+	assert.Equal(t, 46, tbl.remaining)
+}
+
+func TestPlay_HigherLowerEmptiesDeck(t *testing.T) {
+	tbl := newTable()
+	g := &higherLower{}
+	in := iotest.OneByteReader(strings.NewReader(strings.Repeat("h", 60)))
+
+	err := play(tbl, []game{g}, in, io.Discard, render.Plain)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, tbl.remaining)
+	assert.Contains(t, tbl.message, "The deck is empty")
+}
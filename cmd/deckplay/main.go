@@ -0,0 +1,114 @@
+// Command deckplay is a terminal card table for playing with decks locally.
+//
+// It runs the deck service in-process with an in-memory repository, so no
+// server is needed. Tab switches between the games and q quits.
+//
+// Usage:
+//
+//	deckplay [flags]
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/render"
+	"toggl-card-game/internal/repo"
+
+	"golang.org/x/term"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		fmt.Fprintf(os.Stderr, "deckplay: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("deckplay", flag.ContinueOnError)
+	noColor := fs.Bool("no-color", os.Getenv("NO_COLOR") != "", "do not colour red suits (NO_COLOR)")
+	target := fs.Int("elevens-target", 11, "sum of a pair removed in elevens")
+	slots := fs.Int("elevens-slots", 9, "number of cards on the elevens tableau, at most 9")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *slots < 2 || *slots > 9 {
+		return fmt.Errorf("elevens-slots must be between 2 and 9")
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return errors.New("stdin is not a terminal")
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, state)
+
+	paint := render.ANSI
+	if *noColor {
+		paint = render.Plain
+	}
+
+	t := &table{ctx: context.Background(), svc: deck.NewService(repo.NewInMemoryRepo())}
+	games := []game{freePlay{}, newElevens(*target, *slots), &higherLower{}}
+	return play(t, games, os.Stdin, os.Stdout, paint)
+}
+
+// play runs the key loop until the player quits or the input ends.
+func play(t *table, games []game, in io.Reader, out io.Writer, paint render.Painter) error {
+	current := 0
+	if err := games[current].start(t); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 16)
+	for {
+		draw(t, games[current], out, paint)
+
+		n, err := in.Read(buf)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// Escape sequences, e.g. arrow keys, are ignored.
+		if n == 0 || (buf[0] == 0x1b && n > 1) {
+			continue
+		}
+
+		switch k := buf[0]; k {
+		case 'q', 0x03, 0x04:
+			fmt.Fprint(out, "\x1b[H\x1b[2J")
+			return nil
+		case '\t':
+			current = (current + 1) % len(games)
+			err = games[current].start(t)
+		default:
+			err = games[current].key(t, k)
+		}
+		if err != nil {
+			t.message = "Error: " + err.Error()
+		}
+	}
+}
+
+// draw clears the screen and renders the table. The terminal is in raw mode, so lines end with \r\n.
+func draw(t *table, g game, out io.Writer, paint render.Painter) {
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "deckplay · %s · %d cards in the deck\n\n", g.name(), t.remaining)
+	g.view(t, b, paint)
+	fmt.Fprintf(b, "\n%s\n\n%s · tab next game · q quit\n", t.message, g.help())
+	fmt.Fprint(out, "\x1b[H\x1b[2J"+strings.ReplaceAll(b.String(), "\n", "\r\n"))
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"toggl-card-game/api"
+	"toggl-card-game/internal/core/deck"
+)
+
+// table holds the state shared by all games: the deck in the in-process service and the hand of the player.
+type table struct {
+	ctx       context.Context
+	svc       *deck.Service
+	deckId    string
+	remaining int
+	hand      []api.Card
+	message   string
+}
+
+// newDeck replaces the deck on the table with a new full deck.
+func (t *table) newDeck(shuffled bool) error {
+	res, err := t.svc.CreateDeck(t.ctx, deck.CreateRequest{Shuffled: shuffled})
+	if err != nil {
+		return err
+	}
+	t.deckId = res.DeckId
+	t.remaining = res.Remaining
+	t.hand = nil
+	return nil
+}
+
+// draw draws up to n cards from the deck.
+func (t *table) draw(n int) ([]api.Card, error) {
+	if t.remaining == 0 {
+		return nil, nil
+	}
+	res, err := t.svc.DrawCards(t.ctx, deck.DrawRequest{DeckId: t.deckId, Count: min(n, t.remaining)})
+	if err != nil {
+		return nil, err
+	}
+	t.remaining -= len(res.Cards)
+	return res.Cards, nil
+}
+
+// shuffle shuffles the remaining cards of the deck.
+func (t *table) shuffle() error {
+	res, err := t.svc.ShuffleDeck(t.ctx, deck.ShuffleRequest{DeckId: t.deckId})
+	if err != nil {
+		return err
+	}
+	t.remaining = res.Remaining
+	return nil
+}
+
+var suitOrder = map[string]int{"SPADES": 0, "HEARTS": 1, "CLUBS": 2, "DIAMONDS": 3}
+
+// rankValue returns the value of a card rank, ace is 1 and king is 13.
+func rankValue(c api.Card) int {
+	switch c.Value {
+	case "ACE":
+		return 1
+	case "JACK":
+		return 11
+	case "QUEEN":
+		return 12
+	case "KING":
+		return 13
+	}
+	v := 0
+	for _, r := range c.Value {
+		v = v*10 + int(r-'0')
+	}
+	return v
+}
+
+// sortCards sorts cards by suit and then by rank.
+func sortCards(cards []api.Card) {
+	sort.SliceStable(cards, func(i, j int) bool {
+		if cards[i].Suit != cards[j].Suit {
+			return suitOrder[cards[i].Suit] < suitOrder[cards[j].Suit]
+		}
+		return rankValue(cards[i]) < rankValue(cards[j])
+	})
+}
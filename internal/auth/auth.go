@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrMissingCredentials = errors.New("missing credentials")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Principal represents an authenticated client of the api.
+type Principal struct {
+	Subject string
+}
+
+type principalKey struct{}
+
+// NewContext returns a copy of ctx that carries the given principal.
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored in ctx, if any.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// Authenticator verifies api keys and HMAC signed bearer tokens without calling any external service.
+type Authenticator struct {
+	keys   map[[sha256.Size]byte]string
+	signer *Signer
+}
+
+// NewAuthenticator creates a new authenticator.
+// The keys map holds api keys mapped to the subject (owner) they identify.
+// A nil signer disables bearer tokens.
+func NewAuthenticator(keys map[string]string, signer *Signer) *Authenticator {
+	hashed := make(map[[sha256.Size]byte]string, len(keys))
+	for key, subject := range keys {
+		hashed[sha256.Sum256([]byte(key))] = subject
+	}
+	return &Authenticator{
+		keys:   hashed,
+		signer: signer,
+	}
+}
+
+// Enabled returns true if at least one api key or a token signer is configured.
+func (a *Authenticator) Enabled() bool {
+	return len(a.keys) > 0 || a.signer != nil
+}
+
+// Authenticate extracts and verifies the credentials of the given request.
+// Api keys are read from the X-API-Key header and tokens from the Authorization bearer header.
+func (a *Authenticator) Authenticate(r *http.Request) (Principal, error) {
+	return a.AuthenticateCredentials(r.Header.Get("X-API-Key"), r.Header.Get("Authorization"))
+}
+
+// AuthenticateCredentials verifies an api key or, when the key is empty, an Authorization header value.
+// It is used by transports that do not carry credentials in http headers, e.g. gRPC metadata.
+func (a *Authenticator) AuthenticateCredentials(apiKey, authorization string) (Principal, error) {
+	if apiKey != "" {
+		return a.verifyKey(apiKey)
+	}
+
+	if token, ok := bearerToken(authorization); ok {
+		if a.signer == nil {
+			return Principal{}, ErrInvalidCredentials
+		}
+		claims, err := a.signer.Verify(token)
+		if err != nil {
+			return Principal{}, err
+		}
+		// capability tokens identify a deck, not a client
+		if claims.IsCapability() {
+			return Principal{}, ErrInvalidCredentials
+		}
+		return Principal{Subject: claims.Subject}, nil
+	}
+
+	return Principal{}, ErrMissingCredentials
+}
+
+func (a *Authenticator) verifyKey(key string) (Principal, error) {
+	sum := sha256.Sum256([]byte(key))
+	for k, subject := range a.keys {
+		if subtle.ConstantTimeCompare(sum[:], k[:]) == 1 {
+			return Principal{Subject: subject}, nil
+		}
+	}
+	return Principal{}, ErrInvalidCredentials
+}
+
+func bearerToken(h string) (string, bool) {
+	scheme, token, ok := strings.Cut(h, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+// ParseKeys parses api keys in the "key:subject,key:subject" format.
+func ParseKeys(s string) (map[string]string, error) {
+	keys := make(map[string]string)
+	if strings.TrimSpace(s) == "" {
+		return keys, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		key, subject, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || key == "" || subject == "" {
+			return nil, fmt.Errorf("invalid api key entry [%s], expected key:subject", pair)
+		}
+		keys[key] = subject
+	}
+	return keys, nil
+}
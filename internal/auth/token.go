@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+	"toggl-card-game/internal/core/deck"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// Claims represents the payload of a signed token.
+// Identity tokens carry a subject, capability tokens carry a deck and a scope.
+type Claims struct {
+	Id        string `json:"jti,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	Deck      string `json:"deck,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+	MaxCards  int    `json:"max,omitempty"`
+}
+
+// IsCapability returns true if the claims grant access to a single deck.
+func (c Claims) IsCapability() bool {
+	return c.Deck != ""
+}
+
+// Signer signs and verifies tokens with HMAC-SHA256.
+// A token has the form base64url(claims).base64url(signature).
+type Signer struct {
+	secret []byte
+	now    func() time.Time
+}
+
+// NewSigner creates a new token signer with the given secret.
+func NewSigner(secret []byte) *Signer {
+	return &Signer{
+		secret: secret,
+		now:    time.Now,
+	}
+}
+
+// Sign encodes and signs the given claims.
+func (s *Signer) Sign(claims Claims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding.EncodeToString(payload)
+	return enc + "." + base64.RawURLEncoding.EncodeToString(s.mac(enc)), nil
+}
+
+// Verify checks the token signature and expiry and returns its claims.
+func (s *Signer) Verify(token string) (Claims, error) {
+	var claims Claims
+
+	enc, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return claims, ErrInvalidToken
+	}
+
+	given, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(given, s.mac(enc)) {
+		return claims, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(enc)
+	if err != nil {
+		return claims, ErrInvalidToken
+	}
+	if err = json.Unmarshal(payload, &claims); err != nil || (claims.Subject == "" && !claims.IsCapability()) {
+		return claims, ErrInvalidToken
+	}
+
+	if claims.ExpiresAt != 0 && s.now().Unix() >= claims.ExpiresAt {
+		return claims, ErrTokenExpired
+	}
+
+	return claims, nil
+}
+
+func (s *Signer) mac(data string) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// SignGrant implements deck.GrantSigner interface.
+func (s *Signer) SignGrant(grant deck.Grant) (string, error) {
+	return s.Sign(Claims{
+		Id:        grant.Id,
+		ExpiresAt: grant.ExpiresAt.Unix(),
+		Deck:      grant.DeckId,
+		Scope:     string(grant.Scope),
+		MaxCards:  grant.MaxCards,
+	})
+}
+
+// VerifyGrant verifies a capability token and returns the grant it carries.
+func (s *Signer) VerifyGrant(token string) (deck.Grant, error) {
+	claims, err := s.Verify(token)
+	if err != nil {
+		return deck.Grant{}, err
+	}
+	if !claims.IsCapability() {
+		return deck.Grant{}, ErrInvalidToken
+	}
+	return deck.Grant{
+		Id:        claims.Id,
+		DeckId:    claims.Deck,
+		Scope:     deck.Scope(claims.Scope),
+		MaxCards:  claims.MaxCards,
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+	}, nil
+}
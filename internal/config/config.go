@@ -0,0 +1,363 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"toggl-card-game/internal/ratelimit"
+)
+
+const RepoMemory = "memory"
+
+// Config holds the server configuration.
+type Config struct {
+	Host            string
+	Port            int
+	GRPCPort        int
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	ShutdownTimeout time.Duration
+
+	RepoBackend string
+
+	ShuffleByDefault bool
+	ShareTokenTTL    time.Duration
+	ShareTokenMaxTTL time.Duration
+
+	APIKeys         string
+	AuthTokenSecret string
+
+	RateLimitCreate      string
+	RateLimitOpen        string
+	RateLimitDraw        string
+	RateLimitShare       string
+	DeckCreateDailyQuota int
+
+	IdempotencyTTL time.Duration
+
+	VersionRetention int
+
+	LegacySunset time.Time
+
+	LogLevel  slog.Level
+	LogFormat string
+}
+
+// Default returns the configuration used when nothing is overridden.
+func Default() *Config {
+	return &Config{
+		Port:                 8080,
+		GRPCPort:             9090,
+		ReadTimeout:          10 * time.Second,
+		WriteTimeout:         30 * time.Second,
+		IdleTimeout:          time.Minute,
+		ShutdownTimeout:      15 * time.Second,
+		RepoBackend:          RepoMemory,
+		ShareTokenTTL:        time.Hour,
+		ShareTokenMaxTTL:     7 * 24 * time.Hour,
+		RateLimitCreate:      "5/20",
+		RateLimitOpen:        "20/50",
+		RateLimitDraw:        "20/50",
+		RateLimitShare:       "1/10",
+		DeckCreateDailyQuota: 1000,
+		IdempotencyTTL:       24 * time.Hour,
+		VersionRetention:     1000,
+		LegacySunset:         time.Date(2027, time.June, 30, 0, 0, 0, 0, time.UTC),
+		LogLevel:             slog.LevelInfo,
+		LogFormat:            "text",
+	}
+}
+
+// field describes a configuration option. The key is used in the config file,
+// the upper-cased key is the environment variable and the dashed key is the command-line flag.
+type field struct {
+	key    string
+	usage  string
+	value  flag.Value
+	secret bool
+}
+
+func (f field) env() string {
+	return strings.ToUpper(f.key)
+}
+
+func (f field) flag() string {
+	return strings.ReplaceAll(f.key, "_", "-")
+}
+
+func (c *Config) fields() []field {
+	return []field{
+		{key: "host", usage: "host to listen on, empty for all interfaces", value: (*stringValue)(&c.Host)},
+		{key: "port", usage: "port to listen on", value: (*intValue)(&c.Port)},
+		{key: "grpc_port", usage: "port of the gRPC server, 0 disables it", value: (*intValue)(&c.GRPCPort)},
+		{key: "read_timeout", usage: "maximum duration for reading a request", value: (*durationValue)(&c.ReadTimeout)},
+		{key: "write_timeout", usage: "maximum duration for writing a response", value: (*durationValue)(&c.WriteTimeout)},
+		{key: "idle_timeout", usage: "maximum duration of idle keep-alive connections", value: (*durationValue)(&c.IdleTimeout)},
+		{key: "shutdown_timeout", usage: "maximum duration to drain in-flight requests on shutdown", value: (*durationValue)(&c.ShutdownTimeout)},
+		{key: "repo_backend", usage: "deck repository backend, one of: memory", value: (*stringValue)(&c.RepoBackend)},
+		{key: "shuffle_by_default", usage: "shuffle new decks when the shuffled parameter is absent", value: (*boolValue)(&c.ShuffleByDefault)},
+		{key: "share_token_ttl", usage: "default lifetime of share tokens", value: (*durationValue)(&c.ShareTokenTTL)},
+		{key: "share_token_max_ttl", usage: "maximum lifetime of share tokens", value: (*durationValue)(&c.ShareTokenMaxTTL)},
+		{key: "api_keys", usage: "api keys in the key:owner,key:owner format", value: (*stringValue)(&c.APIKeys), secret: true},
+		{key: "auth_token_secret", usage: "secret for HMAC signed bearer tokens", value: (*stringValue)(&c.AuthTokenSecret), secret: true},
+		{key: "rate_limit_create", usage: "create deck rate limit in the rate/burst format", value: (*stringValue)(&c.RateLimitCreate)},
+		{key: "rate_limit_open", usage: "open deck rate limit in the rate/burst format", value: (*stringValue)(&c.RateLimitOpen)},
+		{key: "rate_limit_draw", usage: "draw cards rate limit in the rate/burst format", value: (*stringValue)(&c.RateLimitDraw)},
+		{key: "rate_limit_share", usage: "share deck rate limit in the rate/burst format", value: (*stringValue)(&c.RateLimitShare)},
+		{key: "deck_create_daily_quota", usage: "maximum number of decks a client can create per day", value: (*intValue)(&c.DeckCreateDailyQuota)},
+		{key: "idempotency_ttl", usage: "how long responses of requests with an Idempotency-Key are replayed, 0 disables it", value: (*durationValue)(&c.IdempotencyTTL)},
+		{key: "version_retention", usage: "number of the latest versions kept per deck, 0 keeps every version", value: (*intValue)(&c.VersionRetention)},
+		{key: "legacy_sunset", usage: "date in the YYYY-MM-DD format after which deprecated routes may be removed, empty for none", value: (*dateValue)(&c.LegacySunset)},
+		{key: "log_level", usage: "log level, one of: debug, info, warn, error", value: (*levelValue)(&c.LogLevel)},
+		{key: "log_format", usage: "log format, one of: text, json", value: (*stringValue)(&c.LogFormat)},
+	}
+}
+
+// Load builds the configuration from defaults, an optional JSON config file,
+// environment variables and command-line flags, in increasing order of precedence.
+// The config file is given by the -config flag or the CONFIG_FILE environment variable.
+func Load(args []string) (*Config, error) {
+	cfg := Default()
+	fields := cfg.fields()
+
+	// flags are parsed first but applied last, so they override the other sources
+	fs := flag.NewFlagSet("api", flag.ContinueOnError)
+	configFile := fs.String("config", "", "path to a JSON config file")
+	flags := make(map[string]string)
+	for _, f := range fields {
+		if _, ok := f.value.(*boolValue); ok {
+			fs.BoolFunc(f.flag(), f.usage, func(s string) error { flags[f.key] = s; return nil })
+			continue
+		}
+		fs.Func(f.flag(), f.usage, func(s string) error { flags[f.key] = s; return nil })
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *configFile == "" {
+		*configFile, _ = os.LookupEnv("CONFIG_FILE")
+	}
+	if *configFile != "" {
+		if err := cfg.loadFile(*configFile, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, f := range fields {
+		if v, ok := os.LookupEnv(f.env()); ok && strings.TrimSpace(v) != "" {
+			if err := f.value.Set(v); err != nil {
+				return nil, fmt.Errorf("invalid %s environment variable: %w", f.env(), err)
+			}
+		}
+	}
+
+	for _, f := range fields {
+		if v, ok := flags[f.key]; ok {
+			if err := f.value.Set(v); err != nil {
+				return nil, fmt.Errorf("invalid -%s flag: %w", f.flag(), err)
+			}
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string, fields []field) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read config file: %w", err)
+	}
+
+	values := make(map[string]any)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err = dec.Decode(&values); err != nil {
+		return fmt.Errorf("unable to parse config file %s: %w", path, err)
+	}
+
+	known := make(map[string]field, len(fields))
+	for _, f := range fields {
+		known[f.key] = f
+	}
+	for key, v := range values {
+		f, ok := known[key]
+		if !ok {
+			return fmt.Errorf("unknown key %q in config file %s", key, path)
+		}
+		if err = f.value.Set(fmt.Sprint(v)); err != nil {
+			return fmt.Errorf("invalid %q in config file %s: %w", key, path, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks that the configuration is complete and consistent.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Port < 0 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("port must be between 0 and 65535, got %d", c.Port))
+	}
+	if c.GRPCPort < 0 || c.GRPCPort > 65535 {
+		errs = append(errs, fmt.Errorf("grpc_port must be between 0 and 65535, got %d", c.GRPCPort))
+	}
+	for name, d := range map[string]time.Duration{
+		"read_timeout":        c.ReadTimeout,
+		"write_timeout":       c.WriteTimeout,
+		"idle_timeout":        c.IdleTimeout,
+		"shutdown_timeout":    c.ShutdownTimeout,
+		"share_token_ttl":     c.ShareTokenTTL,
+		"share_token_max_ttl": c.ShareTokenMaxTTL,
+	} {
+		if d <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %s", name, d))
+		}
+	}
+	if c.ShareTokenTTL > c.ShareTokenMaxTTL {
+		errs = append(errs, fmt.Errorf("share_token_ttl must not exceed share_token_max_ttl"))
+	}
+	if c.RepoBackend != RepoMemory {
+		errs = append(errs, fmt.Errorf("unsupported repo_backend %q", c.RepoBackend))
+	}
+	for name, rate := range map[string]string{
+		"rate_limit_create": c.RateLimitCreate,
+		"rate_limit_open":   c.RateLimitOpen,
+		"rate_limit_draw":   c.RateLimitDraw,
+		"rate_limit_share":  c.RateLimitShare,
+	} {
+		if _, err := ratelimit.ParseRate(rate); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	if c.DeckCreateDailyQuota < 1 {
+		errs = append(errs, fmt.Errorf("deck_create_daily_quota must be positive, got %d", c.DeckCreateDailyQuota))
+	}
+	if c.VersionRetention < 0 {
+		errs = append(errs, fmt.Errorf("version_retention must not be negative, got %d", c.VersionRetention))
+	}
+	if c.IdempotencyTTL < 0 {
+		errs = append(errs, fmt.Errorf("idempotency_ttl must not be negative, got %s", c.IdempotencyTTL))
+	}
+	if c.LogFormat != "text" && c.LogFormat != "json" {
+		errs = append(errs, fmt.Errorf("unsupported log_format %q", c.LogFormat))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Addr returns the listen address.
+func (c *Config) Addr() string {
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+}
+
+// GRPCAddr returns the listen address of the gRPC server or an empty string when it is disabled.
+func (c *Config) GRPCAddr() string {
+	if c.GRPCPort == 0 {
+		return ""
+	}
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.GRPCPort))
+}
+
+// Logger creates a logger with the configured level and format.
+func (c *Config) Logger(w io.Writer) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: c.LogLevel}
+	if c.LogFormat == "json" {
+		return slog.New(slog.NewJSONHandler(w, opts))
+	}
+	return slog.New(slog.NewTextHandler(w, opts))
+}
+
+// LogValue implements slog.LogValuer, secrets are redacted.
+func (c *Config) LogValue() slog.Value {
+	fields := c.fields()
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, f := range fields {
+		v := f.value.String()
+		if f.secret && v != "" {
+			v = "[REDACTED]"
+		}
+		attrs = append(attrs, slog.String(f.key, v))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+type stringValue string
+
+func (v *stringValue) Set(s string) error { *v = stringValue(s); return nil }
+func (v *stringValue) String() string     { return string(*v) }
+
+type intValue int
+
+func (v *intValue) Set(s string) error {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*v = intValue(i)
+	return nil
+}
+func (v *intValue) String() string { return strconv.Itoa(int(*v)) }
+
+type boolValue bool
+
+func (v *boolValue) Set(s string) error {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	*v = boolValue(b)
+	return nil
+}
+func (v *boolValue) String() string { return strconv.FormatBool(bool(*v)) }
+
+type durationValue time.Duration
+
+func (v *durationValue) Set(s string) error {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*v = durationValue(d)
+	return nil
+}
+func (v *durationValue) String() string { return time.Duration(*v).String() }
+
+type dateValue time.Time
+
+func (v *dateValue) Set(s string) error {
+	if s == "" {
+		*v = dateValue{}
+		return nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return err
+	}
+	*v = dateValue(t)
+	return nil
+}
+func (v *dateValue) String() string {
+	if time.Time(*v).IsZero() {
+		return ""
+	}
+	return time.Time(*v).Format(time.DateOnly)
+}
+
+type levelValue slog.Level
+
+func (v *levelValue) Set(s string) error { return (*slog.Level)(v).UnmarshalText([]byte(s)) }
+func (v *levelValue) String() string     { return slog.Level(*v).String() }
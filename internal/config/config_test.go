@@ -0,0 +1,168 @@
+package config_test
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"toggl-card-game/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(file, []byte(`{"port": 9000, "read_timeout": "5s", "log_level": "debug", "deck_create_daily_quota": 1000000}`), 0o600)
+	if err != nil {
+		t.Fatalf("unable to write config file. Err: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		verify  func(t *testing.T, cfg *config.Config)
+		wantErr bool
+	}{
+		{
+			name: "defaults test",
+			verify: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, config.Default(), cfg)
+				assert.Equal(t, ":8080", cfg.Addr())
+			},
+		},
+		{
+			name: "config file test",
+			args: []string{"-config", file},
+			verify: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, 9000, cfg.Port)
+				assert.Equal(t, 5*time.Second, cfg.ReadTimeout)
+				assert.Equal(t, slog.LevelDebug, cfg.LogLevel)
+				assert.Equal(t, 1000000, cfg.DeckCreateDailyQuota)
+			},
+		},
+		{
+			name: "env overrides config file test",
+			env:  map[string]string{"CONFIG_FILE": file, "PORT": "9100", "SHUFFLE_BY_DEFAULT": "true"},
+			verify: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, 9100, cfg.Port)
+				assert.Equal(t, 5*time.Second, cfg.ReadTimeout)
+				assert.True(t, cfg.ShuffleByDefault)
+			},
+		},
+		{
+			name: "flags override env test",
+			args: []string{"-config", file, "-port", "9200", "-shuffle-by-default=false", "-host", "127.0.0.1"},
+			env:  map[string]string{"PORT": "9100", "SHUFFLE_BY_DEFAULT": "true"},
+			verify: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, 9200, cfg.Port)
+				assert.False(t, cfg.ShuffleByDefault)
+				assert.Equal(t, "127.0.0.1:9200", cfg.Addr())
+			},
+		},
+		{
+			name:    "invalid duration test",
+			env:     map[string]string{"READ_TIMEOUT": "soon"},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported repo backend test",
+			args:    []string{"-repo-backend", "postgres"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid rate limit test",
+			args:    []string{"-rate-limit-draw", "fast"},
+			wantErr: true,
+		},
+		{
+			name:    "share token ttl exceeds max test",
+			args:    []string{"-share-token-ttl", "2h", "-share-token-max-ttl", "1h"},
+			wantErr: true,
+		},
+		{
+			name: "legacy sunset test",
+			args: []string{"-legacy-sunset", "2028-01-31"},
+			verify: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, time.Date(2028, time.January, 31, 0, 0, 0, 0, time.UTC), cfg.LegacySunset)
+			},
+		},
+		{
+			name: "no legacy sunset test",
+			args: []string{"-legacy-sunset", ""},
+			verify: func(t *testing.T, cfg *config.Config) {
+				assert.True(t, cfg.LegacySunset.IsZero())
+			},
+		},
+		{
+			name:    "invalid legacy sunset test",
+			env:     map[string]string{"LEGACY_SUNSET": "next year"},
+			wantErr: true,
+		},
+		{
+			name: "idempotency disabled test",
+			args: []string{"-idempotency-ttl", "0s"},
+			verify: func(t *testing.T, cfg *config.Config) {
+				assert.Zero(t, cfg.IdempotencyTTL)
+			},
+		},
+		{
+			name:    "negative idempotency ttl test",
+			env:     map[string]string{"IDEMPOTENCY_TTL": "-1h"},
+			wantErr: true,
+		},
+		{
+			name: "version retention test",
+			args: []string{"-version-retention", "0"},
+			verify: func(t *testing.T, cfg *config.Config) {
+				assert.Zero(t, cfg.VersionRetention)
+			},
+		},
+		{
+			name:    "negative version retention test",
+			env:     map[string]string{"VERSION_RETENTION": "-1"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown flag test",
+			args:    []string{"-nope"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := config.Load(tt.args)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			if err != nil {
+				assert.FailNow(t, err.Error())
+				return
+			}
+
+			tt.verify(t, cfg)
+		})
+	}
+}
+
+func TestConfigLogValueRedactsSecrets(t *testing.T) {
+	cfg := config.Default()
+	cfg.APIKeys = "secret-key:alice"
+	cfg.AuthTokenSecret = "top-secret"
+
+	out := new(bytes.Buffer)
+	slog.New(slog.NewTextHandler(out, nil)).Info("config", "config", cfg)
+
+	assert.NotContains(t, out.String(), "secret-key")
+	assert.NotContains(t, out.String(), "top-secret")
+	assert.Contains(t, out.String(), "config.api_keys=[REDACTED]")
+	assert.Contains(t, out.String(), "config.port=8080")
+}
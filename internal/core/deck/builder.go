@@ -1,15 +1,27 @@
 package deck
 
 import (
+	"maps"
 	"math/rand"
+	"slices"
 
 	"github.com/google/uuid"
 )
 
 type Builder struct {
 	id       uuid.UUID
+	owner    string
 	shuffled bool
+	faceDown bool
+	faceUp   []Card
 	cards    []Card
+	onEmpty  EmptyPolicy
+	decks    int
+	// penetration and autoReshuffle place a cut card, see Penetration.
+	penetration   int
+	autoReshuffle bool
+	// from is the deck that is copied, see From.
+	from *Deck
 }
 
 func NewBuilder() *Builder {
@@ -21,11 +33,53 @@ func (b *Builder) Id(id uuid.UUID) *Builder {
 	return b
 }
 
+func (b *Builder) Owner(owner string) *Builder {
+	b.owner = owner
+	return b
+}
+
 func (b *Builder) Shuffled(shuffled bool) *Builder {
 	b.shuffled = shuffled
 	return b
 }
 
+// FaceDown lays the pile face down, so that only its owner sees the faces of its cards.
+func (b *Builder) FaceDown(faceDown bool) *Builder {
+	b.faceDown = faceDown
+	return b
+}
+
+// FaceUp turns the given cards of a face down pile face up, e.g. the trump card.
+func (b *Builder) FaceUp(cards ...Card) *Builder {
+	b.faceUp = append(b.faceUp, cards...)
+	return b
+}
+
+// OnEmpty sets the policy applied when a draw runs out of cards, EmptyNone by default.
+func (b *Builder) OnEmpty(policy EmptyPolicy) *Builder {
+	b.onEmpty = policy
+	return b
+}
+
+// Decks repeats the cards n times, e.g. a shoe of 6 full decks, the cards of a single deck by default.
+func (b *Builder) Decks(n int) *Builder {
+	b.decks = n
+	return b
+}
+
+// Penetration places a cut card that comes out after the given percentage of the cards is dealt,
+// the deck then reports that it needs a reshuffle. 0, the default, places no cut card.
+func (b *Builder) Penetration(percent int) *Builder {
+	b.penetration = percent
+	return b
+}
+
+// AutoReshuffle reshuffles the whole shoe before the first draw of the round after its cut card came out.
+func (b *Builder) AutoReshuffle(auto bool) *Builder {
+	b.autoReshuffle = auto
+	return b
+}
+
 func (b *Builder) Cards(cards []Card) *Builder {
 	b.cards = cards
 	return b
@@ -36,6 +90,21 @@ func (b *Builder) AddCard(card Card) *Builder {
 	return b
 }
 
+// From copies the current state of the deck: its owner, its remaining cards with their faces, its discard pile,
+// its policy when it runs out of cards and its cut card.
+// The copy gets a new id unless one is given and shares no memory with the deck, Shuffled(true) reshuffles its cards.
+// Draws counted per grant are not copied, the grants of the deck do not apply to the copy.
+func (b *Builder) From(d *Deck) *Builder {
+	b.from = d
+	b.owner = d.owner
+	b.faceDown = d.faceDown
+	b.cards = d.cards
+	b.onEmpty = d.onEmpty
+	b.penetration = d.penetration
+	b.autoReshuffle = d.autoReshuffle
+	return b
+}
+
 func (b *Builder) Build() (*Deck, error) {
 	deck := &Deck{}
 
@@ -50,26 +119,68 @@ func (b *Builder) Build() (*Deck, error) {
 		deck.id = id
 	}
 
-	if len(b.cards) == 0 {
+	deck.owner = b.owner
+
+	if len(b.cards) == 0 && b.from == nil {
 		deck.cards = initAllCards()
 	} else {
-		deck.cards = b.cards
+		// the cards are copied, so that shuffling or turning them does not change the given slice
+		deck.cards = slices.Clone(b.cards)
+	}
+	if b.from == nil {
+		pile := deck.cards
+		for range b.decks - 1 {
+			deck.cards = append(deck.cards, pile...)
+		}
 	}
 
 	deck.remaining = len(deck.cards)
 
+	deck.faceDown = b.faceDown
+	// the cards of a copy keep their faces
+	if b.faceDown && b.from == nil {
+		for i := range deck.cards {
+			deck.cards[i].faceDown = !slices.ContainsFunc(b.faceUp, func(c Card) bool { return c.code == deck.cards[i].code })
+		}
+	}
+
+	deck.onEmpty = b.onEmpty
+	if deck.onEmpty == "" {
+		deck.onEmpty = EmptyNone
+	}
+	// the composition is the pile before it is shuffled, a copy has the composition of the deck
+	deck.composition = slices.Clone(deck.cards)
+
+	if b.from != nil {
+		deck.shuffled = b.from.shuffled
+		deck.discards = slices.Clone(b.from.discards)
+		deck.drawn = maps.Clone(b.from.drawn)
+		deck.composition = slices.Clone(b.from.composition)
+	}
+
 	if b.shuffled {
-		shuffled := deck.cards
-		rand.Shuffle(len(shuffled), func(i, j int) {
-			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
-		})
-		deck.cards = shuffled
+		shuffleCards(deck.cards)
 		deck.shuffled = true
 	}
 
+	deck.penetration = b.penetration
+	deck.autoReshuffle = b.autoReshuffle
+	// the cut card of a copy stays where it is
+	if b.from != nil {
+		deck.cutCard, deck.dealt = b.from.cutCard, b.from.dealt
+	} else if deck.penetration > 0 {
+		deck.placeCutCard()
+	}
+
 	return deck, nil
 }
 
+func shuffleCards(cards []Card) {
+	rand.Shuffle(len(cards), func(i, j int) {
+		cards[i], cards[j] = cards[j], cards[i]
+	})
+}
+
 func initAllCards() []Card {
 	cards := make([]Card, 0, 52)
 	for _, suit := range []Suit{Spades, Diamonds, Clubs, Hearts} {
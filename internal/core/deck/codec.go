@@ -0,0 +1,141 @@
+package deck
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// Identifiers of the export format. The version is incremented when the representation changes
+// in a way that older readers cannot import.
+const (
+	ExportFormat  = "toggl-card-game/deck"
+	ExportVersion = 1
+)
+
+// Export returns the portable representation of the deck with the faces of all its cards.
+// The owner and the draws counted per grant are not exported, they belong to the environment of the deck,
+// neither is the position of the cut card of a shoe, see Import, nor the history of the deck.
+func (d *Deck) Export() DeckExport {
+	return DeckExport{
+		Format:        ExportFormat,
+		Version:       ExportVersion,
+		Id:            d.id.String(),
+		Shuffled:      d.shuffled,
+		FaceDown:      d.faceDown,
+		OnEmpty:       d.policyName(),
+		Penetration:   d.penetration,
+		AutoReshuffle: d.autoReshuffle,
+		Cards:         exportCards(d.cards),
+		Discards:      exportCards(d.discards),
+		Composition:   exportCards(d.composition),
+	}
+}
+
+// Import builds the deck of the portable representation, it is owned by the given owner.
+// A representation without an id gets a new one. Invalid representations are rejected with a validation error.
+func Import(e DeckExport, owner string) (*Deck, error) {
+	var invalid []FieldError
+	if e.Format != ExportFormat {
+		invalid = append(invalid, FieldError{Field: "format", Reason: fmt.Sprintf("must be %q", ExportFormat)})
+	}
+	if e.Version != ExportVersion {
+		invalid = append(invalid, FieldError{Field: "version", Reason: fmt.Sprintf("unsupported version %d, expected %d", e.Version, ExportVersion)})
+	}
+	onEmpty, ok := parseEmptyPolicy(e.OnEmpty)
+	if !ok {
+		invalid = append(invalid, invalidEmptyPolicy("on_empty"))
+	}
+	invalid = append(invalid, validatePenetration(0, e.Penetration, e.AutoReshuffle)...)
+	if n := len(e.Cards) + len(e.Discards); n > MaxShoeCards {
+		invalid = append(invalid, FieldError{Field: "cards", Reason: fmt.Sprintf("must hold at most %d cards together with the discards, got %d", MaxShoeCards, n)})
+	}
+	if n := len(e.Composition); n > MaxShoeCards {
+		invalid = append(invalid, FieldError{Field: "composition", Reason: fmt.Sprintf("must hold at most %d cards, got %d", MaxShoeCards, n)})
+	}
+	var id uuid.UUID
+	if e.Id != "" {
+		var err error
+		if id, err = uuid.Parse(e.Id); err != nil {
+			invalid = append(invalid, FieldError{Field: "id", Reason: "must be a UUID"})
+		}
+	}
+	cards, fields := importCards("cards", e.Cards)
+	invalid = append(invalid, fields...)
+	discards, fields := importCards("discards", e.Discards)
+	invalid = append(invalid, fields...)
+	composition, fields := importCards("composition", e.Composition)
+	invalid = append(invalid, fields...)
+	if owner == "" && (e.FaceDown || slices.ContainsFunc(slices.Concat(cards, discards, composition), func(c Card) bool { return c.faceDown })) {
+		invalid = append(invalid, faceDownWithoutOwner())
+	}
+	if len(invalid) > 0 {
+		return nil, NewValidationError(invalid...)
+	}
+
+	if id == uuid.Nil {
+		var err error
+		if id, err = uuid.NewRandom(); err != nil {
+			return nil, err
+		}
+	}
+
+	// unlike the builder, which fills empty decks, the state is taken as it is.
+	// Exports without a composition are made of the cards they still hold, discarded cards lie face up
+	// on the pile and are restocked like the pile was laid.
+	// The cut card of a shoe is placed into the remaining cards.
+	if len(composition) == 0 {
+		composition = slices.Concat(cards, turned(discards, e.FaceDown))
+	}
+	d := &Deck{
+		id:            id,
+		owner:         owner,
+		shuffled:      e.Shuffled,
+		faceDown:      e.FaceDown,
+		remaining:     len(cards),
+		cards:         cards,
+		discards:      discards,
+		onEmpty:       onEmpty,
+		composition:   composition,
+		penetration:   e.Penetration,
+		autoReshuffle: e.AutoReshuffle,
+	}
+	if d.penetration > 0 {
+		d.placeCutCard()
+	}
+	return d, nil
+}
+
+// turned returns a copy of the cards turned face down or face up.
+func turned(cards []Card, faceDown bool) []Card {
+	cards = slices.Clone(cards)
+	for i := range cards {
+		cards[i].faceDown = faceDown
+	}
+	return cards
+}
+
+func exportCards(cards []Card) []CardDto {
+	dtos := ToDtos(cards)
+	for i, c := range cards {
+		dtos[i].FaceDown = c.faceDown
+	}
+	return dtos
+}
+
+// importCards converts the exported cards of the named field, the cards are identified by their code.
+func importCards(field string, dtos []CardDto) ([]Card, []FieldError) {
+	var invalid []FieldError
+	cards := make([]Card, 0, len(dtos))
+	for i, dto := range dtos {
+		card, ok := CardsMap[dto.Code]
+		if !ok {
+			invalid = append(invalid, FieldError{Field: fmt.Sprintf("%s[%d].code", field, i), Reason: fmt.Sprintf("is not a card code: %q", dto.Code)})
+			continue
+		}
+		card.faceDown = dto.FaceDown
+		cards = append(cards, card)
+	}
+	return cards, invalid
+}
@@ -0,0 +1,191 @@
+package deck
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Operations of the commands, they name the steps of a batch.
+const (
+	OpDraw    = "draw"
+	OpDiscard = "discard"
+	OpShuffle = "shuffle"
+)
+
+// MaxBatchSteps is the maximum number of steps of a batch.
+const MaxBatchSteps = 50
+
+// Actor is the caller on whose behalf commands are applied.
+type Actor struct {
+	Caller string
+	Grant  *Grant
+}
+
+// Command is an operation on a deck. Commands mutate the deck they are applied to, so the service
+// applies them to a copy of the stored deck and stores the copy only when all of them succeed.
+type Command interface {
+	// Op returns the name of the operation, e.g. draw.
+	Op() string
+	// Apply applies the operation on behalf of the actor and returns its effect.
+	Apply(d *Deck, by Actor) (Effect, error)
+}
+
+// Effect is the outcome of a command: the cards it moved and the reshuffle it triggered, if any.
+type Effect struct {
+	Cards     []Card
+	Reshuffle *Reshuffle
+}
+
+// DrawCommand draws cards from the top of the deck. When the deck runs out of cards, it is restocked
+// according to its EmptyPolicy, at most once per draw, and the draw continues. Otherwise fewer cards are drawn.
+// Non-owners need a draw grant, its card limit applies.
+type DrawCommand struct {
+	Count int
+}
+
+func (c DrawCommand) Op() string {
+	return OpDraw
+}
+
+func (c DrawCommand) Apply(d *Deck, by Actor) (Effect, error) {
+	// grant limits apply only to non-owners drawing with a shared token
+	var grant *Grant
+	if !d.OwnedBy(by.Caller) {
+		if !by.Grant.Permits(d.id, ScopeDraw) {
+			return Effect{}, NewSvcError(nil, ErrForbidden)
+		}
+		grant = by.Grant
+		if grant.MaxCards > 0 && d.grantDraws[grant.Id]+c.Count > grant.MaxCards {
+			return Effect{}, NewSvcError(nil, ErrGrantLimit)
+		}
+	}
+
+	effect := Effect{Cards: d.take(c.Count)}
+	// a single restock keeps draws of more cards than the deck holds from restocking it over and over
+	if n := len(effect.Cards); n < c.Count {
+		if restocked := d.restock(); restocked > 0 {
+			effect.Reshuffle = &Reshuffle{Policy: string(d.onEmpty), After: n, Cards: restocked}
+			effect.Cards = append(effect.Cards, d.take(c.Count-n)...)
+		}
+	}
+
+	if grant != nil {
+		if d.grantDraws == nil {
+			d.grantDraws = make(map[string]int)
+		}
+		d.grantDraws[grant.Id] += len(effect.Cards)
+	}
+	return effect, nil
+}
+
+// DiscardCommand puts cards that were drawn from the deck onto its discard pile.
+// Non-owners need a draw grant.
+type DiscardCommand struct {
+	Cards []Card
+}
+
+func (c DiscardCommand) Op() string {
+	return OpDiscard
+}
+
+func (c DiscardCommand) Apply(d *Deck, by Actor) (Effect, error) {
+	if !d.OwnedBy(by.Caller) && !by.Grant.Permits(d.id, ScopeDraw) {
+		return Effect{}, NewSvcError(nil, ErrForbidden)
+	}
+
+	for _, card := range c.Cards {
+		// a shoe of several decks holds copies of the card, one of them may be drawn while others remain
+		if d.drawn[card.code] == 0 {
+			sameCode := func(other Card) bool { return other.code == card.code }
+			switch {
+			case !slices.ContainsFunc(d.composition, sameCode):
+				return Effect{}, NewSvcError(nil, ErrNotDiscardable).WithDetail("card %s is not a card of the deck", card.code)
+			case slices.ContainsFunc(d.discards, sameCode):
+				return Effect{}, NewSvcError(nil, ErrNotDiscardable).WithDetail("card %s is already discarded", card.code)
+			default:
+				return Effect{}, NewSvcError(nil, ErrNotDiscardable).WithDetail("card %s was not drawn from the deck", card.code)
+			}
+		}
+		d.drawn[card.code]--
+		// discarded cards lie face up
+		card.faceDown = false
+		d.discards = append(d.discards, card)
+	}
+	return Effect{Cards: slices.Clone(c.Cards)}, nil
+}
+
+// ShuffleCommand shuffles the remaining cards of the deck, only the owner is allowed to shuffle it.
+// A shoe whose cut card came out is reshuffled as a whole, see Deck.ReshuffleNeeded.
+type ShuffleCommand struct{}
+
+func (c ShuffleCommand) Op() string {
+	return OpShuffle
+}
+
+func (c ShuffleCommand) Apply(d *Deck, by Actor) (Effect, error) {
+	if by.Grant != nil || !d.OwnedBy(by.Caller) {
+		return Effect{}, NewSvcError(nil, ErrForbidden)
+	}
+
+	if d.ReshuffleNeeded() {
+		return Effect{Reshuffle: d.reshuffleShoe()}, nil
+	}
+	shuffleCards(d.cards)
+	d.shuffled = true
+	return Effect{}, nil
+}
+
+// toCommand validates a step of a batch and converts it into a command.
+func toCommand(step BatchStep) (Command, []FieldError) {
+	var invalid []FieldError
+	switch step.Op {
+	case OpDraw:
+		if step.Count < 1 {
+			invalid = append(invalid, FieldError{Field: "count", Reason: "must be positive"})
+		}
+		return DrawCommand{Count: step.Count}, invalid
+	case OpDiscard:
+		if len(step.Cards) == 0 {
+			invalid = append(invalid, FieldError{Field: "cards", Reason: "must not be empty"})
+		}
+		for i, code := range step.Cards {
+			if _, ok := CardsMap[code]; !ok {
+				invalid = append(invalid, FieldError{Field: fmt.Sprintf("cards[%d]", i), Reason: fmt.Sprintf("is not a card code: %q", code)})
+			}
+		}
+		return DiscardCommand{Cards: ToCards(step.Cards)}, invalid
+	case OpShuffle:
+		return ShuffleCommand{}, nil
+	}
+	return nil, []FieldError{{Field: "op", Reason: fmt.Sprintf("must be one of %s, %s or %s", OpDraw, OpDiscard, OpShuffle)}}
+}
+
+// stepError is the error of the command at the given index of the applied commands.
+type stepError struct {
+	index int
+	op    string
+	err   error
+}
+
+func (e stepError) Error() string {
+	return fmt.Sprintf("step %d (%s): %s", e.index, e.op, e.err)
+}
+
+func (e stepError) Unwrap() error {
+	return e.err
+}
+
+// inBatch returns the service error of the failed step with a detail that names the step.
+func (e stepError) inBatch() SvcError {
+	var svcErr SvcError
+	if !errors.As(e.err, &svcErr) {
+		svcErr = NewSvcError(e.err, ErrInternal)
+	}
+
+	detail := svcErr.Detail
+	if detail == "" {
+		detail = svcErr.Catalogued().Title
+	}
+	return svcErr.WithDetail("step %d (%s) failed, no step was applied: %s", e.index, e.op, detail)
+}
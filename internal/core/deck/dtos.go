@@ -1,11 +1,9 @@
 package deck
 
+import "toggl-card-game/api"
+
 // CardDto represents a data transfer object for a card.
-type CardDto struct {
-	Value string `json:"value"`
-	Suit  string `json:"suit"`
-	Code  string `json:"code"`
-}
+type CardDto = api.Card
 
 // ToDto converts a Card entity to a CardDto.
 func (c Card) ToDto() CardDto {
@@ -38,35 +36,184 @@ func ToCards(codes []string) []Card {
 type CreateRequest struct {
 	Shuffled bool
 	Cards    []string
+	Owner    string
+	// FaceDown lays the pile face down, FaceUp are the codes of its cards that are turned face up nevertheless.
+	FaceDown bool
+	FaceUp   []string
+	// OnEmpty names the EmptyPolicy of the deck, EmptyNone when empty.
+	OnEmpty string
+	// Decks is the number of decks of a shoe, Penetration and AutoReshuffle place its cut card, see Builder.Penetration.
+	Decks         int
+	Penetration   int
+	AutoReshuffle bool
 }
 
 // CreateResponse represents a response for creating a deck.
-type CreateResponse struct {
-	DeckId    string `json:"deck_id"`
-	Shuffled  bool   `json:"shuffled"`
-	Remaining int    `json:"remaining"`
+type CreateResponse = api.CreateResponse
+
+// CloneRequest represents a request to clone a deck, Reshuffle shuffles the remaining cards of the clone.
+type CloneRequest struct {
+	DeckId    string
+	Caller    string
+	Reshuffle bool
+}
+
+// DeckExport is the portable representation of a deck, see Deck.Export and Import.
+type DeckExport = api.DeckExport
+
+// ExportRequest represents a request to export a deck.
+type ExportRequest struct {
+	DeckId string
+	Caller string
+}
+
+// ImportRequest represents a request to import an exported deck, the imported deck is owned by the caller.
+type ImportRequest struct {
+	Caller string
+	Deck   DeckExport
 }
 
 // OpenRequest represents a request to open a deck.
 type OpenRequest struct {
 	DeckId string
+	Caller string
+	Grant  *Grant
+	// Offset and Limit select a page of the remaining cards, a zero limit selects all cards from the offset.
+	Offset int
+	Limit  int
+	// Fields selects the card fields of the response by their JSON name, all fields when empty.
+	Fields []string
+	// Summary leaves out the cards and counts the remaining cards per suit instead.
+	Summary bool
+	// Version selects the deck as it was after the given number of operations, the current deck when nil.
+	Version *int
 }
 
 // OpenResponse represents a response for opening a deck.
-type OpenResponse struct {
-	DeckId    string    `json:"deck_id"`
-	Shuffled  bool      `json:"shuffled"`
-	Remaining int       `json:"remaining"`
-	Cards     []CardDto `json:"cards"`
+type OpenResponse = api.OpenResponse
+
+// Page describes a page of the remaining cards of a deck.
+type Page = api.Page
+
+// SuitCount is the number of remaining cards of a suit.
+type SuitCount = api.SuitCount
+
+// CardFields are the fields of a card that can be selected when opening a deck.
+var CardFields = []string{"value", "suit", "code"}
+
+// selectFields keeps only the given fields of the cards.
+func selectFields(cards []CardDto, fields []string) []CardDto {
+	if len(fields) == 0 {
+		return cards
+	}
+	selected := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		selected[f] = true
+	}
+	for i := range cards {
+		if !selected["value"] {
+			cards[i].Value = ""
+		}
+		if !selected["suit"] {
+			cards[i].Suit = ""
+		}
+		if !selected["code"] {
+			cards[i].Code = ""
+		}
+	}
+	return cards
+}
+
+// countSuits counts the cards per suit, suits without cards and backs of cards are left out.
+func countSuits(cards []CardDto) []SuitCount {
+	counts := make([]SuitCount, 0, 4)
+	for _, suit := range []Suit{Spades, Diamonds, Clubs, Hearts} {
+		n := 0
+		for _, c := range cards {
+			if c.Suit == string(suit) {
+				n++
+			}
+		}
+		if n > 0 {
+			counts = append(counts, SuitCount{Suit: string(suit), Count: n})
+		}
+	}
+	return counts
 }
 
 // DrawRequest represents a request to draw cards from a deck.
 type DrawRequest struct {
 	DeckId string `json:"deck_id"`
 	Count  int    `json:"count"`
+	Caller string `json:"-"`
+	Grant  *Grant `json:"-"`
 }
 
 // DrawResponse represents a response for drawing cards from a deck.
-type DrawResponse struct {
-	Cards []CardDto `json:"cards"`
+type DrawResponse = api.DrawResponse
+
+// Reshuffle describes a reshuffle of a deck that ran out of cards during a draw.
+type Reshuffle = api.Reshuffle
+
+// Penetration describes the cut card of a shoe.
+type Penetration = api.Penetration
+
+// ShuffleRequest represents a request to shuffle the remaining cards of a deck.
+type ShuffleRequest struct {
+	DeckId string `json:"deck_id"`
+	Caller string `json:"-"`
+}
+
+// ShuffleResponse represents a response for shuffling a deck.
+type ShuffleResponse = api.ShuffleResponse
+
+// ShareRequest represents a request to share a deck with other clients.
+type ShareRequest struct {
+	DeckId     string `json:"-"`
+	Caller     string `json:"-"`
+	Scope      Scope  `json:"scope"`
+	MaxCards   int    `json:"max_cards"`
+	TTLSeconds int    `json:"ttl_seconds"`
 }
+
+// ShareResponse represents a response for sharing a deck.
+type ShareResponse = api.ShareResponse
+
+// SnapshotRequest represents a request to take a snapshot of a deck or to restore the deck from it.
+type SnapshotRequest struct {
+	DeckId string
+	Caller string
+	Name   string
+}
+
+// SnapshotDto represents a data transfer object for a snapshot.
+type SnapshotDto = api.Snapshot
+
+// ListSnapshotsRequest represents a request to list the snapshots of a deck.
+type ListSnapshotsRequest struct {
+	DeckId string
+	Caller string
+}
+
+// SnapshotList represents a response for listing the snapshots of a deck.
+type SnapshotList = api.SnapshotList
+
+// RestoreResponse represents a response for restoring a deck from a snapshot.
+type RestoreResponse = api.RestoreResponse
+
+// BatchRequest represents a request to apply steps to a deck atomically.
+type BatchRequest struct {
+	DeckId string      `json:"-"`
+	Caller string      `json:"-"`
+	Grant  *Grant      `json:"-"`
+	Steps  []BatchStep `json:"steps"`
+}
+
+// BatchStep is a step of a batch, see Command.
+type BatchStep = api.BatchStep
+
+// StepResult is the result of a step of a batch.
+type StepResult = api.StepResult
+
+// BatchResponse represents a response for applying a batch to a deck.
+type BatchResponse = api.BatchResponse
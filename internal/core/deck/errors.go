@@ -1,17 +1,82 @@
 package deck
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"toggl-card-game/api"
+)
+
+// Kind classifies errors independently of the transport, e.g. it maps to an http status or a gRPC code.
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindInvalid
+	KindNotFound
+	KindForbidden
+	KindConflict
+)
+
+// Error is an entry of the error catalogue. The code is a stable machine-readable
+// identifier that is part of the public api, the title is a short summary that does not change
+// between occurrences.
+type Error struct {
+	Code  string
+	Kind  Kind
+	Title string
+}
 
+// Error is implementation of error interface.
+func (e *Error) Error() string {
+	return e.Title
+}
+
+// The error catalogue, service errors wrap one of these as their application error.
 var (
-	ErrCreateDeck   = errors.New("unable to create deck")
-	ErrDeckNotFound = errors.New("unable to find deck")
-	ErrUpdateDeck   = errors.New("unable to update deck")
+	ErrInternal         = &Error{Code: "internal", Kind: KindInternal, Title: "internal error"}
+	ErrInvalidRequest   = &Error{Code: "invalid-request", Kind: KindInvalid, Title: "request is invalid"}
+	ErrCreateDeck       = &Error{Code: "create-deck-failed", Kind: KindInternal, Title: "unable to create deck"}
+	ErrDeckNotFound     = &Error{Code: "deck-not-found", Kind: KindNotFound, Title: "unable to find deck"}
+	ErrUpdateDeck       = &Error{Code: "update-deck-failed", Kind: KindInternal, Title: "unable to update deck"}
+	ErrForbidden        = &Error{Code: "forbidden", Kind: KindForbidden, Title: "access to deck is forbidden"}
+	ErrShareDeck        = &Error{Code: "share-deck-failed", Kind: KindInternal, Title: "unable to share deck"}
+	ErrGrantLimit       = &Error{Code: "grant-limit-exceeded", Kind: KindForbidden, Title: "draw exceeds the shared card limit"}
+	ErrNotDiscardable   = &Error{Code: "card-not-discardable", Kind: KindConflict, Title: "card cannot be discarded"}
+	ErrDeckExists       = &Error{Code: "deck-exists", Kind: KindConflict, Title: "deck already exists"}
+	ErrVersionNotFound  = &Error{Code: "version-not-found", Kind: KindNotFound, Title: "unable to find deck version"}
+	ErrSnapshotNotFound = &Error{Code: "snapshot-not-found", Kind: KindNotFound, Title: "unable to find snapshot"}
+	ErrSnapshotDeck     = &Error{Code: "snapshot-deck-failed", Kind: KindInternal, Title: "unable to take snapshot of deck"}
 )
 
+// Catalogue lists all entries of the error catalogue.
+var Catalogue = []*Error{
+	ErrInternal,
+	ErrInvalidRequest,
+	ErrCreateDeck,
+	ErrDeckNotFound,
+	ErrUpdateDeck,
+	ErrForbidden,
+	ErrShareDeck,
+	ErrGrantLimit,
+	ErrNotDiscardable,
+	ErrDeckExists,
+	ErrVersionNotFound,
+	ErrSnapshotNotFound,
+	ErrSnapshotDeck,
+}
+
+// FieldError describes why a field of a request is invalid.
+type FieldError = api.FieldError
+
 // SvcError is a custom error type that holds both internal and application errors.
+// The internal error is meant for logs only, clients see the application error,
+// the detail and the field errors.
 type SvcError struct {
 	InternalErr error
 	AppErr      error
+	Detail      string
+	Fields      []FieldError
 }
 
 func NewSvcError(internalErr, appErr error) SvcError {
@@ -21,6 +86,34 @@ func NewSvcError(internalErr, appErr error) SvcError {
 	}
 }
 
+// NewValidationError creates a service error for a request with invalid fields.
+func NewValidationError(fields ...FieldError) SvcError {
+	reasons := make([]string, len(fields))
+	for i, f := range fields {
+		reasons[i] = f.Field + " " + f.Reason
+	}
+	return SvcError{
+		AppErr: ErrInvalidRequest,
+		Detail: strings.Join(reasons, ", "),
+		Fields: fields,
+	}
+}
+
+// WithDetail sets an explanation of this occurrence of the error that is safe to show to clients.
+func (x SvcError) WithDetail(format string, args ...any) SvcError {
+	x.Detail = fmt.Sprintf(format, args...)
+	return x
+}
+
+// Catalogued returns the catalogue entry of the application error or ErrInternal when there is none.
+func (x SvcError) Catalogued() *Error {
+	var e *Error
+	if errors.As(x.AppErr, &e) {
+		return e
+	}
+	return ErrInternal
+}
+
 // Error is implementation of error interface.
 func (x SvcError) Error() string {
 	if x.InternalErr == nil && x.AppErr == nil {
@@ -28,3 +121,8 @@ func (x SvcError) Error() string {
 	}
 	return errors.Join(x.InternalErr, x.AppErr).Error()
 }
+
+// Unwrap returns the internal and application errors, so errors.Is matches catalogue entries.
+func (x SvcError) Unwrap() []error {
+	return []error{x.InternalErr, x.AppErr}
+}
@@ -0,0 +1,39 @@
+package deck
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scope represents a permission granted on a shared deck.
+type Scope string
+
+const (
+	ScopeRead Scope = "read"
+	ScopeDraw Scope = "draw"
+)
+
+const (
+	DefaultGrantTTL = time.Hour
+	MaxGrantTTL     = 7 * 24 * time.Hour
+)
+
+// Grant represents a scoped permission on a single deck, shared by the deck owner.
+type Grant struct {
+	Id        string
+	DeckId    string
+	Scope     Scope
+	MaxCards  int
+	ExpiresAt time.Time
+}
+
+// Permits returns true if the grant allows the given scope on the given deck.
+func (g *Grant) Permits(id uuid.UUID, scope Scope) bool {
+	return g != nil && g.DeckId == id.String() && g.Scope == scope
+}
+
+// GrantSigner is the port that turns a grant into a token that can be handed to other clients.
+type GrantSigner interface {
+	SignGrant(grant Grant) (string, error)
+}
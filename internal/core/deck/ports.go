@@ -7,10 +7,19 @@ import (
 )
 
 // Repo is the deck port that defines methods that any repository adapter must implement.
+// Stored decks are never changed in place, the service updates a deck by storing a changed copy
+// whose version is incremented by one.
 type Repo interface {
 	Create(ctx context.Context, deck *Deck) (*Deck, error)
 	Get(ctx context.Context, id uuid.UUID) (*Deck, error)
 	Update(ctx context.Context, deck *Deck) (*Deck, error)
+	// GetVersion returns the deck as it was stored with the given version.
+	GetVersion(ctx context.Context, id uuid.UUID, version int) (*Deck, error)
+	// SaveSnapshot stores the snapshot of its deck, it replaces a snapshot of the deck with the same name.
+	SaveSnapshot(ctx context.Context, snapshot Snapshot) error
+	GetSnapshot(ctx context.Context, id uuid.UUID, name string) (Snapshot, error)
+	// ListSnapshots returns the snapshots of the deck ordered by name.
+	ListSnapshots(ctx context.Context, id uuid.UUID) ([]Snapshot, error)
 }
 
 // TargetFunc is a generic function type that represents any service function
@@ -0,0 +1,104 @@
+package deck
+
+import (
+	"fmt"
+	"slices"
+)
+
+// EmptyPolicy decides what happens when a draw runs out of cards, see DrawCommand.
+type EmptyPolicy string
+
+const (
+	// EmptyNone stops the draw short, it is the policy of decks created without one.
+	EmptyNone EmptyPolicy = "none"
+	// EmptyReshuffleDiscards shuffles the discard pile back into the deck.
+	EmptyReshuffleDiscards EmptyPolicy = "reshuffle_discards"
+	// EmptyReshuffleAll collects all cards the deck was created with, drawn and discarded ones, and shuffles them.
+	EmptyReshuffleAll EmptyPolicy = "reshuffle_all"
+	// EmptyRefill adds the shuffled cards of a fresh deck, drawn and discarded cards stay where they are.
+	EmptyRefill EmptyPolicy = "refill"
+)
+
+// EmptyPolicies lists the policies in the order they are documented.
+var EmptyPolicies = []EmptyPolicy{EmptyNone, EmptyReshuffleDiscards, EmptyReshuffleAll, EmptyRefill}
+
+// parseEmptyPolicy returns the named policy, EmptyNone when the name is empty.
+func parseEmptyPolicy(name string) (EmptyPolicy, bool) {
+	if name == "" {
+		return EmptyNone, true
+	}
+	policy := EmptyPolicy(name)
+	return policy, slices.Contains(EmptyPolicies, policy)
+}
+
+// invalidEmptyPolicy returns the field error of an unknown policy.
+func invalidEmptyPolicy(field string) FieldError {
+	return FieldError{Field: field, Reason: fmt.Sprintf("must be one of %s, %s, %s or %s", EmptyPolicies[0], EmptyPolicies[1], EmptyPolicies[2], EmptyPolicies[3])}
+}
+
+// policyName returns the name of the policy of the deck, it is empty for EmptyNone so that it is omitted
+// from the representations of decks without a policy.
+func (d *Deck) policyName() string {
+	if d.onEmpty == EmptyNone {
+		return ""
+	}
+	return string(d.onEmpty)
+}
+
+// restock puts cards into the empty deck according to its policy and shuffles them.
+// It returns the number of cards put into the deck, 0 when the policy does not restock or there is nothing to put.
+func (d *Deck) restock() int {
+	var cards []Card
+	switch d.onEmpty {
+	case EmptyReshuffleDiscards:
+		cards, d.discards = d.discards, nil
+	case EmptyReshuffleAll:
+		cards, d.discards, d.drawn = slices.Clone(d.composition), nil, nil
+	case EmptyRefill:
+		cards = slices.Clone(d.composition)
+	}
+	if len(cards) == 0 {
+		return 0
+	}
+
+	d.stock(cards)
+	return len(cards)
+}
+
+// stock shuffles the cards and puts them below the remaining cards, the cut card of a shoe is placed anew.
+// The cards lie like in the composition of the deck, e.g. the face up trump of a face down pile stays face up,
+// also discarded cards, which lie face up on the discard pile.
+func (d *Deck) stock(cards []Card) {
+	faceDown := make(map[string]bool, len(d.composition))
+	for _, c := range d.composition {
+		faceDown[c.code] = c.faceDown
+	}
+	for i := range cards {
+		cards[i].faceDown = faceDown[cards[i].code]
+	}
+	shuffleCards(cards)
+	d.cards = append(d.cards, cards...)
+	d.remaining += len(cards)
+	d.shuffled = true
+	if d.penetration > 0 {
+		d.placeCutCard()
+	}
+}
+
+// take removes the top n cards of the deck, fewer when the deck runs out of cards, and returns them.
+func (d *Deck) take(n int) []Card {
+	n = min(n, len(d.cards))
+	cards := slices.Clone(d.cards[:n])
+	d.cards = d.cards[n:]
+	if n > 0 && d.drawn == nil {
+		d.drawn = make(map[string]int)
+	}
+	for _, c := range cards {
+		d.drawn[c.code]++
+	}
+	d.remaining -= n
+	if d.penetration > 0 {
+		d.dealt += n
+	}
+	return cards
+}
@@ -2,32 +2,176 @@ package deck
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 // Service holds the deck use cases - business logic.
 type Service struct {
-	repo Repo
-	lock sync.Mutex
+	repo        Repo
+	signer      GrantSigner
+	grantTTL    time.Duration
+	grantMaxTTL time.Duration
+	lock        sync.Mutex
 }
 
 // NewService creates a new deck service.
 func NewService(repo Repo) *Service {
 	return &Service{
-		repo: repo,
-		lock: sync.Mutex{},
+		repo:        repo,
+		grantTTL:    DefaultGrantTTL,
+		grantMaxTTL: MaxGrantTTL,
+		lock:        sync.Mutex{},
 	}
 }
 
+// WithGrantSigner sets the signer used to issue tokens for shared decks.
+func (s *Service) WithGrantSigner(signer GrantSigner) *Service {
+	s.signer = signer
+	return s
+}
+
+// WithGrantTTL sets the default and maximum lifetime of tokens for shared decks.
+func (s *Service) WithGrantTTL(ttl, maxTTL time.Duration) *Service {
+	s.grantTTL = ttl
+	s.grantMaxTTL = maxTTL
+	return s
+}
+
 // CreateDeck creates a new deck of cards.
 func (s *Service) CreateDeck(ctx context.Context, req CreateRequest) (*CreateResponse, error) {
-	deck, err := NewBuilder().Cards(ToCards(req.Cards)).Shuffled(req.Shuffled).Build()
+	var invalid []FieldError
+	for i, code := range req.Cards {
+		if _, ok := CardsMap[code]; !ok {
+			invalid = append(invalid, FieldError{Field: fmt.Sprintf("cards[%d]", i), Reason: fmt.Sprintf("is not a card code: %q", code)})
+		}
+	}
+	for i, code := range req.FaceUp {
+		if _, ok := CardsMap[code]; !ok || (len(req.Cards) > 0 && !slices.Contains(req.Cards, code)) {
+			invalid = append(invalid, FieldError{Field: fmt.Sprintf("face_up[%d]", i), Reason: fmt.Sprintf("is not a card of the deck: %q", code)})
+		}
+	}
+	if req.FaceDown && req.Owner == "" {
+		invalid = append(invalid, faceDownWithoutOwner())
+	}
+	onEmpty, ok := parseEmptyPolicy(req.OnEmpty)
+	if !ok {
+		invalid = append(invalid, invalidEmptyPolicy("on_empty"))
+	}
+	invalid = append(invalid, validatePenetration(req.Decks, req.Penetration, req.AutoReshuffle)...)
+	if len(invalid) > 0 {
+		return nil, NewValidationError(invalid...)
+	}
+
+	deck, err := NewBuilder().
+		Cards(ToCards(req.Cards)).
+		Decks(req.Decks).
+		Shuffled(req.Shuffled).
+		OnEmpty(onEmpty).
+		Penetration(req.Penetration).
+		AutoReshuffle(req.AutoReshuffle).
+		FaceDown(req.FaceDown).
+		FaceUp(ToCards(req.FaceUp)...).
+		Owner(req.Owner).
+		Build()
+	if err != nil {
+		return nil, NewSvcError(err, ErrCreateDeck)
+	}
+
+	deck, err = s.repo.Create(ctx, deck)
+	if err != nil {
+		return nil, NewSvcError(err, ErrCreateDeck)
+	}
+
+	return &CreateResponse{
+		DeckId:    deck.id.String(),
+		Shuffled:  deck.shuffled,
+		Remaining: deck.remaining,
+	}, nil
+}
+
+// CloneDeck copies the current state of the deck into a new deck, e.g. for what-if simulations or replays.
+// The clone is independent of the deck, optionally its remaining cards are reshuffled.
+// Only the owner of the deck is allowed to clone it, the clone has the same owner.
+func (s *Service) CloneDeck(ctx context.Context, req CloneRequest) (*CreateResponse, error) {
+	id, err := parseDeckId(req.DeckId)
 	if err != nil {
 		return nil, err
 	}
 
+	deck, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, deckNotFound(err, id)
+	}
+
+	if !deck.OwnedBy(req.Caller) {
+		return nil, NewSvcError(nil, ErrForbidden)
+	}
+
+	clone, err := NewBuilder().From(deck).Shuffled(req.Reshuffle).Build()
+	if err != nil {
+		return nil, NewSvcError(err, ErrCreateDeck)
+	}
+
+	clone, err = s.repo.Create(ctx, clone)
+	if err != nil {
+		return nil, NewSvcError(err, ErrCreateDeck)
+	}
+
+	return &CreateResponse{
+		DeckId:    clone.id.String(),
+		Shuffled:  clone.shuffled,
+		Remaining: clone.remaining,
+	}, nil
+}
+
+// ExportDeck returns the portable representation of the deck, see Deck.Export.
+// Only the owner of the deck is allowed to export it, since the export shows the faces of all cards.
+func (s *Service) ExportDeck(ctx context.Context, req ExportRequest) (*DeckExport, error) {
+	id, err := parseDeckId(req.DeckId)
+	if err != nil {
+		return nil, err
+	}
+
+	deck, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, deckNotFound(err, id)
+	}
+
+	if !deck.OwnedBy(req.Caller) {
+		return nil, NewSvcError(nil, ErrForbidden)
+	}
+
+	export := deck.Export()
+	return &export, nil
+}
+
+// ImportDeck creates the deck of an exported representation, owned by the caller.
+// The deck keeps its exported id, which must not be taken by another deck.
+func (s *Service) ImportDeck(ctx context.Context, req ImportRequest) (*CreateResponse, error) {
+	deck, err := Import(req.Deck, req.Caller)
+	if err != nil {
+		var svcErr SvcError
+		if errors.As(err, &svcErr) {
+			return nil, svcErr
+		}
+		return nil, NewSvcError(err, ErrCreateDeck)
+	}
+
+	// the lock keeps a concurrent import of the same id from overwriting the deck
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, err = s.repo.Get(ctx, deck.id); err == nil {
+		return nil, NewSvcError(nil, ErrDeckExists).WithDetail("deck with ID [%s] already exists", deck.id)
+	}
+
 	deck, err = s.repo.Create(ctx, deck)
 	if err != nil {
 		return nil, NewSvcError(err, ErrCreateDeck)
@@ -40,64 +184,383 @@ func (s *Service) CreateDeck(ctx context.Context, req CreateRequest) (*CreateRes
 	}, nil
 }
 
-// OpenDeck opens a deck of cards.
+// OpenDeck opens a deck of cards, optionally a page of its cards, selected card fields or a summary.
+// The faces of face down cards are redacted unless the caller owns the deck, see Role.
+// A version opens the deck as it was after that number of operations, e.g. version 0 is the created deck.
 func (s *Service) OpenDeck(ctx context.Context, req OpenRequest) (*OpenResponse, error) {
-	id, err := uuid.Parse(req.DeckId)
+	id, err := parseDeckId(req.DeckId)
 	if err != nil {
 		return nil, err
 	}
 
+	var invalid []FieldError
+	if req.Offset < 0 {
+		invalid = append(invalid, FieldError{Field: "offset", Reason: "must not be negative"})
+	}
+	if req.Limit < 0 {
+		invalid = append(invalid, FieldError{Field: "limit", Reason: "must not be negative"})
+	}
+	if req.Version != nil && *req.Version < 0 {
+		invalid = append(invalid, FieldError{Field: "version", Reason: "must not be negative"})
+	}
+	for _, f := range req.Fields {
+		if !slices.Contains(CardFields, f) {
+			invalid = append(invalid, FieldError{Field: "fields", Reason: fmt.Sprintf("unknown card field %q, expected one of %s", f, strings.Join(CardFields, ", "))})
+		}
+	}
+	if len(invalid) > 0 {
+		return nil, NewValidationError(invalid...)
+	}
+
 	deck, err := s.repo.Get(ctx, id)
 	if err != nil {
-		return nil, NewSvcError(err, ErrDeckNotFound)
+		return nil, deckNotFound(err, id)
+	}
+
+	role, ok := deck.roleOf(req.Caller, req.Grant)
+	if !ok {
+		return nil, NewSvcError(nil, ErrForbidden)
 	}
 
-	cards := make([]CardDto, 0, len(deck.cards))
-	for _, c := range deck.cards {
-		cards = append(cards, CardDto{
-			Value: string(c.rank),
-			Suit:  string(c.suit),
-			Code:  c.code,
-		})
+	if req.Version != nil && *req.Version != deck.version {
+		if *req.Version > deck.version {
+			return nil, NewSvcError(nil, ErrVersionNotFound).WithDetail("deck %s is at version %d", id, deck.version)
+		}
+		if deck, err = s.repo.GetVersion(ctx, id, *req.Version); err != nil {
+			return nil, NewSvcError(err, ErrVersionNotFound).WithDetail("version %d of deck %s is not stored", *req.Version, id)
+		}
 	}
 
-	return &OpenResponse{
+	cards, hidden := view(deck.cards, role)
+	res := &OpenResponse{
+		DeckId:      deck.id.String(),
+		Shuffled:    deck.shuffled,
+		Remaining:   deck.remaining,
+		Version:     deck.version,
+		OnEmpty:     deck.policyName(),
+		Penetration: deck.penetrationDto(),
+		FaceDown:    deck.faceDown,
+		Hidden:      hidden,
+	}
+	if req.Summary {
+		res.Suits = countSuits(cards)
+		return res, nil
+	}
+
+	if req.Offset > 0 || req.Limit > 0 {
+		res.Page = &Page{Offset: req.Offset, Limit: req.Limit, Total: len(cards)}
+		start, end := min(req.Offset, len(cards)), len(cards)
+		if req.Limit > 0 && start+req.Limit < end {
+			end = start + req.Limit
+			res.Page.NextOffset = end
+		}
+		cards = cards[start:end]
+	}
+	res.Cards = selectFields(cards, req.Fields)
+
+	return res, nil
+}
+
+// DrawCards draws cards from the deck. When the deck runs out of cards mid-draw, it is restocked
+// according to its EmptyPolicy and the response describes the reshuffle. A draw from a shoe whose cut card
+// came out in an earlier round reshuffles the shoe first when it reshuffles automatically.
+func (s *Service) DrawCards(ctx context.Context, req DrawRequest) (*DrawResponse, error) {
+	if req.Count < 1 {
+		return nil, NewValidationError(FieldError{Field: "count", Reason: "must be positive"})
+	}
+
+	deckID, err := parseDeckId(req.DeckId)
+	if err != nil {
+		return nil, err
+	}
+
+	deck, results, err := s.execute(ctx, deckID, Actor{Caller: req.Caller, Grant: req.Grant}, DrawCommand{Count: req.Count})
+	if err != nil {
+		return nil, err
+	}
+
+	return &DrawResponse{Cards: results[0].Cards, Reshuffled: results[0].Reshuffled, Penetration: deck.penetrationDto()}, nil
+}
+
+// ShuffleDeck shuffles the remaining cards of the deck. Only the owner of the deck is allowed to shuffle it.
+func (s *Service) ShuffleDeck(ctx context.Context, req ShuffleRequest) (*ShuffleResponse, error) {
+	id, err := parseDeckId(req.DeckId)
+	if err != nil {
+		return nil, err
+	}
+
+	deck, _, err := s.execute(ctx, id, Actor{Caller: req.Caller}, ShuffleCommand{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ShuffleResponse{
 		DeckId:    deck.id.String(),
 		Shuffled:  deck.shuffled,
 		Remaining: deck.remaining,
-		Cards:     cards,
 	}, nil
 }
 
-// DrawCards draws cards from the deck.
-func (s *Service) DrawCards(ctx context.Context, req DrawRequest) (*DrawResponse, error) {
+// Batch applies the steps to the deck atomically: either all of them are applied or, when a step fails,
+// none of them. Steps are applied in order, e.g. draw 2, discard 1, shuffle.
+func (s *Service) Batch(ctx context.Context, req BatchRequest) (*BatchResponse, error) {
+	id, err := parseDeckId(req.DeckId)
+	if err != nil {
+		return nil, err
+	}
+
+	var invalid []FieldError
+	if len(req.Steps) == 0 || len(req.Steps) > MaxBatchSteps {
+		invalid = append(invalid, FieldError{Field: "steps", Reason: fmt.Sprintf("must have 1 to %d steps", MaxBatchSteps)})
+	}
+	cmds := make([]Command, len(req.Steps))
+	for i, step := range req.Steps {
+		cmd, fields := toCommand(step)
+		for _, f := range fields {
+			invalid = append(invalid, FieldError{Field: fmt.Sprintf("steps[%d].%s", i, f.Field), Reason: f.Reason})
+		}
+		cmds[i] = cmd
+	}
+	if len(invalid) > 0 {
+		return nil, NewValidationError(invalid...)
+	}
+
+	deck, results, err := s.execute(ctx, id, Actor{Caller: req.Caller, Grant: req.Grant}, cmds...)
+	var step stepError
+	if errors.As(err, &step) {
+		return nil, step.inBatch()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &BatchResponse{
+		DeckId:    deck.id.String(),
+		Remaining: deck.remaining,
+		Results:   results,
+	}, nil
+}
+
+// TakeSnapshot stores the current state of the deck under the given name, it replaces a snapshot
+// of the same name. Only the owner of the deck is allowed to take snapshots.
+func (s *Service) TakeSnapshot(ctx context.Context, req SnapshotRequest) (*SnapshotDto, error) {
+	id, err := parseDeckId(req.DeckId)
+	if err != nil {
+		return nil, err
+	}
+	if err = validateSnapshotName(req.Name); err != nil {
+		return nil, err
+	}
+
+	deck, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, deckNotFound(err, id)
+	}
+
+	if !deck.OwnedBy(req.Caller) {
+		return nil, NewSvcError(nil, ErrForbidden)
+	}
+
+	snapshot := Snapshot{Name: req.Name, TakenAt: time.Now().UTC().Truncate(time.Second), Deck: deck}
+	if err = s.repo.SaveSnapshot(ctx, snapshot); err != nil {
+		return nil, NewSvcError(err, ErrSnapshotDeck)
+	}
+
+	dto := snapshot.ToDto()
+	return &dto, nil
+}
+
+// ListSnapshots returns the snapshots of the deck. Only the owner of the deck is allowed to list them.
+func (s *Service) ListSnapshots(ctx context.Context, req ListSnapshotsRequest) (*SnapshotList, error) {
+	id, err := parseDeckId(req.DeckId)
+	if err != nil {
+		return nil, err
+	}
+
+	deck, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, deckNotFound(err, id)
+	}
+
+	if !deck.OwnedBy(req.Caller) {
+		return nil, NewSvcError(nil, ErrForbidden)
+	}
+
+	snapshots, err := s.repo.ListSnapshots(ctx, id)
+	if err != nil {
+		return nil, NewSvcError(err, ErrSnapshotNotFound)
+	}
+
+	res := &SnapshotList{DeckId: id.String(), Snapshots: make([]SnapshotDto, 0, len(snapshots))}
+	for _, snapshot := range snapshots {
+		res.Snapshots = append(res.Snapshots, snapshot.ToDto())
+	}
+	return res, nil
+}
+
+// RestoreSnapshot restores the state of the deck from the named snapshot, e.g. to roll back a table
+// after a dealer error. Restoring does not rewrite the history, it is an operation that increments
+// the version of the deck. Only the owner of the deck is allowed to restore it.
+func (s *Service) RestoreSnapshot(ctx context.Context, req SnapshotRequest) (*RestoreResponse, error) {
+	id, err := parseDeckId(req.DeckId)
+	if err != nil {
+		return nil, err
+	}
+	if err = validateSnapshotName(req.Name); err != nil {
+		return nil, err
+	}
+
+	deck, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, deckNotFound(err, id)
+	}
+
+	if !deck.OwnedBy(req.Caller) {
+		return nil, NewSvcError(nil, ErrForbidden)
+	}
+
+	snapshot, err := s.repo.GetSnapshot(ctx, id, req.Name)
+	if err != nil {
+		return nil, NewSvcError(err, ErrSnapshotNotFound).WithDetail("deck %s has no snapshot %q", id, req.Name)
+	}
+
+	deck, _, err = s.execute(ctx, id, Actor{Caller: req.Caller}, RestoreCommand{Snapshot: snapshot})
+	if err != nil {
+		return nil, err
+	}
+
+	return &RestoreResponse{
+		DeckId:    deck.id.String(),
+		Snapshot:  snapshot.Name,
+		Version:   deck.version,
+		Remaining: deck.remaining,
+	}, nil
+}
+
+// execute applies the commands to a copy of the deck under the service lock and stores the copy
+// with a single update when all of them succeed, so that a failing command leaves the deck untouched.
+// The update is a single operation that increments the version of the deck, it is a round of a shoe:
+// when its cut card came out in an earlier round, a shoe that reshuffles automatically is reshuffled
+// before the first draw.
+// The error of a failing command is a stepError.
+func (s *Service) execute(ctx context.Context, id uuid.UUID, by Actor, cmds ...Command) (*Deck, []StepResult, error) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	deckID, err := uuid.Parse(req.DeckId)
+	stored, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, nil, deckNotFound(err, id)
+	}
+
+	deck := stored.clone()
+	results := make([]StepResult, len(cmds))
+	newRound := deck.autoReshuffle && deck.ReshuffleNeeded()
+	for i, cmd := range cmds {
+		var reshuffle *Reshuffle
+		if _, ok := cmd.(DrawCommand); ok && newRound {
+			reshuffle, newRound = deck.reshuffleShoe(), false
+		}
+		effect, err := cmd.Apply(deck, by)
+		if err != nil {
+			return nil, nil, stepError{index: i, op: cmd.Op(), err: err}
+		}
+		if effect.Reshuffle == nil {
+			effect.Reshuffle = reshuffle
+		}
+		results[i] = StepResult{Op: cmd.Op(), Cards: ToDtos(effect.Cards), Remaining: deck.remaining, Reshuffled: effect.Reshuffle}
+	}
+	deck.version++
+
+	if _, err = s.repo.Update(ctx, deck); err != nil {
+		return nil, nil, NewSvcError(err, ErrUpdateDeck)
+	}
+	return deck, results, nil
+}
+
+// ShareDeck issues a token that grants the given scope on the deck to other clients.
+// Only the owner of the deck is allowed to share it.
+func (s *Service) ShareDeck(ctx context.Context, req ShareRequest) (*ShareResponse, error) {
+	if s.signer == nil {
+		return nil, NewSvcError(fmt.Errorf("grant signer is not configured"), ErrShareDeck)
+	}
+
+	id, err := parseDeckId(req.DeckId)
 	if err != nil {
 		return nil, err
 	}
 
-	deck, err := s.repo.Get(ctx, deckID)
+	var invalid []FieldError
+	if req.Scope != ScopeRead && req.Scope != ScopeDraw {
+		invalid = append(invalid, FieldError{Field: "scope", Reason: fmt.Sprintf("must be %q or %q", ScopeRead, ScopeDraw)})
+	}
+	if req.MaxCards < 0 {
+		invalid = append(invalid, FieldError{Field: "max_cards", Reason: "must not be negative"})
+	}
+	if req.TTLSeconds < 0 {
+		invalid = append(invalid, FieldError{Field: "ttl_seconds", Reason: "must not be negative"})
+	}
+	if len(invalid) > 0 {
+		return nil, NewValidationError(invalid...)
+	}
+
+	ttl := time.Duration(req.TTLSeconds) * time.Second
+	if ttl == 0 {
+		ttl = s.grantTTL
+	}
+	if ttl > s.grantMaxTTL {
+		ttl = s.grantMaxTTL
+	}
+
+	deck, err := s.repo.Get(ctx, id)
 	if err != nil {
-		return nil, NewSvcError(err, ErrUpdateDeck)
+		return nil, deckNotFound(err, id)
 	}
 
-	// draw cards from the deck
-	cards := make([]Card, 0, req.Count)
-	for i := 0; i < req.Count; i++ {
-		if deck.remaining == 0 {
-			break
-		}
+	if !deck.OwnedBy(req.Caller) {
+		return nil, NewSvcError(nil, ErrForbidden)
+	}
 
-		cards = append(cards, deck.cards[i])
-		deck.remaining--
+	grant := Grant{
+		Id:        uuid.NewString(),
+		DeckId:    deck.id.String(),
+		Scope:     req.Scope,
+		ExpiresAt: time.Now().Add(ttl).Truncate(time.Second),
 	}
-	deck.cards = deck.cards[req.Count:]
+	if req.Scope == ScopeDraw {
+		grant.MaxCards = req.MaxCards
+	}
+
+	token, err := s.signer.SignGrant(grant)
+	if err != nil {
+		return nil, NewSvcError(err, ErrShareDeck)
+	}
+
+	return &ShareResponse{
+		Token:     token,
+		DeckId:    grant.DeckId,
+		Scope:     string(grant.Scope),
+		MaxCards:  grant.MaxCards,
+		ExpiresAt: grant.ExpiresAt,
+	}, nil
+}
 
-	// update the deck
-	s.repo.Update(ctx, deck)
+// validateSnapshotName returns a validation error for names that cannot be part of a snapshot url.
+func validateSnapshotName(name string) error {
+	if !snapshotName.MatchString(name) {
+		return NewValidationError(FieldError{Field: "name", Reason: "must be 1 to 64 letters, digits, dots, dashes or underscores"})
+	}
+	return nil
+}
+
+// parseDeckId parses the id of a deck or returns a validation error.
+func parseDeckId(id string) (uuid.UUID, error) {
+	deckID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, NewValidationError(FieldError{Field: "deck_id", Reason: "must be a UUID"})
+	}
+	return deckID, nil
+}
 
-	return &DrawResponse{Cards: ToDtos(cards)}, nil
+func deckNotFound(err error, id uuid.UUID) SvcError {
+	return NewSvcError(err, ErrDeckNotFound).WithDetail("deck %s does not exist", id)
 }
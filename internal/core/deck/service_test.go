@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 	"toggl-card-game/internal/core/deck"
 	mocks "toggl-card-game/mocks/internal_/core/deck"
 
@@ -219,6 +220,130 @@ func TestService_OpenDeck(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name: "open deck owned by another subject test",
+			args: deck.OpenRequest{DeckId: uuid.NewString(), Caller: "bob"},
+			when: func() (*deck.Deck, error) {
+				return deck.NewBuilder().Owner("alice").Build()
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "open deck owned by caller test",
+			args: deck.OpenRequest{DeckId: uuid.NewString(), Caller: "alice"},
+			when: func() (*deck.Deck, error) {
+				return deck.NewBuilder().Owner("alice").Build()
+			},
+			want: &deck.OpenResponse{
+				DeckId:    uuid.NewString(),
+				Shuffled:  false,
+				Remaining: 52,
+				Cards:     dtos,
+			},
+			wantErr: false,
+		},
+		{
+			name: "open first page of deck test",
+			args: deck.OpenRequest{DeckId: uuid.NewString(), Limit: 2},
+			when: func() (*deck.Deck, error) {
+				return deck.NewBuilder().
+					AddCard(deck.CardsMap["AS"]).
+					AddCard(deck.CardsMap["2H"]).
+					AddCard(deck.CardsMap["10S"]).
+					Build()
+			},
+			want: &deck.OpenResponse{
+				Remaining: 3,
+				Cards:     []deck.CardDto{deck.CardsMap["AS"].ToDto(), deck.CardsMap["2H"].ToDto()},
+				Page:      &deck.Page{Offset: 0, Limit: 2, Total: 3, NextOffset: 2},
+			},
+			wantErr: false,
+		},
+		{
+			name: "open last page of deck test",
+			args: deck.OpenRequest{DeckId: uuid.NewString(), Offset: 2, Limit: 2},
+			when: func() (*deck.Deck, error) {
+				return deck.NewBuilder().
+					AddCard(deck.CardsMap["AS"]).
+					AddCard(deck.CardsMap["2H"]).
+					AddCard(deck.CardsMap["10S"]).
+					Build()
+			},
+			want: &deck.OpenResponse{
+				Remaining: 3,
+				Cards:     []deck.CardDto{deck.CardsMap["10S"].ToDto()},
+				Page:      &deck.Page{Offset: 2, Limit: 2, Total: 3},
+			},
+			wantErr: false,
+		},
+		{
+			name: "open page past the end of deck test",
+			args: deck.OpenRequest{DeckId: uuid.NewString(), Offset: 5},
+			when: func() (*deck.Deck, error) {
+				return deck.NewBuilder().
+					AddCard(deck.CardsMap["AS"]).
+					AddCard(deck.CardsMap["2H"]).
+					AddCard(deck.CardsMap["10S"]).
+					Build()
+			},
+			want: &deck.OpenResponse{
+				Remaining: 3,
+				Cards:     []deck.CardDto{},
+				Page:      &deck.Page{Offset: 5, Total: 3},
+			},
+			wantErr: false,
+		},
+		{
+			name: "open deck with card codes only test",
+			args: deck.OpenRequest{DeckId: uuid.NewString(), Fields: []string{"code"}},
+			when: func() (*deck.Deck, error) {
+				return deck.NewBuilder().
+					AddCard(deck.CardsMap["AS"]).
+					AddCard(deck.CardsMap["2H"]).
+					AddCard(deck.CardsMap["10S"]).
+					Build()
+			},
+			want: &deck.OpenResponse{
+				Remaining: 3,
+				Cards:     []deck.CardDto{{Code: "AS"}, {Code: "2H"}, {Code: "10S"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "open deck summary test",
+			args: deck.OpenRequest{DeckId: uuid.NewString(), Summary: true},
+			when: func() (*deck.Deck, error) {
+				return deck.NewBuilder().
+					AddCard(deck.CardsMap["AS"]).
+					AddCard(deck.CardsMap["2H"]).
+					AddCard(deck.CardsMap["10S"]).
+					Build()
+			},
+			want: &deck.OpenResponse{
+				Remaining: 3,
+				Suits:     []deck.SuitCount{{Suit: "SPADES", Count: 2}, {Suit: "HEARTS", Count: 1}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "open deck negative offset test",
+			args: deck.OpenRequest{DeckId: uuid.NewString(), Offset: -1},
+			when: func() (*deck.Deck, error) {
+				return deck.NewBuilder().Build()
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "open deck unknown card field test",
+			args: deck.OpenRequest{DeckId: uuid.NewString(), Fields: []string{"colour"}},
+			when: func() (*deck.Deck, error) {
+				return deck.NewBuilder().Build()
+			},
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name: "open deck repo returns an error test",
 			args: deck.OpenRequest{DeckId: uuid.NewString()},
@@ -255,6 +380,8 @@ func TestService_OpenDeck(t *testing.T) {
 			assert.Equal(t, tt.want.Shuffled, actual.Shuffled)
 			assert.Equal(t, tt.want.Remaining, actual.Remaining)
 			assert.True(t, len(actual.Cards) == len(tt.want.Cards))
+			assert.Equal(t, tt.want.Page, actual.Page)
+			assert.Equal(t, tt.want.Suits, actual.Suits)
 
 			if !tt.want.Shuffled {
 				assert.Equal(t, actual.Cards, tt.want.Cards)
@@ -263,6 +390,110 @@ func TestService_OpenDeck(t *testing.T) {
 	}
 }
 
+func TestService_OpenFaceDownDeck(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.New()
+	faceDown := func() (*deck.Deck, error) {
+		return deck.NewBuilder().
+			Id(id).
+			Owner("alice").
+			FaceDown(true).
+			FaceUp(deck.CardsMap["QH"]).
+			Cards(deck.ToCards([]string{"AS", "QH", "10D"})).
+			Build()
+	}
+	back := deck.CardDto{FaceDown: true}
+	hiddenDto := func(code string) deck.CardDto {
+		dto := deck.CardsMap[code].ToDto()
+		dto.FaceDown = true
+		return dto
+	}
+
+	tests := []struct {
+		name    string
+		args    deck.OpenRequest
+		want    *deck.OpenResponse
+		wantErr bool
+	}{
+		{
+			name: "owner sees all faces test",
+			args: deck.OpenRequest{DeckId: id.String(), Caller: "alice"},
+			want: &deck.OpenResponse{
+				Cards: []deck.CardDto{hiddenDto("AS"), deck.CardsMap["QH"].ToDto(), hiddenDto("10D")},
+			},
+		},
+		{
+			name: "player sees backs test",
+			args: deck.OpenRequest{DeckId: id.String(), Grant: &deck.Grant{DeckId: id.String(), Scope: deck.ScopeDraw}},
+			want: &deck.OpenResponse{
+				Hidden: 2,
+				Cards:  []deck.CardDto{back, deck.CardsMap["QH"].ToDto(), back},
+			},
+		},
+		{
+			name: "spectator sees face up cards only test",
+			args: deck.OpenRequest{DeckId: id.String(), Grant: &deck.Grant{DeckId: id.String(), Scope: deck.ScopeRead}},
+			want: &deck.OpenResponse{
+				Hidden: 2,
+				Cards:  []deck.CardDto{deck.CardsMap["QH"].ToDto()},
+			},
+		},
+		{
+			name: "player summary counts face up cards test",
+			args: deck.OpenRequest{DeckId: id.String(), Grant: &deck.Grant{DeckId: id.String(), Scope: deck.ScopeDraw}, Summary: true},
+			want: &deck.OpenResponse{
+				Hidden: 2,
+				Suits:  []deck.SuitCount{{Suit: "HEARTS", Count: 1}},
+			},
+		},
+		{
+			name: "owner summary counts all cards test",
+			args: deck.OpenRequest{DeckId: id.String(), Caller: "alice", Summary: true},
+			want: &deck.OpenResponse{
+				Suits: []deck.SuitCount{{Suit: "SPADES", Count: 1}, {Suit: "DIAMONDS", Count: 1}, {Suit: "HEARTS", Count: 1}},
+			},
+		},
+		{
+			name: "player pages backs test",
+			args: deck.OpenRequest{DeckId: id.String(), Grant: &deck.Grant{DeckId: id.String(), Scope: deck.ScopeDraw}, Offset: 1, Limit: 1},
+			want: &deck.OpenResponse{
+				Hidden: 2,
+				Cards:  []deck.CardDto{deck.CardsMap["QH"].ToDto()},
+				Page:   &deck.Page{Offset: 1, Limit: 1, Total: 3, NextOffset: 2},
+			},
+		},
+		{
+			name:    "grant of another deck test",
+			args:    deck.OpenRequest{DeckId: id.String(), Caller: "alice", Grant: &deck.Grant{DeckId: uuid.NewString(), Scope: deck.ScopeDraw}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := faceDown()
+			assert.NoError(t, err)
+			repoMock := mocks.NewRepo(t)
+			repoMock.On("Get", ctx, id).Return(d, nil)
+
+			svc := deck.NewService(repoMock)
+
+			actual, err := svc.OpenDeck(ctx, tt.args)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, deck.ErrForbidden)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, actual.FaceDown)
+			assert.Equal(t, 3, actual.Remaining)
+			assert.Equal(t, tt.want.Hidden, actual.Hidden)
+			assert.Equal(t, tt.want.Cards, actual.Cards)
+			assert.Equal(t, tt.want.Page, actual.Page)
+			assert.Equal(t, tt.want.Suits, actual.Suits)
+		})
+	}
+}
+
 func TestService_DrawCards(t *testing.T) {
 	ctx := context.Background()
 
@@ -311,6 +542,15 @@ func TestService_DrawCards(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name: "draw from deck owned by another subject test",
+			args: deck.DrawRequest{DeckId: uuid.NewString(), Count: 3, Caller: "bob"},
+			when: func() (*deck.Deck, error) {
+				return deck.NewBuilder().Owner("alice").Build()
+			},
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name: "open deck repo returns an error test",
 			args: deck.DrawRequest{DeckId: uuid.NewString(), Count: 3},
@@ -344,6 +584,812 @@ func TestService_DrawCards(t *testing.T) {
 	}
 }
 
+func TestService_DrawCardsOnEmpty(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.New()
+
+	tests := []struct {
+		name          string
+		policy        deck.EmptyPolicy
+		wantDrawn     int
+		wantReshuffle *deck.Reshuffle
+		wantRemaining int
+		wantDiscarded int
+	}{
+		{
+			name:          "no policy draws short test",
+			policy:        deck.EmptyNone,
+			wantDrawn:     1,
+			wantRemaining: 0,
+			wantDiscarded: 1,
+		},
+		{
+			name:          "reshuffle discards once per draw test",
+			policy:        deck.EmptyReshuffleDiscards,
+			wantDrawn:     2,
+			wantReshuffle: &deck.Reshuffle{Policy: "reshuffle_discards", After: 1, Cards: 1},
+			wantRemaining: 0,
+			wantDiscarded: 0,
+		},
+		{
+			name:          "reshuffle all test",
+			policy:        deck.EmptyReshuffleAll,
+			wantDrawn:     3,
+			wantReshuffle: &deck.Reshuffle{Policy: "reshuffle_all", After: 1, Cards: 3},
+			wantRemaining: 1,
+			wantDiscarded: 0,
+		},
+		{
+			name:          "refill test",
+			policy:        deck.EmptyRefill,
+			wantDrawn:     3,
+			wantReshuffle: &deck.Reshuffle{Policy: "refill", After: 1, Cards: 3},
+			wantRemaining: 1,
+			wantDiscarded: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored, err := deck.NewBuilder().Id(id).Owner("alice").FaceDown(true).FaceUp(deck.ToCards([]string{"2S"})...).
+				OnEmpty(tt.policy).Cards(deck.ToCards([]string{"AS", "2S", "3S"})).Build()
+			assert.NoError(t, err)
+			var updated *deck.Deck
+			repoMock := mocks.NewRepo(t)
+			repoMock.On("Get", ctx, id).Return(stored, nil)
+			repoMock.On("Update", ctx, mock.Anything).Run(func(args mock.Arguments) {
+				updated = args.Get(1).(*deck.Deck)
+			}).Return(stored, nil)
+
+			svc := deck.NewService(repoMock)
+
+			actual, err := svc.Batch(ctx, deck.BatchRequest{DeckId: id.String(), Caller: "alice", Steps: []deck.BatchStep{
+				{Op: deck.OpDraw, Count: 2},
+				{Op: deck.OpDiscard, Cards: []string{"AS"}},
+				{Op: deck.OpDraw, Count: 3},
+			}})
+
+			assert.NoError(t, err)
+			drawn := actual.Results[2]
+			if assert.Len(t, drawn.Cards, tt.wantDrawn) {
+				assert.Equal(t, "3S", drawn.Cards[0].Code)
+			}
+			assert.Equal(t, tt.wantReshuffle, drawn.Reshuffled)
+			assert.Equal(t, tt.wantRemaining, updated.Remaining())
+			assert.Equal(t, tt.wantDiscarded, updated.Discarded())
+			// restocked cards lie like in the composition, the face up card stays face up
+			for _, c := range updated.Export().Cards {
+				assert.Equal(t, c.Code != "2S", c.FaceDown, c.Code)
+			}
+		})
+	}
+
+	t.Run("refilled face up cards stay face up test", func(t *testing.T) {
+		stored, err := deck.NewBuilder().Id(id).Owner("alice").FaceDown(true).FaceUp(deck.ToCards([]string{"2S"})...).
+			Decks(2).OnEmpty(deck.EmptyRefill).Cards(deck.ToCards([]string{"2S"})).Build()
+		assert.NoError(t, err)
+		var updated *deck.Deck
+		repoMock := mocks.NewRepo(t)
+		repoMock.On("Get", ctx, id).Return(stored, nil)
+		repoMock.On("Update", ctx, mock.Anything).Run(func(args mock.Arguments) {
+			updated = args.Get(1).(*deck.Deck)
+		}).Return(stored, nil)
+
+		_, err = deck.NewService(repoMock).Batch(ctx, deck.BatchRequest{DeckId: id.String(), Caller: "alice", Steps: []deck.BatchStep{
+			{Op: deck.OpDraw, Count: 3},
+		}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []deck.CardDto{{Value: "2", Suit: "SPADES", Code: "2S"}}, updated.Export().Cards)
+	})
+
+	t.Run("discard cards drawn before a refill test", func(t *testing.T) {
+		stored, err := deck.NewBuilder().Id(id).Owner("alice").OnEmpty(deck.EmptyRefill).
+			Cards(deck.ToCards([]string{"AS", "2S"})).Build()
+		assert.NoError(t, err)
+		var updated *deck.Deck
+		repoMock := mocks.NewRepo(t)
+		repoMock.On("Get", ctx, id).Return(stored, nil)
+		repoMock.On("Update", ctx, mock.Anything).Run(func(args mock.Arguments) {
+			updated = args.Get(1).(*deck.Deck)
+		}).Return(stored, nil)
+
+		_, err = deck.NewService(repoMock).Batch(ctx, deck.BatchRequest{DeckId: id.String(), Caller: "alice", Steps: []deck.BatchStep{
+			{Op: deck.OpDraw, Count: 3},
+			{Op: deck.OpDiscard, Cards: []string{"AS", "2S"}},
+		}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, updated.Remaining())
+		assert.Equal(t, 2, updated.Discarded())
+	})
+
+	t.Run("discard cards reshuffled into the deck test", func(t *testing.T) {
+		stored, err := deck.NewBuilder().Id(id).Owner("alice").OnEmpty(deck.EmptyReshuffleAll).
+			Cards(deck.ToCards([]string{"AS"})).Build()
+		assert.NoError(t, err)
+		repoMock := mocks.NewRepo(t)
+		repoMock.On("Get", ctx, id).Return(stored, nil)
+
+		_, err = deck.NewService(repoMock).Batch(ctx, deck.BatchRequest{DeckId: id.String(), Caller: "alice", Steps: []deck.BatchStep{
+			{Op: deck.OpDraw, Count: 1},
+			{Op: deck.OpDraw, Count: 1},
+			{Op: deck.OpDiscard, Cards: []string{"AS", "AS"}},
+		}})
+
+		assert.ErrorIs(t, err, deck.ErrNotDiscardable)
+	})
+}
+
+func TestService_Shoe(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.New()
+	draw := func(n int) deck.BatchStep { return deck.BatchStep{Op: deck.OpDraw, Count: n} }
+
+	tests := []struct {
+		name          string
+		autoReshuffle bool
+		// round is the last round, it is played after two rounds dealt the cut card
+		round         []deck.BatchStep
+		wantReshuffle *deck.Reshuffle
+		wantRemaining int
+		wantDealt     int
+	}{
+		{
+			name:          "auto reshuffle before the next round test",
+			autoReshuffle: true,
+			round:         []deck.BatchStep{draw(1), draw(1)},
+			wantReshuffle: &deck.Reshuffle{Policy: deck.ReshuffleCutCard, Cards: 4},
+			wantRemaining: 2,
+			wantDealt:     2,
+		},
+		{
+			name:          "no auto reshuffle test",
+			round:         []deck.BatchStep{draw(1)},
+			wantRemaining: 1,
+			wantDealt:     3,
+		},
+		{
+			name:          "shuffle of the owner reshuffles the shoe test",
+			round:         []deck.BatchStep{{Op: deck.OpShuffle}},
+			wantReshuffle: &deck.Reshuffle{Policy: deck.ReshuffleCutCard, Cards: 4},
+			wantRemaining: 4,
+			wantDealt:     0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, err := deck.NewBuilder().Id(id).Owner("alice").Cards(deck.ToCards([]string{"AS", "KH"})).Decks(2).
+				Penetration(50).AutoReshuffle(tt.autoReshuffle).Build()
+			assert.NoError(t, err)
+			repoMock := mocks.NewRepo(t)
+			repoMock.On("Get", ctx, id).Return(func(context.Context, uuid.UUID) (*deck.Deck, error) { return current, nil })
+			repoMock.On("Update", ctx, mock.Anything).Run(func(args mock.Arguments) {
+				current = args.Get(1).(*deck.Deck)
+			}).Return(nil, nil)
+
+			svc := deck.NewService(repoMock)
+
+			drawn, err := svc.DrawCards(ctx, deck.DrawRequest{DeckId: id.String(), Caller: "alice", Count: 1})
+			assert.NoError(t, err)
+			assert.Equal(t, &deck.Penetration{Percent: 50, CutCard: 2, Dealt: 1, AutoReshuffle: tt.autoReshuffle}, drawn.Penetration)
+
+			// the ace is discarded while the ace of the other deck is in the shoe
+			_, err = svc.Batch(ctx, deck.BatchRequest{DeckId: id.String(), Caller: "alice", Steps: []deck.BatchStep{
+				draw(1), {Op: deck.OpDiscard, Cards: []string{"AS"}},
+			}})
+			assert.NoError(t, err)
+			opened, err := svc.OpenDeck(ctx, deck.OpenRequest{DeckId: id.String(), Caller: "alice"})
+			assert.NoError(t, err)
+			assert.True(t, opened.Penetration.ReshuffleNeeded)
+
+			actual, err := svc.Batch(ctx, deck.BatchRequest{DeckId: id.String(), Caller: "alice", Steps: tt.round})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantReshuffle, actual.Results[0].Reshuffled)
+			// the shoe is reshuffled only once per round
+			for _, result := range actual.Results[1:] {
+				assert.Nil(t, result.Reshuffled)
+			}
+			assert.Equal(t, tt.wantRemaining, actual.Remaining)
+			opened, err = svc.OpenDeck(ctx, deck.OpenRequest{DeckId: id.String(), Caller: "alice"})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantDealt, opened.Penetration.Dealt)
+			assert.Equal(t, tt.wantDealt >= 2, opened.Penetration.ReshuffleNeeded)
+		})
+	}
+}
+
+func TestService_ShuffleDeck(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		args    deck.ShuffleRequest
+		when    func() (*deck.Deck, error)
+		want    *deck.ShuffleResponse
+		wantErr bool
+	}{
+		{
+			name: "shuffle full deck test",
+			args: deck.ShuffleRequest{DeckId: uuid.NewString()},
+			when: func() (*deck.Deck, error) {
+				return deck.NewBuilder().Build()
+			},
+			want:    &deck.ShuffleResponse{Shuffled: true, Remaining: 52},
+			wantErr: false,
+		},
+		{
+			name: "shuffle deck owned by caller test",
+			args: deck.ShuffleRequest{DeckId: uuid.NewString(), Caller: "alice"},
+			when: func() (*deck.Deck, error) {
+				return deck.NewBuilder().Owner("alice").Cards(deck.ToCards([]string{"AS", "2S", "3S"})).Build()
+			},
+			want:    &deck.ShuffleResponse{Shuffled: true, Remaining: 3},
+			wantErr: false,
+		},
+		{
+			name: "shuffle deck owned by another subject test",
+			args: deck.ShuffleRequest{DeckId: uuid.NewString(), Caller: "bob"},
+			when: func() (*deck.Deck, error) {
+				return deck.NewBuilder().Owner("alice").Build()
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "shuffle deck invalid id test",
+			args: deck.ShuffleRequest{DeckId: "invalid-id"},
+			when: func() (*deck.Deck, error) {
+				return deck.NewBuilder().Build()
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "shuffle deck repo returns an error test",
+			args: deck.ShuffleRequest{DeckId: uuid.NewString()},
+			when: func() (*deck.Deck, error) {
+				return nil, errors.New("repo error")
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// mock repo call
+			d, err := tt.when()
+			repoMock := mocks.NewRepo(t)
+			repoMock.On("Get", ctx, mock.Anything).Return(d, err).Maybe()
+			repoMock.On("Update", ctx, mock.Anything).Return(d, err).Maybe()
+
+			// service under test
+			svc := deck.NewService(repoMock)
+
+			actual, err := svc.ShuffleDeck(ctx, tt.args)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+
+			if err != nil {
+				assert.FailNow(t, err.Error())
+				return
+			}
+
+			assert.Equal(t, d.Id().String(), actual.DeckId)
+			assert.Equal(t, tt.want.Shuffled, actual.Shuffled)
+			assert.Equal(t, tt.want.Remaining, actual.Remaining)
+		})
+	}
+}
+
+func TestService_CloneDeck(t *testing.T) {
+	ctx := context.Background()
+	source, _ := deck.NewBuilder().Owner("alice").Cards(deck.ToCards([]string{"AS", "2S", "3S"})).Build()
+
+	tests := []struct {
+		name      string
+		args      deck.CloneRequest
+		when      func() (*deck.Deck, error)
+		createErr error
+		want      *deck.CreateResponse
+		wantErr   bool
+	}{
+		{
+			name: "clone deck test",
+			args: deck.CloneRequest{DeckId: source.Id().String(), Caller: "alice"},
+			when: func() (*deck.Deck, error) {
+				return source, nil
+			},
+			want: &deck.CreateResponse{Shuffled: false, Remaining: 3},
+		},
+		{
+			name: "clone and reshuffle deck test",
+			args: deck.CloneRequest{DeckId: source.Id().String(), Caller: "alice", Reshuffle: true},
+			when: func() (*deck.Deck, error) {
+				return source, nil
+			},
+			want: &deck.CreateResponse{Shuffled: true, Remaining: 3},
+		},
+		{
+			name: "clone deck owned by another subject test",
+			args: deck.CloneRequest{DeckId: source.Id().String(), Caller: "bob"},
+			when: func() (*deck.Deck, error) {
+				return source, nil
+			},
+			wantErr: true,
+		},
+		{
+			name: "clone deck invalid id test",
+			args: deck.CloneRequest{DeckId: "invalid-id"},
+			when: func() (*deck.Deck, error) {
+				return source, nil
+			},
+			wantErr: true,
+		},
+		{
+			name: "clone unknown deck test",
+			args: deck.CloneRequest{DeckId: uuid.NewString()},
+			when: func() (*deck.Deck, error) {
+				return nil, errors.New("repo error")
+			},
+			wantErr: true,
+		},
+		{
+			name: "repo fails to create the clone test",
+			args: deck.CloneRequest{DeckId: source.Id().String(), Caller: "alice"},
+			when: func() (*deck.Deck, error) {
+				return source, nil
+			},
+			createErr: errors.New("repo error"),
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// mock repo call
+			d, err := tt.when()
+			repoMock := mocks.NewRepo(t)
+			repoMock.On("Get", ctx, mock.Anything).Return(d, err).Maybe()
+			repoMock.EXPECT().Create(ctx, mock.Anything).RunAndReturn(func(ctx context.Context, clone *deck.Deck) (*deck.Deck, error) {
+				return clone, tt.createErr
+			}).Maybe()
+
+			// service under test
+			svc := deck.NewService(repoMock)
+
+			actual, err := svc.CloneDeck(ctx, tt.args)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+
+			if err != nil {
+				assert.FailNow(t, err.Error())
+				return
+			}
+
+			assert.NotEqual(t, source.Id().String(), actual.DeckId)
+			assert.Equal(t, tt.want.Shuffled, actual.Shuffled)
+			assert.Equal(t, tt.want.Remaining, actual.Remaining)
+			assert.False(t, source.Shuffled())
+		})
+	}
+}
+
+func TestService_ImportDeck(t *testing.T) {
+	ctx := context.Background()
+	source, _ := deck.NewBuilder().Owner("alice").Cards(deck.ToCards([]string{"AS", "2S", "3S"})).Build()
+
+	tests := []struct {
+		name     string
+		args     deck.ImportRequest
+		existing error
+		want     *deck.CreateResponse
+		wantErr  *deck.Error
+	}{
+		{
+			name:     "import deck test",
+			args:     deck.ImportRequest{Caller: "bob", Deck: source.Export()},
+			existing: errors.New("not found"),
+			want:     &deck.CreateResponse{DeckId: source.Id().String(), Remaining: 3},
+		},
+		{
+			name:    "import existing deck test",
+			args:    deck.ImportRequest{Caller: "bob", Deck: source.Export()},
+			wantErr: deck.ErrDeckExists,
+		},
+		{
+			name:    "import unsupported version test",
+			args:    deck.ImportRequest{Caller: "bob", Deck: deck.DeckExport{Format: deck.ExportFormat, Version: 2}},
+			wantErr: deck.ErrInvalidRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// mock repo call
+			repoMock := mocks.NewRepo(t)
+			repoMock.On("Get", ctx, mock.Anything).Return(source, tt.existing).Maybe()
+			repoMock.EXPECT().Create(ctx, mock.Anything).RunAndReturn(func(ctx context.Context, d *deck.Deck) (*deck.Deck, error) {
+				assert.Equal(t, tt.args.Caller, d.Owner())
+				return d, nil
+			}).Maybe()
+
+			// service under test
+			svc := deck.NewService(repoMock)
+
+			actual, err := svc.ImportDeck(ctx, tt.args)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			if err != nil {
+				assert.FailNow(t, err.Error())
+				return
+			}
+
+			assert.Equal(t, tt.want, actual)
+		})
+	}
+}
+
+type signerStub struct{}
+
+func (signerStub) SignGrant(grant deck.Grant) (string, error) {
+	return "token-" + grant.Id, nil
+}
+
+func TestService_OpenDeckVersion(t *testing.T) {
+	ctx := context.Background()
+	source, _ := deck.NewBuilder().Owner("alice").Cards(deck.ToCards([]string{"AS", "2S", "3S"})).Build()
+
+	// draw once to get the deck at version 1
+	repoMock := mocks.NewRepo(t)
+	repoMock.EXPECT().Get(ctx, source.Id()).Return(source, nil).Once()
+	var current *deck.Deck
+	repoMock.EXPECT().Update(ctx, mock.Anything).RunAndReturn(func(ctx context.Context, d *deck.Deck) (*deck.Deck, error) {
+		current = d
+		return d, nil
+	}).Once()
+	_, err := deck.NewService(repoMock).DrawCards(ctx, deck.DrawRequest{DeckId: source.Id().String(), Count: 1, Caller: "alice"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, current.Version())
+	assert.Equal(t, 0, source.Version())
+
+	version := func(v int) *int { return &v }
+	tests := []struct {
+		name          string
+		version       *int
+		wantVersion   int
+		wantRemaining int
+		wantErr       error
+	}{
+		{
+			name:          "open current version test",
+			wantVersion:   1,
+			wantRemaining: 2,
+		},
+		{
+			name:          "open created version test",
+			version:       version(0),
+			wantVersion:   0,
+			wantRemaining: 3,
+		},
+		{
+			name:    "open future version test",
+			version: version(2),
+			wantErr: deck.ErrVersionNotFound,
+		},
+		{
+			name:    "open negative version test",
+			version: version(-1),
+			wantErr: deck.ErrInvalidRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// mock repo call
+			repoMock := mocks.NewRepo(t)
+			repoMock.EXPECT().Get(ctx, source.Id()).Return(current, nil).Maybe()
+			repoMock.EXPECT().GetVersion(ctx, source.Id(), 0).Return(source, nil).Maybe()
+
+			// service under test
+			svc := deck.NewService(repoMock)
+
+			actual, err := svc.OpenDeck(ctx, deck.OpenRequest{DeckId: source.Id().String(), Caller: "alice", Version: tt.version})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			if err != nil {
+				assert.FailNow(t, err.Error())
+				return
+			}
+
+			assert.Equal(t, tt.wantVersion, actual.Version)
+			assert.Equal(t, tt.wantRemaining, actual.Remaining)
+		})
+	}
+}
+
+func TestService_RestoreSnapshot(t *testing.T) {
+	ctx := context.Background()
+	current, _ := deck.NewBuilder().Owner("alice").Cards(deck.ToCards([]string{"3S"})).Build()
+	taken, _ := deck.NewBuilder().Owner("alice").Cards(deck.ToCards([]string{"AS", "2S", "3S"})).Build()
+	snapshot := deck.Snapshot{Name: "round-1", TakenAt: time.Now(), Deck: taken}
+
+	tests := []struct {
+		name        string
+		args        deck.SnapshotRequest
+		snapshotErr error
+		updateErr   error
+		want        *deck.RestoreResponse
+		wantErr     error
+	}{
+		{
+			name: "restore snapshot test",
+			args: deck.SnapshotRequest{DeckId: current.Id().String(), Caller: "alice", Name: "round-1"},
+			want: &deck.RestoreResponse{DeckId: current.Id().String(), Snapshot: "round-1", Version: 1, Remaining: 3},
+		},
+		{
+			name:    "restore deck owned by another subject test",
+			args:    deck.SnapshotRequest{DeckId: current.Id().String(), Caller: "bob", Name: "round-1"},
+			wantErr: deck.ErrForbidden,
+		},
+		{
+			name:        "restore unknown snapshot test",
+			args:        deck.SnapshotRequest{DeckId: current.Id().String(), Caller: "alice", Name: "round-2"},
+			snapshotErr: errors.New("repo error"),
+			wantErr:     deck.ErrSnapshotNotFound,
+		},
+		{
+			name:    "restore invalid snapshot name test",
+			args:    deck.SnapshotRequest{DeckId: current.Id().String(), Caller: "alice", Name: "../round-1"},
+			wantErr: deck.ErrInvalidRequest,
+		},
+		{
+			name:      "repo fails to update the deck test",
+			args:      deck.SnapshotRequest{DeckId: current.Id().String(), Caller: "alice", Name: "round-1"},
+			updateErr: errors.New("repo error"),
+			wantErr:   deck.ErrUpdateDeck,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// mock repo call
+			repoMock := mocks.NewRepo(t)
+			repoMock.EXPECT().Get(ctx, current.Id()).Return(current, nil).Maybe()
+			repoMock.EXPECT().GetSnapshot(ctx, current.Id(), tt.args.Name).Return(snapshot, tt.snapshotErr).Maybe()
+			repoMock.EXPECT().Update(ctx, mock.Anything).RunAndReturn(func(ctx context.Context, d *deck.Deck) (*deck.Deck, error) {
+				assert.True(t, d.Equals(taken))
+				return d, tt.updateErr
+			}).Maybe()
+
+			// service under test
+			svc := deck.NewService(repoMock)
+
+			actual, err := svc.RestoreSnapshot(ctx, tt.args)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			if err != nil {
+				assert.FailNow(t, err.Error())
+				return
+			}
+
+			assert.Equal(t, tt.want, actual)
+			assert.Equal(t, 1, current.Remaining())
+			assert.Equal(t, 0, taken.Version())
+		})
+	}
+}
+
+func TestService_Batch(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.New()
+	player := &deck.Grant{Id: "grant", DeckId: id.String(), Scope: deck.ScopeDraw, MaxCards: 2}
+	cards := func(codes ...string) []deck.CardDto {
+		return deck.ToDtos(deck.ToCards(codes))
+	}
+
+	tests := []struct {
+		name       string
+		args       deck.BatchRequest
+		want       []deck.StepResult
+		wantErr    error
+		wantFields []deck.FieldError
+	}{
+		{
+			name: "draw discard shuffle test",
+			args: deck.BatchRequest{Caller: "alice", Steps: []deck.BatchStep{
+				{Op: deck.OpDraw, Count: 2},
+				{Op: deck.OpDiscard, Cards: []string{"2S"}},
+				{Op: deck.OpShuffle},
+			}},
+			want: []deck.StepResult{
+				{Op: deck.OpDraw, Cards: cards("AS", "2S"), Remaining: 2},
+				{Op: deck.OpDiscard, Cards: cards("2S"), Remaining: 2},
+				{Op: deck.OpShuffle, Cards: cards(), Remaining: 2},
+			},
+		},
+		{
+			name: "player draws and discards test",
+			args: deck.BatchRequest{Grant: player, Steps: []deck.BatchStep{
+				{Op: deck.OpDraw, Count: 1},
+				{Op: deck.OpDiscard, Cards: []string{"AS"}},
+			}},
+			want: []deck.StepResult{
+				{Op: deck.OpDraw, Cards: cards("AS"), Remaining: 3},
+				{Op: deck.OpDiscard, Cards: cards("AS"), Remaining: 3},
+			},
+		},
+		{
+			name: "player exceeds grant limit across steps test",
+			args: deck.BatchRequest{Grant: player, Steps: []deck.BatchStep{
+				{Op: deck.OpDraw, Count: 2},
+				{Op: deck.OpDraw, Count: 1},
+			}},
+			wantErr: deck.ErrGrantLimit,
+		},
+		{
+			name: "player shuffles test",
+			args: deck.BatchRequest{Grant: player, Steps: []deck.BatchStep{
+				{Op: deck.OpDraw, Count: 1},
+				{Op: deck.OpShuffle},
+			}},
+			wantErr: deck.ErrForbidden,
+		},
+		{
+			name: "discard card in deck rolls back test",
+			args: deck.BatchRequest{Caller: "alice", Steps: []deck.BatchStep{
+				{Op: deck.OpDraw, Count: 1},
+				{Op: deck.OpDiscard, Cards: []string{"10S"}},
+			}},
+			wantErr: deck.ErrNotDiscardable,
+		},
+		{
+			name: "discard card not of the deck test",
+			args: deck.BatchRequest{Caller: "alice", Steps: []deck.BatchStep{
+				{Op: deck.OpDraw, Count: 1},
+				{Op: deck.OpDiscard, Cards: []string{"2C"}},
+			}},
+			wantErr: deck.ErrNotDiscardable,
+		},
+		{
+			name: "invalid steps test",
+			args: deck.BatchRequest{Caller: "alice", Steps: []deck.BatchStep{
+				{Op: deck.OpDraw},
+				{Op: deck.OpDiscard, Cards: []string{"XX"}},
+				{Op: "cut"},
+			}},
+			wantErr: deck.ErrInvalidRequest,
+			wantFields: []deck.FieldError{
+				{Field: "steps[0].count", Reason: "must be positive"},
+				{Field: "steps[1].cards[0]", Reason: `is not a card code: "XX"`},
+				{Field: "steps[2].op", Reason: "must be one of draw, discard or shuffle"},
+			},
+		},
+		{
+			name:       "no steps test",
+			args:       deck.BatchRequest{Caller: "alice"},
+			wantErr:    deck.ErrInvalidRequest,
+			wantFields: []deck.FieldError{{Field: "steps", Reason: "must have 1 to 50 steps"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored, err := deck.NewBuilder().Id(id).Owner("alice").Cards(deck.ToCards([]string{"AS", "2S", "3S", "10S"})).Build()
+			assert.NoError(t, err)
+			repoMock := mocks.NewRepo(t)
+			repoMock.On("Get", ctx, id).Return(stored, nil).Maybe()
+			if tt.wantErr == nil {
+				repoMock.On("Update", ctx, mock.Anything).Return(stored, nil)
+			}
+
+			svc := deck.NewService(repoMock)
+
+			tt.args.DeckId = id.String()
+			actual, err := svc.Batch(ctx, tt.args)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				var svcErr deck.SvcError
+				if assert.ErrorAs(t, err, &svcErr) {
+					assert.Equal(t, tt.wantFields, svcErr.Fields)
+				}
+				// the stored deck is untouched
+				assert.Equal(t, 4, stored.Remaining())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, id.String(), actual.DeckId)
+			assert.Equal(t, tt.want[len(tt.want)-1].Remaining, actual.Remaining)
+			assert.Equal(t, tt.want, actual.Results)
+		})
+	}
+}
+
+func TestService_ShareDeck(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		args    deck.ShareRequest
+		when    func() (*deck.Deck, error)
+		want    *deck.ShareResponse
+		wantErr bool
+	}{
+		{
+			name: "share read scope test",
+			args: deck.ShareRequest{DeckId: uuid.NewString(), Caller: "alice", Scope: deck.ScopeRead, MaxCards: 3},
+			when: func() (*deck.Deck, error) {
+				return deck.NewBuilder().Owner("alice").Build()
+			},
+			want:    &deck.ShareResponse{Scope: string(deck.ScopeRead)},
+			wantErr: false,
+		},
+		{
+			name: "share draw scope with limit test",
+			args: deck.ShareRequest{DeckId: uuid.NewString(), Caller: "alice", Scope: deck.ScopeDraw, MaxCards: 3},
+			when: func() (*deck.Deck, error) {
+				return deck.NewBuilder().Owner("alice").Build()
+			},
+			want:    &deck.ShareResponse{Scope: string(deck.ScopeDraw), MaxCards: 3},
+			wantErr: false,
+		},
+		{
+			name: "share deck owned by another subject test",
+			args: deck.ShareRequest{DeckId: uuid.NewString(), Caller: "bob", Scope: deck.ScopeRead},
+			when: func() (*deck.Deck, error) {
+				return deck.NewBuilder().Owner("alice").Build()
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "share invalid scope test",
+			args: deck.ShareRequest{DeckId: uuid.NewString(), Caller: "alice", Scope: "admin"},
+			when: func() (*deck.Deck, error) {
+				return deck.NewBuilder().Owner("alice").Build()
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// mock repo call
+			d, err := tt.when()
+			repoMock := mocks.NewRepo(t)
+			repoMock.On("Get", ctx, mock.Anything).Return(d, err).Maybe()
+
+			// service under test
+			svc := deck.NewService(repoMock).WithGrantSigner(signerStub{})
+
+			actual, err := svc.ShareDeck(ctx, tt.args)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+
+			if err != nil {
+				assert.FailNow(t, err.Error())
+				return
+			}
+
+			assert.NotEmpty(t, actual.Token)
+			assert.Equal(t, d.Id().String(), actual.DeckId)
+			assert.Equal(t, tt.want.Scope, actual.Scope)
+			assert.Equal(t, tt.want.MaxCards, actual.MaxCards)
+			assert.True(t, actual.ExpiresAt.After(time.Now()))
+		})
+	}
+}
+
 // full deck of sequenced cards
 var dtos = []deck.CardDto{
 	{Value: "ACE", Suit: "SPADES", Code: "AS"},
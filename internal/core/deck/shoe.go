@@ -0,0 +1,66 @@
+package deck
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Limits of shoes, casinos deal blackjack and baccarat from shoes of up to 8 decks.
+const (
+	MaxDecks       = 8
+	MaxPenetration = 100
+	// MaxShoeCards is the number of cards of the largest shoe, imports of more cards are rejected.
+	MaxShoeCards = MaxDecks * 52
+)
+
+// ReshuffleCutCard is the policy of a Reshuffle of the whole shoe after its cut card came out.
+const ReshuffleCutCard = "cut_card"
+
+// placeCutCard puts the cut card into the remaining cards, it comes out after the penetration of them is dealt.
+func (d *Deck) placeCutCard() {
+	d.dealt = 0
+	d.cutCard = max(1, d.remaining*d.penetration/100)
+}
+
+// ReshuffleNeeded returns true if the cut card of the shoe came out, the shoe is reshuffled before the next round.
+func (d *Deck) ReshuffleNeeded() bool {
+	return d.penetration > 0 && d.dealt >= d.cutCard
+}
+
+// reshuffleShoe collects all cards of the shoe, the drawn and the discarded ones, shuffles them
+// and places the cut card anew.
+func (d *Deck) reshuffleShoe() *Reshuffle {
+	d.cards, d.discards, d.drawn, d.remaining = nil, nil, nil, 0
+	cards := slices.Clone(d.composition)
+	d.stock(cards)
+	return &Reshuffle{Policy: ReshuffleCutCard, Cards: len(cards)}
+}
+
+// penetrationDto returns the state of the cut card, nil for decks without one.
+func (d *Deck) penetrationDto() *Penetration {
+	if d.penetration == 0 {
+		return nil
+	}
+	return &Penetration{
+		Percent:         d.penetration,
+		CutCard:         d.cutCard,
+		Dealt:           d.dealt,
+		ReshuffleNeeded: d.ReshuffleNeeded(),
+		AutoReshuffle:   d.autoReshuffle,
+	}
+}
+
+// validatePenetration validates the cut card of a new deck.
+func validatePenetration(decks, penetration int, autoReshuffle bool) []FieldError {
+	var invalid []FieldError
+	if decks < 0 || decks > MaxDecks {
+		invalid = append(invalid, FieldError{Field: "decks", Reason: fmt.Sprintf("must be between 0 and %d, 0 for a single deck", MaxDecks)})
+	}
+	if penetration < 0 || penetration > MaxPenetration {
+		invalid = append(invalid, FieldError{Field: "penetration", Reason: fmt.Sprintf("must be between 0 and %d, 0 for no cut card", MaxPenetration)})
+	}
+	if autoReshuffle && penetration == 0 {
+		invalid = append(invalid, FieldError{Field: "auto_reshuffle", Reason: "requires a penetration"})
+	}
+	return invalid
+}
@@ -0,0 +1,50 @@
+package deck
+
+import (
+	"regexp"
+	"time"
+)
+
+// snapshotName matches the names of snapshots, they are part of the snapshot urls.
+var snapshotName = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// Snapshot is a named copy of a deck, taken by its owner to restore the deck later, e.g. after a dealer error.
+type Snapshot struct {
+	Name    string
+	TakenAt time.Time
+	// Deck is the deck when the snapshot was taken. It is shared with the repo, stored decks are never changed.
+	Deck *Deck
+}
+
+// ToDto converts a Snapshot to a SnapshotDto.
+func (s Snapshot) ToDto() SnapshotDto {
+	return SnapshotDto{
+		Name:      s.Name,
+		DeckId:    s.Deck.id.String(),
+		Version:   s.Deck.version,
+		Remaining: s.Deck.remaining,
+		TakenAt:   s.TakenAt,
+	}
+}
+
+// RestoreCommand replaces the state of the deck with the state of the snapshot, including the cards
+// drawn per grant. The id, owner and version are kept, restoring is an operation of its own.
+// Only the owner is allowed to restore a deck.
+type RestoreCommand struct {
+	Snapshot Snapshot
+}
+
+func (c RestoreCommand) Op() string {
+	return "restore"
+}
+
+func (c RestoreCommand) Apply(d *Deck, by Actor) (Effect, error) {
+	if by.Grant != nil || !d.OwnedBy(by.Caller) {
+		return Effect{}, NewSvcError(nil, ErrForbidden)
+	}
+
+	id, owner, version := d.id, d.owner, d.version
+	*d = *c.Snapshot.Deck.clone()
+	d.id, d.owner, d.version = id, owner, version
+	return Effect{}, nil
+}
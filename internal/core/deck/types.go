@@ -1,6 +1,8 @@
 package deck
 
 import (
+	"maps"
+	"slices"
 	"strconv"
 
 	"github.com/google/uuid"
@@ -14,9 +16,10 @@ type Rank string
 
 // Card represents a playing card.
 type Card struct {
-	suit Suit
-	rank Rank
-	code string
+	suit     Suit
+	rank     Rank
+	code     string
+	faceDown bool
 }
 
 // NewCard creates a new card with the given suit and rank.
@@ -43,12 +46,36 @@ func (c Card) Code() string {
 	return c.code
 }
 
+// FaceDown returns true if the card lies face down, its face is hidden from players and spectators.
+func (c Card) FaceDown() bool {
+	return c.faceDown
+}
+
 // Deck represents a deck of cards.
 type Deck struct {
 	id        uuid.UUID
+	owner     string
 	shuffled  bool
+	faceDown  bool
 	remaining int
 	cards     []Card
+	// discards is the discard pile, the last card is on top
+	discards []Card
+	// drawn counts the cards per code that were drawn and not discarded since they were last put into the deck
+	drawn map[string]int
+	// grantDraws counts cards drawn per grant ID
+	grantDraws map[string]int
+	// version counts the operations applied to the deck, it is 0 when the deck is created
+	version int
+	// onEmpty is the policy applied when a draw runs out of cards, composition the cards the deck was created with
+	onEmpty     EmptyPolicy
+	composition []Card
+	// penetration is the percentage of the shoe dealt before its cut card comes out, 0 for decks without one.
+	// cutCard is the number of cards dealt when it comes out, dealt the number dealt since the shoe was shuffled.
+	penetration   int
+	autoReshuffle bool
+	cutCard       int
+	dealt         int
 }
 
 // Id returns the deck ID.
@@ -56,19 +83,65 @@ func (d *Deck) Id() uuid.UUID {
 	return d.id
 }
 
+// Owner returns the subject that owns the deck.
+func (d *Deck) Owner() string {
+	return d.owner
+}
+
+// OwnedBy returns true if the deck has no owner or is owned by the given subject.
+func (d *Deck) OwnedBy(subject string) bool {
+	return d.owner == "" || d.owner == subject
+}
+
 // Shuffled returns true if the deck is shuffled.
 func (d *Deck) Shuffled() bool {
 	return d.shuffled
 }
 
+// FaceDown returns true if the pile was laid face down, its cards are face down unless turned face up.
+func (d *Deck) FaceDown() bool {
+	return d.faceDown
+}
+
 // Remaining returns the number of remaining cards in the deck.
 func (d *Deck) Remaining() int {
 	return d.remaining
 }
 
+// OnEmpty returns the policy applied when a draw runs out of cards.
+func (d *Deck) OnEmpty() EmptyPolicy {
+	return d.onEmpty
+}
+
+// Penetration returns the percentage of the shoe dealt before its cut card comes out, 0 for decks without one.
+func (d *Deck) Penetration() int {
+	return d.penetration
+}
+
+// Version returns the number of operations applied to the deck, see Service.OpenDeck.
+func (d *Deck) Version() int {
+	return d.version
+}
+
+// Discarded returns the number of cards on the discard pile of the deck.
+func (d *Deck) Discarded() int {
+	return len(d.discards)
+}
+
+// clone returns a deep copy of the deck, commands are applied to copies so that failures leave no trace.
+func (d *Deck) clone() *Deck {
+	c := *d
+	c.cards = slices.Clone(d.cards)
+	c.discards = slices.Clone(d.discards)
+	c.drawn = maps.Clone(d.drawn)
+	c.grantDraws = maps.Clone(d.grantDraws)
+	c.composition = slices.Clone(d.composition)
+	return &c
+}
+
 // Equals receiver purpose is to compare two decks with out ID.
 func (d *Deck) Equals(other *Deck) bool {
-	if d.shuffled != other.shuffled {
+	if d.shuffled != other.shuffled || d.faceDown != other.faceDown {
 		return false
 	}
 	if d.remaining != other.remaining {
@@ -123,3 +123,168 @@ func TestBuildNewDeck(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildFrom(t *testing.T) {
+	source, err := deck.NewBuilder().
+		Owner("alice").
+		Cards(deck.ToCards([]string{"AS", "KH", "2D", "10C"})).
+		FaceDown(true).
+		FaceUp(deck.NewCard(deck.Hearts, deck.King)).
+		Build()
+	if err != nil {
+		assert.FailNow(t, err.Error())
+		return
+	}
+	sequenced, _ := deck.NewBuilder().From(source).Build()
+
+	tests := []struct {
+		name    string
+		args    *deck.Builder
+		shuffle bool
+	}{
+		{
+			name: "copy test",
+			args: deck.NewBuilder().From(source),
+		},
+		{
+			name:    "reshuffled copy test",
+			args:    deck.NewBuilder().From(source).Shuffled(true),
+			shuffle: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := tt.args.Build()
+			if err != nil {
+				assert.FailNow(t, err.Error())
+				return
+			}
+
+			assert.NotEqual(t, source.Id(), actual.Id())
+			assert.Equal(t, source.Owner(), actual.Owner())
+			assert.Equal(t, source.Remaining(), actual.Remaining())
+			assert.True(t, actual.FaceDown())
+			assert.Equal(t, tt.shuffle, actual.Shuffled())
+			if !tt.shuffle {
+				assert.True(t, source.Equals(actual), fmt.Sprintf("expected: %v and actual %v are not equal", source, actual))
+			}
+			// the source is not changed by building or shuffling the copy
+			assert.True(t, sequenced.Equals(source), fmt.Sprintf("source %v was changed", source))
+		})
+	}
+}
+
+func TestBuildShoe(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          *deck.Builder
+		wantRemaining int
+	}{
+		{
+			name:          "six deck shoe test",
+			args:          deck.NewBuilder().Decks(6).Penetration(75),
+			wantRemaining: 312,
+		},
+		{
+			name:          "shoe of partial decks test",
+			args:          deck.NewBuilder().Cards(deck.ToCards([]string{"AS", "KH"})).Decks(2).Penetration(50),
+			wantRemaining: 4,
+		},
+		{
+			name:          "single deck with cut card test",
+			args:          deck.NewBuilder().Cards(deck.ToCards([]string{"AS"})).Penetration(10),
+			wantRemaining: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := tt.args.Build()
+			if err != nil {
+				assert.FailNow(t, err.Error())
+				return
+			}
+
+			assert.Equal(t, tt.wantRemaining, actual.Remaining())
+			assert.False(t, actual.ReshuffleNeeded())
+			opened := actual.Export()
+			assert.Len(t, opened.Cards, tt.wantRemaining)
+			assert.Equal(t, opened.Cards[0], opened.Cards[tt.wantRemaining/2], "the decks of the shoe are repeated")
+
+			copied, _ := deck.NewBuilder().From(actual).Build()
+			assert.Equal(t, actual.Penetration(), copied.Penetration())
+		})
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	source, err := deck.NewBuilder().
+		Owner("alice").
+		Cards(deck.ToCards([]string{"AS", "KH", "2D"})).
+		FaceDown(true).
+		FaceUp(deck.NewCard(deck.Hearts, deck.King)).
+		Build()
+	if err != nil {
+		assert.FailNow(t, err.Error())
+		return
+	}
+
+	tests := []struct {
+		name       string
+		given      func() deck.DeckExport
+		wantFields []deck.FieldError
+	}{
+		{
+			name:  "round trip test",
+			given: source.Export,
+		},
+		{
+			name: "empty deck test",
+			given: func() deck.DeckExport {
+				e := source.Export()
+				e.Cards = []deck.CardDto{}
+				return e
+			},
+		},
+		{
+			name: "invalid export test",
+			given: func() deck.DeckExport {
+				e := source.Export()
+				e.Format = "cards"
+				e.Version = 2
+				e.Id = "not-a-uuid"
+				e.Discards = []deck.CardDto{{Code: "XX"}}
+				return e
+			},
+			wantFields: []deck.FieldError{
+				{Field: "format", Reason: `must be "toggl-card-game/deck"`},
+				{Field: "version", Reason: "unsupported version 2, expected 1"},
+				{Field: "id", Reason: "must be a UUID"},
+				{Field: "discards[0].code", Reason: `is not a card code: "XX"`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			given := tt.given()
+			actual, err := deck.Import(given, "bob")
+			if tt.wantFields != nil {
+				var svcErr deck.SvcError
+				assert.ErrorAs(t, err, &svcErr)
+				assert.Equal(t, tt.wantFields, svcErr.Fields)
+				return
+			}
+			if err != nil {
+				assert.FailNow(t, err.Error())
+				return
+			}
+
+			assert.Equal(t, source.Id(), actual.Id())
+			assert.Equal(t, "bob", actual.Owner())
+			assert.Equal(t, len(given.Cards), actual.Remaining())
+			assert.Equal(t, given, actual.Export())
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package deck
+
+// Role is the role of a caller at the table of a deck, it decides which faces of the cards the caller sees.
+type Role string
+
+const (
+	// RoleOwner owns the deck and sees the faces of all cards.
+	RoleOwner Role = "owner"
+	// RolePlayer holds a draw grant, it sees the faces of face up cards and the backs of face down cards.
+	RolePlayer Role = "player"
+	// RoleSpectator holds a read grant, it sees the faces of face up cards and only the number of face down cards.
+	RoleSpectator Role = "spectator"
+)
+
+// roleOf returns the role of the caller or false when the caller may not open the deck.
+// A grant decides the role of its holder, so that a shared token never reveals more than its scope.
+func (d *Deck) roleOf(caller string, grant *Grant) (Role, bool) {
+	switch {
+	case grant.Permits(d.id, ScopeDraw):
+		return RolePlayer, true
+	case grant.Permits(d.id, ScopeRead):
+		return RoleSpectator, true
+	case grant == nil && d.OwnedBy(caller):
+		return RoleOwner, true
+	}
+	return "", false
+}
+
+// faceDownWithoutOwner rejects face down cards of a deck without an owner. Every caller without a grant
+// owns such a deck and would see the faces of its face down cards.
+func faceDownWithoutOwner() FieldError {
+	return FieldError{Field: "face_down", Reason: "requires an authenticated owner, every caller sees the faces of a deck without one"}
+}
+
+// view returns the cards as seen in the given role and the number of cards whose faces are hidden.
+// Owners see face down cards marked as such, players see their backs and spectators do not see them at all.
+func view(cards []Card, role Role) ([]CardDto, int) {
+	dtos := make([]CardDto, 0, len(cards))
+	hidden := 0
+	for _, c := range cards {
+		switch {
+		case !c.faceDown:
+			dtos = append(dtos, c.ToDto())
+		case role == RoleOwner:
+			dto := c.ToDto()
+			dto.FaceDown = true
+			dtos = append(dtos, dto)
+		case role == RolePlayer:
+			hidden++
+			dtos = append(dtos, CardDto{FaceDown: true})
+		default:
+			hidden++
+		}
+	}
+	return dtos, hidden
+}
@@ -0,0 +1,52 @@
+// Package docs serves the OpenAPI document of the api and a documentation page rendering it.
+// The page is self-contained, it loads no third-party code and works offline.
+package docs
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"encoding/base64"
+	"net/http"
+	"strings"
+)
+
+// OpenAPI is the hand-maintained OpenAPI 3 document of the api.
+//
+//go:embed openapi.json
+var OpenAPI []byte
+
+//go:embed index.html
+var index string
+
+// script renders the document, it is inlined into the page.
+//
+//go:embed docs.js
+var script string
+
+var (
+	page = []byte(strings.Replace(index, "{{script}}", script, 1))
+	// policy allows only the inlined script and requests to the server itself
+	policy = "default-src 'none'; style-src 'unsafe-inline'; connect-src 'self'; script-src 'sha256-" + hash(script) + "'"
+)
+
+func hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// SpecHandler serves the OpenAPI document.
+func SpecHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(OpenAPI)
+	}
+}
+
+// PageHandler serves the documentation page.
+func PageHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Security-Policy", policy)
+		w.Write(page)
+	}
+}
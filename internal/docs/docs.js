@@ -0,0 +1,90 @@
+// Renders the OpenAPI document of the api without third-party code, the page works offline.
+(async () => {
+  const root = document.getElementById("docs");
+  const el = (tag, text, className) => {
+    const e = document.createElement(tag);
+    if (text !== undefined) e.textContent = text;
+    if (className) e.className = className;
+    return e;
+  };
+
+  let doc;
+  try {
+    doc = await (await fetch("/openapi.json")).json();
+  } catch (err) {
+    root.append(el("p", "Unable to load /openapi.json: " + err));
+    return;
+  }
+
+  // resolve follows local references, e.g. #/components/parameters/FaceDown
+  const resolve = (obj) => {
+    while (obj && obj.$ref) {
+      obj = obj.$ref.slice(2).split("/").reduce((o, key) => o[key], doc);
+    }
+    return obj;
+  };
+  const refName = (obj) => (obj && obj.$ref ? obj.$ref.split("/").pop() : "");
+  const schemaText = (schema) => {
+    if (!schema) return "";
+    if (schema.$ref) return refName(schema);
+    if (schema.type === "array") return schemaText(schema.items) + "[]";
+    return (schema.type || "") + (schema.enum ? " (" + schema.enum.join(", ") + ")" : "");
+  };
+  const table = (headers, rows) => {
+    const t = el("table");
+    const head = el("tr");
+    headers.forEach((h) => head.append(el("th", h)));
+    t.append(head);
+    rows.forEach((row) => {
+      const tr = el("tr");
+      row.forEach((cell) => tr.append(el("td", cell)));
+      t.append(tr);
+    });
+    return t;
+  };
+
+  root.append(el("h1", doc.info.title + " " + doc.info.version));
+  root.append(el("p", doc.info.description));
+  const raw = el("a", "Raw OpenAPI document");
+  raw.href = "/openapi.json";
+  root.append(raw);
+
+  for (const [path, ops] of Object.entries(doc.paths)) {
+    for (const [method, op] of Object.entries(ops)) {
+      const section = el("details", undefined, "operation");
+      const summary = el("summary");
+      summary.append(el("span", method.toUpperCase(), "method " + method), el("code", path), el("span", op.summary || ""));
+      section.append(summary);
+      if (op.description) section.append(el("p", op.description));
+
+      const params = (op.parameters || []).map(resolve);
+      if (params.length) {
+        section.append(el("h4", "Parameters"));
+        section.append(table(["Name", "In", "Type", "Description"],
+          params.map((p) => [p.name + (p.required ? " *" : ""), p.in, schemaText(p.schema), p.description || ""])));
+      }
+      const body = op.requestBody && resolve(op.requestBody);
+      if (body) {
+        section.append(el("h4", "Request body"));
+        section.append(table(["Media type", "Schema"],
+          Object.entries(body.content || {}).map(([type, media]) => [type, schemaText(media.schema)])));
+      }
+      section.append(el("h4", "Responses"));
+      section.append(table(["Status", "Description"],
+        Object.entries(op.responses || {}).map(([status, res]) => [status, resolve(res).description || ""])));
+      root.append(section);
+    }
+  }
+
+  root.append(el("h2", "Schemas"));
+  for (const [name, schema] of Object.entries(doc.components.schemas)) {
+    const section = el("details", undefined, "schema");
+    section.append(el("summary", name));
+    if (schema.description) section.append(el("p", schema.description));
+    const required = schema.required || [];
+    section.append(table(["Property", "Type", "Description"],
+      Object.entries(schema.properties || {}).map(([prop, s]) =>
+        [prop + (required.includes(prop) ? " *" : ""), schemaText(s), (resolve(s) || {}).description || s.description || ""])));
+    root.append(section);
+  }
+})();
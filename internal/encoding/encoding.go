@@ -0,0 +1,181 @@
+// Package encoding encodes api responses in the media type negotiated with the Accept header.
+package encoding
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrNotEncodable is returned by encoders that do not support the type of a value, e.g. CSV for a deck without cards.
+	ErrNotEncodable = errors.New("value cannot be encoded in this media type")
+	// ErrNotAcceptable is returned when no acceptable media type can encode a value.
+	ErrNotAcceptable = errors.New("none of the accepted media types can represent the response")
+)
+
+// Encoder encodes values in a media type.
+type Encoder interface {
+	Encode(w io.Writer, v any) error
+}
+
+// EncoderFunc is an adapter to use ordinary functions as encoders.
+type EncoderFunc func(w io.Writer, v any) error
+
+// Encode calls f(w, v).
+func (f EncoderFunc) Encode(w io.Writer, v any) error {
+	return f(w, v)
+}
+
+// Registry maps media types to encoders.
+// The first registered media type is preferred when the client accepts any type.
+type Registry struct {
+	mu       sync.RWMutex
+	types    []string
+	encoders map[string]Encoder
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{encoders: make(map[string]Encoder)}
+}
+
+// Register registers the encoder of a media type, e.g. application/xml, replacing any previous one.
+func (r *Registry) Register(mediaType string, enc Encoder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mediaType = strings.ToLower(mediaType)
+	if _, ok := r.encoders[mediaType]; !ok {
+		r.types = append(r.types, mediaType)
+	}
+	r.encoders[mediaType] = enc
+}
+
+// Encode encodes v in the most preferred media type of the accept header that can represent it.
+// It returns the content type and the encoded body, or ErrNotAcceptable.
+func (r *Registry) Encode(accept string, v any) (string, []byte, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ranges, excluded := parseAccept(accept)
+	tried := make(map[string]bool)
+	for _, rng := range ranges {
+		for _, mediaType := range r.types {
+			if tried[mediaType] || excluded[mediaType] || !rng.matches(mediaType) {
+				continue
+			}
+			tried[mediaType] = true
+
+			buf := new(bytes.Buffer)
+			if err := r.encoders[mediaType].Encode(buf, v); err != nil {
+				// the next acceptable media type may be able to represent the value
+				continue
+			}
+			return contentType(mediaType), buf.Bytes(), nil
+		}
+	}
+
+	return "", nil, ErrNotAcceptable
+}
+
+// contentType adds the charset to textual media types.
+func contentType(mediaType string) string {
+	if strings.HasPrefix(mediaType, "text/") {
+		return mediaType + "; charset=utf-8"
+	}
+	return mediaType
+}
+
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+func (m mediaRange) matches(mediaType string) bool {
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	return (m.typ == "*" || m.typ == typ) && (m.subtype == "*" || m.subtype == subtype)
+}
+
+// parseAccept parses an Accept header into media ranges ordered by preference
+// and the media types explicitly excluded with q=0. An empty header accepts anything.
+func parseAccept(accept string) ([]mediaRange, map[string]bool) {
+	if strings.TrimSpace(accept) == "" {
+		accept = "*/*"
+	}
+
+	var ranges []mediaRange
+	excluded := make(map[string]bool)
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			// mime rejects the bare "*" sent by some clients
+			if strings.TrimSpace(part) != "*" {
+				continue
+			}
+			mediaType = "*/*"
+		}
+
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(s, 64); err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+		if q == 0 {
+			excluded[mediaType] = true
+			continue
+		}
+
+		typ, subtype, _ := strings.Cut(mediaType, "/")
+		ranges = append(ranges, mediaRange{typ: typ, subtype: subtype, q: q})
+	}
+
+	// more specific ranges win over wildcards of the same quality
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].q != ranges[j].q {
+			return ranges[i].q > ranges[j].q
+		}
+		return specificity(ranges[i]) > specificity(ranges[j])
+	})
+	return ranges, excluded
+}
+
+func specificity(m mediaRange) int {
+	switch {
+	case m.typ == "*":
+		return 0
+	case m.subtype == "*":
+		return 1
+	}
+	return 2
+}
+
+// Default is the registry used by the http handlers.
+var Default = NewRegistry()
+
+// Register registers the encoder of a media type in the default registry.
+func Register(mediaType string, enc Encoder) {
+	Default.Register(mediaType, enc)
+}
+
+func init() {
+	Register("application/json", JSON)
+	Register("application/xml", XML)
+	Register("text/xml", XML)
+	Register("application/msgpack", MessagePack)
+	Register("application/x-msgpack", MessagePack)
+	Register("application/vnd.msgpack", MessagePack)
+	Register("text/csv", CSV)
+	Register("text/plain", Text)
+}
+
+func errNotEncodable(v any) error {
+	return fmt.Errorf("%w: %T", ErrNotEncodable, v)
+}
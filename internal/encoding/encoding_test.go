@@ -0,0 +1,164 @@
+package encoding_test
+
+import (
+	"testing"
+	"time"
+	"toggl-card-game/api"
+	"toggl-card-game/internal/encoding"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var openRes = &api.OpenResponse{
+	DeckId:    "d1",
+	Shuffled:  false,
+	Remaining: 2,
+	Cards:     []api.Card{{Value: "ACE", Suit: "SPADES", Code: "AS"}, {Value: "10", Suit: "HEARTS", Code: "10H"}},
+}
+
+func TestRegistry_Encode(t *testing.T) {
+	tests := []struct {
+		name            string
+		accept          string
+		value           any
+		wantContentType string
+		wantBody        string
+		wantErr         error
+	}{
+		{
+			name:            "empty accept defaults to json test",
+			value:           api.CreateResponse{DeckId: "d1", Remaining: 52},
+			wantContentType: "application/json",
+			wantBody:        `{"deck_id":"d1","shuffled":false,"remaining":52}`,
+		},
+		{
+			name:            "xml test",
+			accept:          "application/xml",
+			value:           api.CreateResponse{DeckId: "d1", Remaining: 52},
+			wantContentType: "application/xml",
+			wantBody:        "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<CreateResponse><deck_id>d1</deck_id><shuffled>false</shuffled><remaining>52</remaining></CreateResponse>",
+		},
+		{
+			name:            "xml cards test",
+			accept:          "text/xml",
+			value:           api.DrawResponse{Cards: openRes.Cards[:1]},
+			wantContentType: "text/xml; charset=utf-8",
+			wantBody:        "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<DrawResponse><cards><card><value>ACE</value><suit>SPADES</suit><code>AS</code></card></cards></DrawResponse>",
+		},
+		{
+			name:            "csv cards test",
+			accept:          "text/csv",
+			value:           openRes,
+			wantContentType: "text/csv; charset=utf-8",
+			wantBody:        "value,suit,code\nACE,SPADES,AS\n10,HEARTS,10H\n",
+		},
+		{
+			name:    "csv without cards is not acceptable test",
+			accept:  "text/csv",
+			value:   api.CreateResponse{DeckId: "d1"},
+			wantErr: encoding.ErrNotAcceptable,
+		},
+		{
+			name:            "csv falls back to the next acceptable type test",
+			accept:          "text/csv, application/json;q=0.5",
+			value:           api.CreateResponse{DeckId: "d1"},
+			wantContentType: "application/json",
+			wantBody:        `{"deck_id":"d1","shuffled":false,"remaining":0}`,
+		},
+		{
+			name:            "text test",
+			accept:          "text/plain",
+			value:           openRes,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "deck_id: d1\nshuffled: false\nremaining: 2\nversion: 0\ncards: A♠ 10♥\n",
+		},
+		{
+			name:            "quality order test",
+			accept:          "application/json;q=0.4, application/xml;q=0.9",
+			value:           api.ShuffleResponse{},
+			wantContentType: "application/xml",
+			wantBody:        "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<ShuffleResponse><deck_id></deck_id><shuffled>false</shuffled><remaining>0</remaining></ShuffleResponse>",
+		},
+		{
+			name:            "wildcard prefers json test",
+			accept:          "text/*;q=0.1, */*",
+			value:           api.ShuffleResponse{},
+			wantContentType: "application/json",
+			wantBody:        `{"deck_id":"","shuffled":false,"remaining":0}`,
+		},
+		{
+			name:    "excluded type test",
+			accept:  "application/json;q=0, application/pdf",
+			value:   api.ShuffleResponse{},
+			wantErr: encoding.ErrNotAcceptable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contentType, body, err := encoding.Default.Encode(tt.accept, tt.value)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantContentType, contentType)
+			if tt.wantErr == nil {
+				assert.Equal(t, tt.wantBody, string(body))
+			}
+		})
+	}
+}
+
+type named struct {
+	Name string `json:"name"`
+}
+
+type embedding struct {
+	named
+	*api.Card
+	Count int    `json:"count"`
+	Code  string `json:"code"`
+}
+
+type omitting struct {
+	Cards []int          `json:"cards,omitempty"`
+	Tags  map[string]int `json:"tags,omitempty"`
+	At    time.Time      `json:"at,omitempty"`
+}
+
+func TestMessagePack(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  []byte
+	}{
+		{"nil test", nil, []byte{0xc0}},
+		{"small ints test", []int{0, 127, -1, -32}, []byte{0x94, 0x00, 0x7f, 0xff, 0xe0}},
+		{"large ints test", []int64{200, -200, 70000}, []byte{0x93, 0xcc, 0xc8, 0xd1, 0xff, 0x38, 0xce, 0x00, 0x01, 0x11, 0x70}},
+		{"card test", api.Card{Value: "ACE", Suit: "SPADES", Code: "AS"}, append(append(append(
+			[]byte{0x83, 0xa5, 'v', 'a', 'l', 'u', 'e', 0xa3, 'A', 'C', 'E'},
+			0xa4, 's', 'u', 'i', 't', 0xa6, 'S', 'P', 'A', 'D', 'E', 'S'),
+			0xa4, 'c', 'o', 'd', 'e'), 0xa2, 'A', 'S')},
+		{"omitempty and time test", api.ShareResponse{ExpiresAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}, append(append(append(append(
+			[]byte{0x84, 0xa5, 't', 'o', 'k', 'e', 'n', 0xa0},
+			0xa7, 'd', 'e', 'c', 'k', '_', 'i', 'd', 0xa0),
+			0xa5, 's', 'c', 'o', 'p', 'e', 0xa0),
+			0xaa, 'e', 'x', 'p', 'i', 'r', 'e', 's', '_', 'a', 't'),
+			append([]byte{0xb4}, "2024-01-02T03:04:05Z"...)...)},
+		{"embedded struct test", embedding{named: named{Name: "a"}, Card: &api.Card{Suit: "S", Code: "AS"}, Count: 1, Code: "KS"},
+			[]byte{0x84, 0xa4, 'n', 'a', 'm', 'e', 0xa1, 'a', 0xa4, 's', 'u', 'i', 't', 0xa1, 'S',
+				0xa5, 'c', 'o', 'u', 'n', 't', 0x01, 0xa4, 'c', 'o', 'd', 'e', 0xa2, 'K', 'S'}},
+		{"nil embedded struct test", embedding{Count: 1}, []byte{0x83, 0xa4, 'n', 'a', 'm', 'e', 0xa0,
+			0xa5, 'c', 'o', 'u', 'n', 't', 0x01, 0xa4, 'c', 'o', 'd', 'e', 0xa0}},
+		{"omitempty empty slice and map test", omitting{Cards: []int{}, Tags: map[string]int{}},
+			append([]byte{0x81, 0xa2, 'a', 't', 0xb4}, "0001-01-01T00:00:00Z"...)},
+		{"bool and float test", map[string]any{"a": true, "b": 1.5}, []byte{0x82, 0xa1, 'a', 0xc3, 0xa1, 'b', 0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, body, err := encoding.Default.Encode("application/msgpack", tt.value)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, body)
+		})
+	}
+}
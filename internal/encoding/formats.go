@@ -0,0 +1,232 @@
+package encoding
+
+import (
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"toggl-card-game/api"
+	"toggl-card-game/internal/render"
+)
+
+// JSON encodes values as JSON.
+var JSON = EncoderFunc(func(w io.Writer, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+})
+
+// XML encodes structs as XML documents, the root element is named after the type.
+var XML = EncoderFunc(func(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(v)
+})
+
+// CSV encodes card lists, and values holding a card list, as CSV with a value,suit,code header.
+var CSV = EncoderFunc(func(w io.Writer, v any) error {
+	cards, ok := cardsOf(v)
+	if !ok {
+		return errNotEncodable(v)
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"value", "suit", "code"})
+	for _, c := range cards {
+		cw.Write([]string{c.Value, c.Suit, c.Code})
+	}
+	cw.Flush()
+	return cw.Error()
+})
+
+// Text encodes values as "name: value" lines, cards are rendered as rank and suit symbol, e.g. A♠.
+var Text = EncoderFunc(func(w io.Writer, v any) error {
+	if cards, ok := v.([]api.Card); ok {
+		_, err := fmt.Fprintln(w, shortCards(cards))
+		return err
+	}
+
+	rv := indirect(reflect.ValueOf(v))
+	var lines []string
+	switch rv.Kind() {
+	case reflect.Struct:
+		for _, f := range structFields(rv) {
+			lines = append(lines, f.name+": "+textValue(f.value))
+		}
+	case reflect.Map:
+		for _, k := range rv.MapKeys() {
+			lines = append(lines, fmt.Sprint(k.Interface())+": "+textValue(rv.MapIndex(k)))
+		}
+		sort.Strings(lines)
+	case reflect.Invalid:
+	default:
+		lines = append(lines, textValue(rv))
+	}
+
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+})
+
+func textValue(v reflect.Value) string {
+	v = indirect(v)
+	if !v.IsValid() {
+		return ""
+	}
+	switch x := v.Interface().(type) {
+	case []api.Card:
+		return shortCards(x)
+	case encoding.TextMarshaler:
+		b, err := x.MarshalText()
+		if err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func shortCards(cards []api.Card) string {
+	s := make([]string, len(cards))
+	for i, c := range cards {
+		s[i] = render.Short(c)
+	}
+	return strings.Join(s, " ")
+}
+
+var cardsType = reflect.TypeOf([]api.Card(nil))
+
+// cardsOf returns the cards of a card list or of the first card list field of a struct.
+func cardsOf(v any) ([]api.Card, bool) {
+	rv := indirect(reflect.ValueOf(v))
+	if !rv.IsValid() {
+		return nil, false
+	}
+	if rv.Type() == cardsType {
+		return rv.Interface().([]api.Card), true
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, false
+	}
+	for i := 0; i < rv.NumField(); i++ {
+		if rv.Type().Field(i).IsExported() && rv.Field(i).Type() == cardsType {
+			return rv.Field(i).Interface().([]api.Card), true
+		}
+	}
+	return nil, false
+}
+
+// field is a struct field as encoded by encoding/json.
+type field struct {
+	name   string
+	value  reflect.Value
+	depth  int
+	tagged bool
+}
+
+// structFields returns the fields of a struct the way encoding/json encodes them: the fields of embedded
+// structs are promoted, a shallower or tagged field hides the others of its name and omitempty fields
+// with empty values are left out.
+func structFields(v reflect.Value) []field {
+	var fields []field
+	collectFields(v, 0, &fields)
+
+	byName := map[string][]int{}
+	for i, f := range fields {
+		byName[f.name] = append(byName[f.name], i)
+	}
+	var dominant []field
+	for i, f := range fields {
+		if dominantField(fields, byName[f.name]) == i {
+			dominant = append(dominant, f)
+		}
+	}
+	return dominant
+}
+
+func collectFields(v reflect.Value, depth int, fields *[]field) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf, fv := t.Field(i), v.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if fv.Kind() == reflect.Pointer {
+					if fv.IsNil() {
+						continue
+					}
+					fv = fv.Elem()
+				}
+				collectFields(fv, depth+1, fields)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if strings.Contains(","+opts+",", ",omitempty,") && isEmptyValue(fv) {
+			continue
+		}
+		tagged := name != ""
+		if !tagged {
+			name = sf.Name
+		}
+		*fields = append(*fields, field{name: name, value: fv, depth: depth, tagged: tagged})
+	}
+}
+
+// dominantField returns the index of the field that wins among fields of the same name, -1 if none does.
+func dominantField(fields []field, idx []int) int {
+	best, conflict := idx[0], false
+	for _, i := range idx[1:] {
+		f, b := fields[i], fields[best]
+		switch {
+		case f.depth < b.depth || (f.depth == b.depth && f.tagged && !b.tagged):
+			best, conflict = i, false
+		case f.depth == b.depth && f.tagged == b.tagged:
+			conflict = true
+		}
+	}
+	if conflict {
+		return -1
+	}
+	return best
+}
+
+// isEmptyValue reports whether v is empty as defined by the omitempty option of encoding/json.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
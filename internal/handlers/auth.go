@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+	"toggl-card-game/internal/auth"
+)
+
+// Authenticate is a middleware that rejects requests without valid credentials
+// and stores the authenticated principal in the request context.
+func Authenticate(authn *auth.Authenticator) Middleware {
+	return func(next http.Handler) http.Handler {
+		return MakeHandler(func(w http.ResponseWriter, r *http.Request) error {
+			p, err := authn.Authenticate(r)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+				return problemUnauthorized.with(err.Error())
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), p)))
+			return nil
+		})
+	}
+}
+
+// subject returns the subject of the authenticated principal or an empty string.
+func subject(r *http.Request) string {
+	p, _ := auth.FromContext(r.Context())
+	return p.Subject
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"toggl-card-game/internal/auth"
+	"toggl-card-game/internal/core/deck"
+)
+
+type grantKey struct{}
+
+// Capability is a middleware that accepts capability tokens for the given scopes.
+// The token is read from the X-Capability-Token header or the token query parameter.
+// Requests without a capability token are passed to the fallback middleware, usually Authenticate.
+func Capability(signer *auth.Signer, fallback Middleware, scopes ...deck.Scope) Middleware {
+	return func(next http.Handler) http.Handler {
+		guarded := fallback(next)
+
+		return MakeHandler(func(w http.ResponseWriter, r *http.Request) error {
+			token := r.Header.Get("X-Capability-Token")
+			if token == "" {
+				token = r.URL.Query().Get("token")
+			}
+			if token == "" {
+				guarded.ServeHTTP(w, r)
+				return nil
+			}
+
+			grant, err := signer.VerifyGrant(token)
+			if err != nil {
+				return problemUnauthorized.with(err.Error())
+			}
+			if !slices.Contains(scopes, grant.Scope) {
+				return catalogued(deck.ErrForbidden, "capability token does not permit this operation")
+			}
+			if id := r.PathValue("UUID"); id != "" && id != grant.DeckId {
+				return catalogued(deck.ErrForbidden, "capability token is not valid for this deck")
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), grantKey{}, &grant)))
+			return nil
+		})
+	}
+}
+
+// grant returns the grant of a verified capability token or nil.
+func grant(r *http.Request) *deck.Grant {
+	g, _ := r.Context().Value(grantKey{}).(*deck.Grant)
+	return g
+}
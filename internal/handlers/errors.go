@@ -1,27 +1,136 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"toggl-card-game/api"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/encoding"
 )
 
-// ApiError represents a custom error struct that contains error and http code.
-type ApiError struct {
-	Err  string `json:"errorMessage"`
-	Code int    `json:"httpCode"`
-}
+// ApiError is an error response in the RFC 7807 problem details format.
+type ApiError = api.Problem
 
-func NewApiError(err string, code int) ApiError {
+// NewApiError creates a problem without a specific type, its title is the text of the status code.
+func NewApiError(detail string, status int) ApiError {
 	return ApiError{
-		Err:  err,
-		Code: code,
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
 	}
 }
 
-func (x ApiError) Error() string {
-	data, err := json.Marshal(x)
-	if err != nil {
-		return "{error: " + x.Err + ", code: " + fmt.Sprint(x.Code) + "}"
+// ProblemType returns the type of the problems with the given error code, e.g. /problems/deck-not-found.
+func ProblemType(code string) string {
+	return "/problems/" + code
+}
+
+// problem is an entry of the catalogue of errors raised by the http layer,
+// errors of the deck service are listed in the deck error catalogue.
+type problem struct {
+	code   string
+	title  string
+	status int
+}
+
+var (
+	problemUnauthorized  = problem{code: "unauthorized", title: "credentials are missing or invalid", status: http.StatusUnauthorized}
+	problemRateLimited   = problem{code: "rate-limited", title: "rate limit exceeded", status: http.StatusTooManyRequests}
+	problemNotAcceptable = problem{code: "not-acceptable", title: "none of the accepted media types can represent the response", status: http.StatusNotAcceptable}
+	problemNotReady      = problem{code: "not-ready", title: "server is not ready", status: http.StatusServiceUnavailable}
+	problemBodyTooLarge  = problem{code: "request-too-large", title: "request body is too large", status: http.StatusRequestEntityTooLarge}
+
+	problemIdempotencyInFlight = problem{code: "idempotency-key-in-flight", title: "a request with the idempotency key is in flight", status: http.StatusConflict}
+	problemIdempotencyMismatch = problem{code: "idempotency-key-reused", title: "idempotency key was used for a different request", status: http.StatusUnprocessableEntity}
+)
+
+// Problems lists the codes of all problem types the api responds with.
+func Problems() []string {
+	codes := []string{problemUnauthorized.code, problemRateLimited.code, problemNotAcceptable.code, problemNotReady.code,
+		problemBodyTooLarge.code, problemIdempotencyInFlight.code, problemIdempotencyMismatch.code}
+	for _, e := range deck.Catalogue {
+		codes = append(codes, e.Code)
 	}
-	return string(data)
+	return codes
+}
+
+func (p problem) with(detail string) ApiError {
+	return ApiError{Type: ProblemType(p.code), Title: p.title, Status: p.status, Detail: detail}
+}
+
+// statuses maps the kinds of deck errors to http status codes.
+var statuses = map[deck.Kind]int{
+	deck.KindInternal:  http.StatusInternalServerError,
+	deck.KindInvalid:   http.StatusBadRequest,
+	deck.KindNotFound:  http.StatusNotFound,
+	deck.KindForbidden: http.StatusForbidden,
+	deck.KindConflict:  http.StatusConflict,
+}
+
+// catalogued converts a deck error catalogue entry into a problem.
+func catalogued(e *deck.Error, detail string) ApiError {
+	return ApiError{Type: ProblemType(e.Code), Title: e.Title, Status: statuses[e.Kind], Detail: detail}
+}
+
+// toApiError converts any error into a problem. Internal errors are logged and never sent to clients.
+func toApiError(ctx context.Context, err error) ApiError {
+	var apiErr ApiError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var svcErr deck.SvcError
+	if errors.As(err, &svcErr) {
+		e := svcErr.Catalogued()
+		if e.Kind == deck.KindInternal || svcErr.InternalErr != nil {
+			slog.Log(ctx, levelOf(e.Kind), "service error", "request_id", RequestIDFrom(ctx), "code", e.Code, "error", svcErr.InternalErr)
+		}
+		apiErr = catalogued(e, svcErr.Detail)
+		apiErr.Errors = svcErr.Fields
+		return apiErr
+	}
+
+	slog.ErrorContext(ctx, "unhandled error", "request_id", RequestIDFrom(ctx), "error", err)
+	return catalogued(deck.ErrInternal, "")
+}
+
+func levelOf(kind deck.Kind) slog.Level {
+	if kind == deck.KindInternal {
+		return slog.LevelError
+	}
+	return slog.LevelDebug
+}
+
+// problemEncoders encode problems as JSON or XML, other media types fall back to JSON.
+var problemEncoders = func() *encoding.Registry {
+	r := encoding.NewRegistry()
+	r.Register("application/problem+json", encoding.JSON)
+	r.Register("application/json", encoding.JSON)
+	r.Register("application/problem+xml", encoding.XML)
+	r.Register("application/xml", encoding.XML)
+	r.Register("text/xml", encoding.XML)
+	return r
+}()
+
+// writeError writes a problem as application/problem+xml when the client prefers XML
+// and as application/problem+json otherwise, so that clients always learn why their request failed.
+func writeError(w http.ResponseWriter, r *http.Request, apiErr ApiError) {
+	apiErr.Instance = r.URL.Path
+	apiErr.RequestId = RequestIDFrom(r.Context())
+
+	contentType, b, err := problemEncoders.Encode(r.Header.Get("Accept"), apiErr)
+	if err != nil || !strings.Contains(contentType, "xml") {
+		contentType = "application/problem+json"
+		b, _ = json.Marshal(apiErr)
+	} else {
+		contentType = "application/problem+xml"
+	}
+
+	w.Header().Add("Vary", "Accept")
+	writeBody(w, apiErr.Status, contentType, b)
 }
@@ -2,11 +2,15 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
 	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/encoding"
 
 	"github.com/google/uuid"
 )
@@ -15,15 +19,11 @@ import (
 type MyHandlerFunc func(http.ResponseWriter, *http.Request) error
 
 // MakeHandler decorates a custom handler function.
+// Returned errors are written as problem details, see toApiError.
 func MakeHandler(fn MyHandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if err := fn(w, r); err != nil {
-			switch e := err.(type) {
-			case ApiError:
-				writeJson(w, e.Code, e)
-			default:
-				writeJson(w, http.StatusInternalServerError, map[string]string{"errorMessage": err.Error(), "httpCode": "500"})
-			}
+			writeError(w, r, toApiError(r.Context(), err))
 		}
 	})
 }
@@ -47,81 +47,353 @@ func writeJson(w http.ResponseWriter, code int, data interface{}) error {
 	return nil
 }
 
+// write writes data in the media type negotiated with the Accept header of the request.
+// It returns a not-acceptable problem when no acceptable media type can represent the data.
+func write(w http.ResponseWriter, r *http.Request, code int, data interface{}) error {
+	contentType, b, err := encoding.Default.Encode(r.Header.Get("Accept"), data)
+	if err != nil {
+		return problemNotAcceptable.with(r.Header.Get("Accept"))
+	}
+	w.Header().Add("Vary", "Accept")
+	return writeBody(w, code, contentType, b)
+}
+
+func writeBody(w http.ResponseWriter, code int, contentType string, b []byte) error {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(code)
+	_, err := w.Write(b)
+	return err
+}
+
 // RequestFunc is a custom request parser function type.
 // It takes an http.Request and returns a input value or an error.
 type RequestParserFunc[In any] func(r *http.Request) (In, error)
 
+// ResponderFunc writes the output of a service function, it chooses the status code and headers of the response.
+type ResponderFunc[Out any] func(w http.ResponseWriter, r *http.Request, out Out) error
+
 // Handle is a generic handler function that takes a target function and returns a custom handler function.
+// The output of the target function is written with 200 OK.
 func Handle[In any, Out any](reqPar RequestParserFunc[In], svcFunc deck.TargetFunc[In, Out]) MyHandlerFunc {
+	return HandleWith(reqPar, svcFunc, OK[Out])
+}
+
+// HandleWith is like Handle but writes the output of the target function with the given responder.
+func HandleWith[In any, Out any](reqPar RequestParserFunc[In], svcFunc deck.TargetFunc[In, Out], respond ResponderFunc[Out]) MyHandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) error {
 		// Parse request
 		in, err := reqPar(r)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
 			return err
 		}
 
 		// Call service function
 		out, err := svcFunc(r.Context(), in)
 		if err != nil {
-			switch e := err.(type) {
-			case deck.SvcError:
-				return ApiError{e.Error(), http.StatusBadRequest}
-			default:
-				return err
-			}
+			return err
 		}
 
-		return writeJson(w, http.StatusOK, out)
+		return respond(w, r, out)
+	}
+}
+
+// OK writes the output with 200 OK.
+func OK[Out any](w http.ResponseWriter, r *http.Request, out Out) error {
+	return write(w, r, http.StatusOK, out)
+}
+
+// Created returns a responder that writes the output with 201 Created
+// and the Location of the created resource.
+func Created[Out any](location func(r *http.Request, out Out) string) ResponderFunc[Out] {
+	return func(w http.ResponseWriter, r *http.Request, out Out) error {
+		w.Header().Set("Location", location(r, out))
+		return write(w, r, http.StatusCreated, out)
 	}
 }
 
 func ParseCreateRequest(r *http.Request) (deck.CreateRequest, error) {
-	var req deck.CreateRequest
+	return parseCreateRequest(r, false)
+}
+
+// CreateRequestParser returns a create request parser that uses the given value
+// when the shuffled query parameter is absent.
+func CreateRequestParser(shuffledByDefault bool) RequestParserFunc[deck.CreateRequest] {
+	return func(r *http.Request) (deck.CreateRequest, error) {
+		return parseCreateRequest(r, shuffledByDefault)
+	}
+}
+
+func parseCreateRequest(r *http.Request, shuffledByDefault bool) (deck.CreateRequest, error) {
+	req := deck.CreateRequest{Shuffled: shuffledByDefault}
 
 	// Parse query parameters
 	q := r.URL.Query()
 	if q.Has("cards") {
-		// TODO: validate card codes
-		// codes := strings.Split(q.Get("cards"), ",")
-		// for _, code := range codes {
-		// 	card := deck.CardsMap[code]
-		// 	if card == nil {
-		// 		return req, ApiError{http.StatusBadRequest, "invalid card code"}
-		// 	}
-		// }
+		// card codes are validated by the service
 		req.Cards = strings.Split(q.Get("cards"), ",")
 	}
 
 	if q.Has("shuffled") {
 		shuffled, err := strconv.ParseBool(q.Get("shuffled"))
 		if err != nil {
-			slog.Warn("unable to parse shuffled query parameter, using default false value", "error", err)
-			shuffled = false
+			slog.Warn("unable to parse shuffled query parameter, using default value", "error", err, "default", shuffledByDefault)
+			shuffled = shuffledByDefault
 		}
 		req.Shuffled = shuffled
 	}
 
+	// unlike shuffled, an invalid face_down is rejected rather than exposing the faces of the cards
+	if q.Has("face_down") {
+		faceDown, err := strconv.ParseBool(q.Get("face_down"))
+		if err != nil {
+			return deck.CreateRequest{}, deck.NewValidationError(deck.FieldError{Field: "face_down", Reason: "must be a boolean"})
+		}
+		req.FaceDown = faceDown
+	}
+	if q.Has("face_up") {
+		req.FaceUp = strings.Split(q.Get("face_up"), ",")
+	}
+	// the policy is validated by the service
+	req.OnEmpty = q.Get("on_empty")
+
+	// the shoe and its cut card, their ranges are validated by the service
+	var invalid []deck.FieldError
+	for _, param := range []struct {
+		name string
+		dst  *int
+	}{{"decks", &req.Decks}, {"penetration", &req.Penetration}} {
+		if !q.Has(param.name) {
+			continue
+		}
+		n, err := strconv.Atoi(q.Get(param.name))
+		if err != nil {
+			invalid = append(invalid, deck.FieldError{Field: param.name, Reason: "must be an integer"})
+		}
+		*param.dst = n
+	}
+	if q.Has("auto_reshuffle") {
+		var err error
+		if req.AutoReshuffle, err = strconv.ParseBool(q.Get("auto_reshuffle")); err != nil {
+			invalid = append(invalid, deck.FieldError{Field: "auto_reshuffle", Reason: "must be a boolean"})
+		}
+	}
+	if len(invalid) > 0 {
+		return deck.CreateRequest{}, deck.NewValidationError(invalid...)
+	}
+
+	req.Owner = subject(r)
+
+	return req, nil
+}
+
+// ParseCloneRequest parses a clone of the deck in the path, reshuffle=true reshuffles the cards of the clone.
+func ParseCloneRequest(r *http.Request) (deck.CloneRequest, error) {
+	id, err := pathDeckId(r)
+	if err != nil {
+		return deck.CloneRequest{}, err
+	}
+	req := deck.CloneRequest{DeckId: id, Caller: subject(r)}
+
+	if q := r.URL.Query(); q.Has("reshuffle") {
+		req.Reshuffle, err = strconv.ParseBool(q.Get("reshuffle"))
+		if err != nil {
+			return deck.CloneRequest{}, deck.NewValidationError(deck.FieldError{Field: "reshuffle", Reason: "must be a boolean"})
+		}
+	}
+
 	return req, nil
 }
 
+// ParseExportRequest parses an export of the deck in the path.
+func ParseExportRequest(r *http.Request) (deck.ExportRequest, error) {
+	id, err := pathDeckId(r)
+	if err != nil {
+		return deck.ExportRequest{}, err
+	}
+	return deck.ExportRequest{DeckId: id, Caller: subject(r)}, nil
+}
+
+// ParseImportRequest parses an import, the body is an exported deck.
+func ParseImportRequest(r *http.Request) (deck.ImportRequest, error) {
+	req := deck.ImportRequest{}
+	if err := json.NewDecoder(http.MaxBytesReader(nil, r.Body, MaxBodyBytes)).Decode(&req.Deck); err != nil {
+		return deck.ImportRequest{}, invalidBody(err)
+	}
+	req.Caller = subject(r)
+
+	return req, nil
+}
+
+// ParseOpenRequest parses an open request. The offset and limit query parameters select a page of the cards,
+// fields a comma separated list of card fields and summary=true returns counts instead of cards.
+// The version query parameter selects a past version of the deck.
 func ParseOpenRequest(r *http.Request) (deck.OpenRequest, error) {
-	id := r.PathValue("UUID")
-	_, err := uuid.Parse(id)
+	id, err := pathDeckId(r)
 	if err != nil {
 		return deck.OpenRequest{}, err
 	}
+	req := deck.OpenRequest{DeckId: id, Caller: subject(r), Grant: grant(r)}
 
-	return deck.OpenRequest{DeckId: id}, nil
+	q := r.URL.Query()
+	var invalid []deck.FieldError
+	for _, param := range []struct {
+		name string
+		dst  *int
+	}{{"offset", &req.Offset}, {"limit", &req.Limit}} {
+		if !q.Has(param.name) {
+			continue
+		}
+		n, err := strconv.Atoi(q.Get(param.name))
+		if err != nil {
+			invalid = append(invalid, deck.FieldError{Field: param.name, Reason: "must be an integer"})
+		}
+		*param.dst = n
+	}
+	if q.Has("fields") {
+		req.Fields = strings.Split(q.Get("fields"), ",")
+	}
+	if q.Has("summary") {
+		if req.Summary, err = strconv.ParseBool(q.Get("summary")); err != nil {
+			invalid = append(invalid, deck.FieldError{Field: "summary", Reason: "must be a boolean"})
+		}
+	}
+	if q.Has("version") {
+		version, err := strconv.Atoi(q.Get("version"))
+		if err != nil {
+			invalid = append(invalid, deck.FieldError{Field: "version", Reason: "must be an integer"})
+		}
+		req.Version = &version
+	}
+	if len(invalid) > 0 {
+		return deck.OpenRequest{}, deck.NewValidationError(invalid...)
+	}
+
+	return req, nil
 }
 
+// ParseDrawRequest parses the body of the deprecated PUT /api/deck route, which holds the deck id and the count.
 func ParseDrawRequest(r *http.Request) (deck.DrawRequest, error) {
 	req := new(deck.DrawRequest)
 
 	err := json.NewDecoder(r.Body).Decode(req)
 	if err != nil {
-		return *req, err
+		return *req, invalidBody(err)
+	}
+	req.Caller = subject(r)
+	req.Grant = grant(r)
+
+	return *req, nil
+}
+
+// ParseDeckDrawRequest parses a draw from the deck in the path.
+// The count is given either as the count query parameter or in a JSON body, e.g. {"count": 2}.
+func ParseDeckDrawRequest(r *http.Request) (deck.DrawRequest, error) {
+	id, err := pathDeckId(r)
+	if err != nil {
+		return deck.DrawRequest{}, err
+	}
+
+	var count *int
+	if q := r.URL.Query(); q.Has("count") {
+		n, err := strconv.Atoi(q.Get("count"))
+		if err != nil {
+			return deck.DrawRequest{}, deck.NewValidationError(deck.FieldError{Field: "count", Reason: "must be an integer"})
+		}
+		count = &n
+	}
+
+	body := struct {
+		Count *int `json:"count"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		return deck.DrawRequest{}, invalidBody(err)
+	}
+
+	switch {
+	case count == nil && body.Count == nil:
+		return deck.DrawRequest{}, deck.NewValidationError(deck.FieldError{Field: "count", Reason: "is required"})
+	case count != nil && body.Count != nil && *count != *body.Count:
+		return deck.DrawRequest{}, deck.NewValidationError(deck.FieldError{Field: "count", Reason: "differs between the query and the body"})
+	case count == nil:
+		count = body.Count
+	}
+
+	return deck.DrawRequest{DeckId: id, Count: *count, Caller: subject(r), Grant: grant(r)}, nil
+}
+
+func ParseShareRequest(r *http.Request) (deck.ShareRequest, error) {
+	req := new(deck.ShareRequest)
+
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		return *req, invalidBody(err)
 	}
 
+	req.DeckId = r.PathValue("UUID")
+	req.Caller = subject(r)
+
 	return *req, nil
 }
+
+// ParseBatchRequest parses a batch of steps applied to the deck in the path, e.g. {"steps": [{"op": "draw", "count": 2}]}.
+func ParseBatchRequest(r *http.Request) (deck.BatchRequest, error) {
+	id, err := pathDeckId(r)
+	if err != nil {
+		return deck.BatchRequest{}, err
+	}
+
+	req := deck.BatchRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return deck.BatchRequest{}, invalidBody(err)
+	}
+	req.DeckId = id
+	req.Caller = subject(r)
+	req.Grant = grant(r)
+
+	return req, nil
+}
+
+// ParseSnapshotRequest parses a snapshot of the deck in the path, the name of the snapshot is in the path too.
+// The name is validated by the service.
+func ParseSnapshotRequest(r *http.Request) (deck.SnapshotRequest, error) {
+	id, err := pathDeckId(r)
+	if err != nil {
+		return deck.SnapshotRequest{}, err
+	}
+	return deck.SnapshotRequest{DeckId: id, Caller: subject(r), Name: r.PathValue("name")}, nil
+}
+
+// ParseListSnapshotsRequest parses a listing of the snapshots of the deck in the path.
+func ParseListSnapshotsRequest(r *http.Request) (deck.ListSnapshotsRequest, error) {
+	id, err := pathDeckId(r)
+	if err != nil {
+		return deck.ListSnapshotsRequest{}, err
+	}
+	return deck.ListSnapshotsRequest{DeckId: id, Caller: subject(r)}, nil
+}
+
+// pathDeckId returns the deck id of the UUID path parameter.
+func pathDeckId(r *http.Request) (string, error) {
+	id := r.PathValue("UUID")
+	if _, err := uuid.Parse(id); err != nil {
+		return "", deck.NewValidationError(deck.FieldError{Field: "UUID", Reason: "must be a UUID"})
+	}
+	return id, nil
+}
+
+// MaxBodyBytes limits request bodies that are read as a whole, e.g. an import, the export of the largest shoe
+// is a small fraction of it.
+const MaxBodyBytes = 1 << 20
+
+// bodyTooLarge returns the problem of a request body that exceeds its limit.
+func bodyTooLarge(err *http.MaxBytesError) error {
+	return problemBodyTooLarge.with(fmt.Sprintf("request body exceeds %d bytes", err.Limit))
+}
+
+// invalidBody returns a validation error for a request body that cannot be decoded.
+func invalidBody(err error) error {
+	if tooLarge := new(http.MaxBytesError); errors.As(err, &tooLarge) {
+		return bodyTooLarge(tooLarge)
+	}
+	return deck.NewSvcError(err, deck.ErrInvalidRequest).WithDetail("request body is not valid JSON: %s", err)
+}
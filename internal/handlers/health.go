@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+)
+
+// Check is a readiness check, it returns an error when a dependency is not ready.
+type Check func(ctx context.Context) error
+
+type status struct {
+	Status string `json:"status"`
+}
+
+// Liveness replies 200 as long as the process is able to serve requests.
+func Liveness() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, http.StatusOK, status{Status: "ok"})
+	}
+}
+
+// Readiness replies 200 when all checks pass and 503 otherwise.
+func Readiness(checks ...Check) http.HandlerFunc {
+	return MakeHandler(func(w http.ResponseWriter, r *http.Request) error {
+		for _, check := range checks {
+			if err := check(r.Context()); err != nil {
+				return problemNotReady.with(err.Error())
+			}
+		}
+		return writeJson(w, http.StatusOK, status{Status: "ready"})
+	})
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/idempotency"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+)
+
+// Idempotent is a middleware that stores the first response of requests with an Idempotency-Key header
+// and replays it to their retries, so that e.g. a retried draw does not draw again.
+// Keys are scoped by the client, the method and the path, which holds the deck. A key reused for
+// another request is rejected with 422 and a retry while the first request is in flight with 409.
+// Server errors, rejections of the rate limit and panics are not stored, so that such requests can be retried.
+// It has to be placed after the authentication middleware, requests without the header pass through.
+func Idempotent(store idempotency.Store) Middleware {
+	return func(next http.Handler) http.Handler {
+		return MakeHandler(func(w http.ResponseWriter, r *http.Request) error {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return nil
+			}
+			if !validRequestID(key) {
+				return deck.NewValidationError(deck.FieldError{Field: IdempotencyKeyHeader, Reason: "must be 1 to 128 printable characters"})
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodyBytes))
+			if tooLarge := new(http.MaxBytesError); errors.As(err, &tooLarge) {
+				return bodyTooLarge(tooLarge)
+			}
+			if err != nil {
+				return deck.NewSvcError(err, deck.ErrInvalidRequest).WithDetail("unable to read the request body")
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			scoped := clientKey(r) + " " + r.Method + " " + r.URL.Path + " " + key
+			stored, err := store.Begin(r.Context(), scoped, fingerprint(r, body))
+			switch {
+			case errors.Is(err, idempotency.ErrInFlight):
+				return problemIdempotencyInFlight.with(err.Error())
+			case errors.Is(err, idempotency.ErrMismatch):
+				return problemIdempotencyMismatch.with(err.Error())
+			case err != nil:
+				return err
+			case stored != nil:
+				for k, v := range stored.Header {
+					w.Header()[k] = v
+				}
+				w.Header().Set(IdempotencyReplayedHeader, "true")
+				return writeBody(w, stored.Status, stored.Header.Get("Content-Type"), stored.Body)
+			}
+
+			// the key is released unless the response is stored, also when the handler panics
+			completed := false
+			defer func() {
+				if completed {
+					return
+				}
+				if err := store.Release(context.WithoutCancel(r.Context()), scoped); err != nil {
+					slog.ErrorContext(r.Context(), "unable to release idempotency key", "request_id", RequestIDFrom(r.Context()), "error", err)
+				}
+			}()
+
+			rec := &bodyRecorder{statusRecorder: statusRecorder{ResponseWriter: w}}
+			next.ServeHTTP(rec, r)
+
+			// rejections of the rate limit did not reach the handler, the retry has to be applied
+			if rec.Status() >= http.StatusInternalServerError || rec.Status() == http.StatusTooManyRequests {
+				return nil
+			}
+			header := w.Header().Clone()
+			// the replay carries the request id of the retry
+			header.Del(RequestIDHeader)
+			// the response is written, failures of the store can only be logged
+			err = store.Complete(r.Context(), scoped, idempotency.Response{Status: rec.Status(), Header: header, Body: rec.body.Bytes()})
+			if err != nil {
+				slog.ErrorContext(r.Context(), "unable to store idempotent response", "request_id", RequestIDFrom(r.Context()), "error", err)
+			}
+			completed = err == nil
+			return nil
+		})
+	}
+}
+
+// fingerprint identifies a request by its query and body, the method and path are part of the key.
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.URL.RawQuery))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// bodyRecorder records the status code and body of a response.
+type bodyRecorder struct {
+	statusRecorder
+	body bytes.Buffer
+}
+
+func (r *bodyRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.statusRecorder.Write(b)
+}
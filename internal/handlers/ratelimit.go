@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"toggl-card-game/internal/ratelimit"
+)
+
+// RateLimit is a middleware that rejects requests not allowed by the given policy with 429.
+// The reservation of an allowed request is settled with its status, requests succeed with 2xx.
+// Requests are keyed by the authenticated subject or by the client IP for anonymous requests,
+// so it has to be placed after the authentication middleware.
+func RateLimit(policy ratelimit.Policy) Middleware {
+	return func(next http.Handler) http.Handler {
+		return MakeHandler(func(w http.ResponseWriter, r *http.Request) error {
+			reservation, ok, retry := policy.Reserve(clientKey(r))
+			if !ok {
+				seconds := int(math.Ceil(retry.Seconds()))
+				w.Header().Set("Retry-After", fmt.Sprint(seconds))
+				return problemRateLimited.with(fmt.Sprintf("retry after %d seconds", seconds))
+			}
+
+			rec := &statusRecorder{ResponseWriter: w}
+			succeeded := false
+			// a panicking handler did not succeed either
+			defer func() { reservation.Done(succeeded) }()
+			next.ServeHTTP(rec, r)
+			succeeded = rec.Status() < http.StatusMultipleChoices
+			return nil
+		})
+	}
+}
+
+func clientKey(r *http.Request) string {
+	if sub := subject(r); sub != "" {
+		return "sub:" + sub
+	}
+	if g := grant(r); g != nil {
+		return "grant:" + g.Id
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
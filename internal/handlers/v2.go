@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"toggl-card-game/api/apiv2"
+	"toggl-card-game/internal/core/deck"
+)
+
+// CreatedV2 writes a created deck in the v2 representation with 201 Created.
+func CreatedV2(w http.ResponseWriter, r *http.Request, out *deck.CreateResponse) error {
+	res := apiv2.Deck{
+		Id:        out.DeckId,
+		Shuffled:  out.Shuffled,
+		Remaining: out.Remaining,
+		Links:     apiv2.DeckLinks(out.DeckId),
+	}
+	w.Header().Set("Location", res.Links.Self)
+	return write(w, r, http.StatusCreated, res)
+}
+
+// OpenedV2 writes an opened deck in the v2 representation.
+func OpenedV2(w http.ResponseWriter, r *http.Request, out *deck.OpenResponse) error {
+	return write(w, r, http.StatusOK, apiv2.DeckDetail{
+		Id:          out.DeckId,
+		Shuffled:    out.Shuffled,
+		Remaining:   out.Remaining,
+		Version:     out.Version,
+		OnEmpty:     out.OnEmpty,
+		Penetration: penetrationV2(out.Penetration),
+		FaceDown:    out.FaceDown,
+		Hidden:      out.Hidden,
+		Cards:       cardsV2(out.Cards),
+		Page:        pageV2(out.Page),
+		Suits:       suitsV2(out.Suits),
+		Links:       apiv2.DeckLinks(out.DeckId),
+	})
+}
+
+// DrawnV2 writes drawn cards in the v2 representation.
+func DrawnV2(w http.ResponseWriter, r *http.Request, out *deck.DrawResponse) error {
+	return write(w, r, http.StatusOK, apiv2.Draw{
+		DeckId:      r.PathValue("UUID"),
+		Cards:       cardsV2(out.Cards),
+		Reshuffled:  reshuffleV2(out.Reshuffled),
+		Penetration: penetrationV2(out.Penetration),
+	})
+}
+
+// SharedV2 writes a capability token in the v2 representation.
+func SharedV2(w http.ResponseWriter, r *http.Request, out *deck.ShareResponse) error {
+	return write(w, r, http.StatusOK, apiv2.Share{
+		Token:     out.Token,
+		DeckId:    out.DeckId,
+		Scope:     out.Scope,
+		MaxCards:  out.MaxCards,
+		ExpiresAt: out.ExpiresAt,
+	})
+}
+
+// ParseBatchRequestV2 parses a batch of the deck in the path from its v2 representation.
+func ParseBatchRequestV2(r *http.Request) (deck.BatchRequest, error) {
+	id, err := pathDeckId(r)
+	if err != nil {
+		return deck.BatchRequest{}, err
+	}
+
+	in := apiv2.BatchRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		return deck.BatchRequest{}, invalidBody(err)
+	}
+	req := deck.BatchRequest{DeckId: id, Caller: subject(r), Grant: grant(r)}
+	for _, step := range in.Steps {
+		req.Steps = append(req.Steps, deck.BatchStep{Op: step.Op, Count: step.Count, Cards: step.Cards})
+	}
+
+	return req, nil
+}
+
+// BatchedV2 writes an applied batch in the v2 representation.
+func BatchedV2(w http.ResponseWriter, r *http.Request, out *deck.BatchResponse) error {
+	res := apiv2.Batch{DeckId: out.DeckId, Remaining: out.Remaining, Results: make([]apiv2.StepResult, 0, len(out.Results))}
+	for _, step := range out.Results {
+		res.Results = append(res.Results, apiv2.StepResult{
+			Op:         step.Op,
+			Cards:      cardsV2(step.Cards),
+			Remaining:  step.Remaining,
+			Reshuffled: reshuffleV2(step.Reshuffled),
+		})
+	}
+	return write(w, r, http.StatusOK, res)
+}
+
+// SnapshotV2 writes a snapshot in the v2 representation.
+func SnapshotV2(w http.ResponseWriter, r *http.Request, out *deck.SnapshotDto) error {
+	return write(w, r, http.StatusOK, snapshotV2(*out))
+}
+
+// SnapshotsV2 writes the snapshots of a deck in the v2 representation.
+func SnapshotsV2(w http.ResponseWriter, r *http.Request, out *deck.SnapshotList) error {
+	res := apiv2.SnapshotList{DeckId: out.DeckId, Snapshots: make([]apiv2.Snapshot, 0, len(out.Snapshots))}
+	for _, s := range out.Snapshots {
+		res.Snapshots = append(res.Snapshots, snapshotV2(s))
+	}
+	return write(w, r, http.StatusOK, res)
+}
+
+// RestoredV2 writes a deck restored from a snapshot in the v2 representation.
+func RestoredV2(w http.ResponseWriter, r *http.Request, out *deck.RestoreResponse) error {
+	return write(w, r, http.StatusOK, apiv2.Restore{
+		DeckId:    out.DeckId,
+		Snapshot:  out.Snapshot,
+		Version:   out.Version,
+		Remaining: out.Remaining,
+	})
+}
+
+// ExportedV2 writes an exported deck in the v2 representation.
+func ExportedV2(w http.ResponseWriter, r *http.Request, out *deck.DeckExport) error {
+	return write(w, r, http.StatusOK, apiv2.DeckExport{
+		Format:        out.Format,
+		Version:       out.Version,
+		Id:            out.Id,
+		Shuffled:      out.Shuffled,
+		FaceDown:      out.FaceDown,
+		OnEmpty:       out.OnEmpty,
+		Penetration:   out.Penetration,
+		AutoReshuffle: out.AutoReshuffle,
+		Cards:         cardsV2(out.Cards),
+		Discards:      cardsV2(out.Discards),
+		Composition:   cardsV2(out.Composition),
+	})
+}
+
+// ParseImportRequestV2 parses an import from the v2 representation of an exported deck.
+func ParseImportRequestV2(r *http.Request) (deck.ImportRequest, error) {
+	in := apiv2.DeckExport{}
+	if err := json.NewDecoder(http.MaxBytesReader(nil, r.Body, MaxBodyBytes)).Decode(&in); err != nil {
+		return deck.ImportRequest{}, invalidBody(err)
+	}
+
+	return deck.ImportRequest{
+		Deck: deck.DeckExport{
+			Format:        in.Format,
+			Version:       in.Version,
+			Id:            in.Id,
+			Shuffled:      in.Shuffled,
+			FaceDown:      in.FaceDown,
+			OnEmpty:       in.OnEmpty,
+			Penetration:   in.Penetration,
+			AutoReshuffle: in.AutoReshuffle,
+			Cards:         cardsFromV2(in.Cards),
+			Discards:      cardsFromV2(in.Discards),
+			Composition:   cardsFromV2(in.Composition),
+		},
+		Caller: subject(r),
+	}, nil
+}
+
+// cardsV2 converts cards to their v2 representation, nil stays nil.
+func cardsV2(cards []deck.CardDto) []apiv2.Card {
+	if cards == nil {
+		return nil
+	}
+	res := make([]apiv2.Card, 0, len(cards))
+	for _, c := range cards {
+		res = append(res, apiv2.Card{Value: c.Value, Suit: c.Suit, Code: c.Code, FaceDown: c.FaceDown})
+	}
+	return res
+}
+
+// cardsFromV2 converts cards from their v2 representation, nil stays nil.
+func cardsFromV2(cards []apiv2.Card) []deck.CardDto {
+	if cards == nil {
+		return nil
+	}
+	res := make([]deck.CardDto, 0, len(cards))
+	for _, c := range cards {
+		res = append(res, deck.CardDto{Value: c.Value, Suit: c.Suit, Code: c.Code, FaceDown: c.FaceDown})
+	}
+	return res
+}
+
+func pageV2(p *deck.Page) *apiv2.Page {
+	if p == nil {
+		return nil
+	}
+	return &apiv2.Page{Offset: p.Offset, Limit: p.Limit, Total: p.Total, NextOffset: p.NextOffset}
+}
+
+func suitsV2(suits []deck.SuitCount) []apiv2.SuitCount {
+	if suits == nil {
+		return nil
+	}
+	res := make([]apiv2.SuitCount, 0, len(suits))
+	for _, s := range suits {
+		res = append(res, apiv2.SuitCount{Suit: s.Suit, Count: s.Count})
+	}
+	return res
+}
+
+func reshuffleV2(r *deck.Reshuffle) *apiv2.Reshuffle {
+	if r == nil {
+		return nil
+	}
+	return &apiv2.Reshuffle{Policy: r.Policy, After: r.After, Cards: r.Cards}
+}
+
+func penetrationV2(p *deck.Penetration) *apiv2.Penetration {
+	if p == nil {
+		return nil
+	}
+	return &apiv2.Penetration{
+		Percent:         p.Percent,
+		CutCard:         p.CutCard,
+		Dealt:           p.Dealt,
+		ReshuffleNeeded: p.ReshuffleNeeded,
+		AutoReshuffle:   p.AutoReshuffle,
+	}
+}
+
+func snapshotV2(s deck.SnapshotDto) apiv2.Snapshot {
+	return apiv2.Snapshot{Name: s.Name, DeckId: s.DeckId, Version: s.Version, Remaining: s.Remaining, TakenAt: s.TakenAt}
+}
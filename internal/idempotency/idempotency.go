@@ -0,0 +1,119 @@
+// Package idempotency stores the first response of requests with an idempotency key,
+// so that retries of a request are answered without applying it again.
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInFlight is returned while the first request of a key has not completed yet.
+	ErrInFlight = errors.New("a request with this idempotency key is in flight")
+	// ErrMismatch is returned when a key is reused for a different request.
+	ErrMismatch = errors.New("idempotency key was used for a different request")
+)
+
+// Response is a stored response, it is replayed to the retries of its request.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Store stores the first response per idempotency key.
+type Store interface {
+	// Begin reserves the key for the request with the given fingerprint. It returns the stored response
+	// of a completed request, ErrInFlight while the first request is in flight and ErrMismatch when the key
+	// was used for a request with another fingerprint. A nil response and error mean that the caller
+	// reserved the key and has to Complete or Release it.
+	Begin(ctx context.Context, key, fingerprint string) (*Response, error)
+	// Complete stores the response of a reserved key.
+	Complete(ctx context.Context, key string, res Response) error
+	// Release forgets a reserved key without storing a response, so that the request can be retried.
+	Release(ctx context.Context, key string) error
+}
+
+type entry struct {
+	fingerprint string
+	res         *Response
+	expires     time.Time
+}
+
+// MemoryStore is a Store that keeps the responses in memory for a fixed window.
+// It is safe for concurrent use.
+type MemoryStore struct {
+	ttl       time.Duration
+	entries   map[string]*entry
+	lastSweep time.Time
+	now       func() time.Time
+	lock      sync.Mutex
+}
+
+// NewMemoryStore creates a store that keeps keys and their responses for ttl after their first request.
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	return &MemoryStore{
+		ttl:     ttl,
+		entries: make(map[string]*entry),
+		now:     time.Now,
+	}
+}
+
+// Begin implements Store interface.
+func (s *MemoryStore) Begin(ctx context.Context, key, fingerprint string) (*Response, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	e, ok := s.entries[key]
+	if !ok || now.After(e.expires) {
+		s.entries[key] = &entry{fingerprint: fingerprint, expires: now.Add(s.ttl)}
+		return nil, nil
+	}
+	if e.fingerprint != fingerprint {
+		return nil, ErrMismatch
+	}
+	if e.res == nil {
+		return nil, ErrInFlight
+	}
+	return e.res, nil
+}
+
+// Complete implements Store interface.
+func (s *MemoryStore) Complete(ctx context.Context, key string, res Response) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if e, ok := s.entries[key]; ok {
+		e.res = &res
+	}
+	return nil
+}
+
+// Release implements Store interface.
+func (s *MemoryStore) Release(ctx context.Context, key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// sweep removes expired keys, so that the store does not grow without bounds.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, e := range s.entries {
+		if now.After(e.expires) {
+			delete(s.entries, key)
+		}
+	}
+}
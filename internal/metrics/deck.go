@@ -0,0 +1,192 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+	"toggl-card-game/internal/core/deck"
+
+	"github.com/google/uuid"
+)
+
+// repoBuckets are histogram buckets in seconds for repository operations, which are usually fast.
+var repoBuckets = []float64{.00001, .00005, .0001, .0005, .001, .005, .01, .05, .1, .5}
+
+// Deck holds the metrics of the deck api.
+// A nil *Deck is valid and records nothing.
+type Deck struct {
+	registry        *Registry
+	Requests        *Counter
+	RequestDuration *Histogram
+	DecksCreated    *Counter
+	CardsDrawn      *Counter
+	RepoDuration    *Histogram
+}
+
+// NewDeck registers the deck api metrics in the given registry.
+func NewDeck(reg *Registry) *Deck {
+	return &Deck{
+		registry:        reg,
+		Requests:        reg.Counter("http_requests_total", "Total number of HTTP requests.", "route", "method", "code"),
+		RequestDuration: reg.Histogram("http_request_duration_seconds", "HTTP request latency in seconds.", DefBuckets, "route", "method"),
+		DecksCreated:    reg.Counter("decks_created_total", "Total number of created decks."),
+		CardsDrawn:      reg.Counter("cards_drawn_total", "Total number of drawn cards."),
+		RepoDuration:    reg.Histogram("repo_operation_duration_seconds", "Deck repository operation latency in seconds.", repoBuckets, "operation", "result"),
+	}
+}
+
+// ObserveRequest records a served HTTP request.
+func (m *Deck) ObserveRequest(route, method string, code int, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	m.Requests.Inc(route, method, formatFloat(float64(code)))
+	m.RequestDuration.Observe(elapsed.Seconds(), route, method)
+}
+
+// ObserveCreate decorates the create deck use case to count created decks.
+func (m *Deck) ObserveCreate(fn deck.TargetFunc[deck.CreateRequest, *deck.CreateResponse]) deck.TargetFunc[deck.CreateRequest, *deck.CreateResponse] {
+	return observeCreated(m, fn)
+}
+
+// ObserveClone decorates the clone deck use case to count clones as created decks.
+func (m *Deck) ObserveClone(fn deck.TargetFunc[deck.CloneRequest, *deck.CreateResponse]) deck.TargetFunc[deck.CloneRequest, *deck.CreateResponse] {
+	return observeCreated(m, fn)
+}
+
+// ObserveImport decorates the import deck use case to count imported decks as created decks.
+func (m *Deck) ObserveImport(fn deck.TargetFunc[deck.ImportRequest, *deck.CreateResponse]) deck.TargetFunc[deck.ImportRequest, *deck.CreateResponse] {
+	return observeCreated(m, fn)
+}
+
+// observeCreated decorates a use case that creates a deck to count created decks.
+func observeCreated[Req any](m *Deck, fn deck.TargetFunc[Req, *deck.CreateResponse]) deck.TargetFunc[Req, *deck.CreateResponse] {
+	if m == nil {
+		return fn
+	}
+	return func(ctx context.Context, req Req) (*deck.CreateResponse, error) {
+		res, err := fn(ctx, req)
+		if err == nil {
+			m.DecksCreated.Inc()
+		}
+		return res, err
+	}
+}
+
+// ObserveDraw decorates the draw cards use case to count drawn cards.
+func (m *Deck) ObserveDraw(fn deck.TargetFunc[deck.DrawRequest, *deck.DrawResponse]) deck.TargetFunc[deck.DrawRequest, *deck.DrawResponse] {
+	if m == nil {
+		return fn
+	}
+	return func(ctx context.Context, req deck.DrawRequest) (*deck.DrawResponse, error) {
+		res, err := fn(ctx, req)
+		if err == nil {
+			m.CardsDrawn.Add(float64(len(res.Cards)))
+		}
+		return res, err
+	}
+}
+
+// ObserveBatch decorates the batch use case to count the cards drawn by its draw steps.
+func (m *Deck) ObserveBatch(fn deck.TargetFunc[deck.BatchRequest, *deck.BatchResponse]) deck.TargetFunc[deck.BatchRequest, *deck.BatchResponse] {
+	if m == nil {
+		return fn
+	}
+	return func(ctx context.Context, req deck.BatchRequest) (*deck.BatchResponse, error) {
+		res, err := fn(ctx, req)
+		if err == nil {
+			for _, r := range res.Results {
+				if r.Op == deck.OpDraw {
+					m.CardsDrawn.Add(float64(len(r.Cards)))
+				}
+			}
+		}
+		return res, err
+	}
+}
+
+// Counted is implemented by repositories that can report the number of stored decks.
+type Counted interface {
+	Count() int
+}
+
+// Repo decorates the repository to measure operation latencies.
+// If the repository implements Counted, the number of active decks is exposed as a gauge.
+func (m *Deck) Repo(repo deck.Repo) deck.Repo {
+	if m == nil {
+		return repo
+	}
+	if c, ok := repo.(Counted); ok {
+		m.registry.GaugeFunc("active_decks", "Number of decks stored in the repository.", func() float64 {
+			return float64(c.Count())
+		})
+	}
+	return &instrumentedRepo{next: repo, duration: m.RepoDuration}
+}
+
+type instrumentedRepo struct {
+	next     deck.Repo
+	duration *Histogram
+}
+
+func (r *instrumentedRepo) observe(op string, start time.Time, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	r.duration.Observe(time.Since(start).Seconds(), op, result)
+}
+
+func (r *instrumentedRepo) Create(ctx context.Context, d *deck.Deck) (*deck.Deck, error) {
+	start := time.Now()
+	d, err := r.next.Create(ctx, d)
+	r.observe("create", start, err)
+	return d, err
+}
+
+func (r *instrumentedRepo) Get(ctx context.Context, id uuid.UUID) (*deck.Deck, error) {
+	start := time.Now()
+	d, err := r.next.Get(ctx, id)
+	r.observe("get", start, err)
+	return d, err
+}
+
+func (r *instrumentedRepo) Update(ctx context.Context, d *deck.Deck) (*deck.Deck, error) {
+	start := time.Now()
+	d, err := r.next.Update(ctx, d)
+	r.observe("update", start, err)
+	return d, err
+}
+
+func (r *instrumentedRepo) GetVersion(ctx context.Context, id uuid.UUID, version int) (*deck.Deck, error) {
+	start := time.Now()
+	d, err := r.next.GetVersion(ctx, id, version)
+	r.observe("get_version", start, err)
+	return d, err
+}
+
+func (r *instrumentedRepo) SaveSnapshot(ctx context.Context, snapshot deck.Snapshot) error {
+	start := time.Now()
+	err := r.next.SaveSnapshot(ctx, snapshot)
+	r.observe("save_snapshot", start, err)
+	return err
+}
+
+func (r *instrumentedRepo) GetSnapshot(ctx context.Context, id uuid.UUID, name string) (deck.Snapshot, error) {
+	start := time.Now()
+	s, err := r.next.GetSnapshot(ctx, id, name)
+	r.observe("get_snapshot", start, err)
+	return s, err
+}
+
+func (r *instrumentedRepo) ListSnapshots(ctx context.Context, id uuid.UUID) ([]deck.Snapshot, error) {
+	start := time.Now()
+	s, err := r.next.ListSnapshots(ctx, id)
+	r.observe("list_snapshots", start, err)
+	return s, err
+}
+
+// Handler returns a http.Handler that serves the metrics of the registry.
+func (m *Deck) Handler() http.Handler {
+	return m.registry.Handler()
+}
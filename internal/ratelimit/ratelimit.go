@@ -0,0 +1,217 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Policy decides whether a request identified by key is allowed.
+// An allowed request is charged, e.g. it takes a token, and the returned Reservation settles the charge.
+// When it is not allowed, nothing is charged and it returns how long the client should wait before retrying.
+type Policy interface {
+	Reserve(key string) (Reservation, bool, time.Duration)
+}
+
+// Reservation is the charge of an allowed request.
+type Reservation interface {
+	// Cancel gives the charge back, e.g. when another policy rejects the request.
+	Cancel()
+	// Done settles the charge with the outcome of the request. Policies that count only
+	// successful requests, e.g. DailyQuota, give the charge of a failed request back.
+	Done(succeeded bool)
+}
+
+// All combines policies, a request is allowed only if every policy allows it.
+// Policies are evaluated in order and evaluation stops at the first rejection,
+// the charges of the policies evaluated before are given back.
+func All(policies ...Policy) Policy {
+	return all(policies)
+}
+
+type all []Policy
+
+func (p all) Reserve(key string) (Reservation, bool, time.Duration) {
+	reservations := make(reservations, 0, len(p))
+	for _, policy := range p {
+		r, ok, retry := policy.Reserve(key)
+		if !ok {
+			reservations.Cancel()
+			return nil, false, retry
+		}
+		reservations = append(reservations, r)
+	}
+	return reservations, true, 0
+}
+
+type reservations []Reservation
+
+func (rs reservations) Cancel() {
+	for _, r := range rs {
+		r.Cancel()
+	}
+}
+
+func (rs reservations) Done(succeeded bool) {
+	for _, r := range rs {
+		r.Done(succeeded)
+	}
+}
+
+// reservation settles a charge with cancel, refundFailed gives the charge of failed requests back too.
+type reservation struct {
+	cancel       func()
+	refundFailed bool
+}
+
+func (r reservation) Cancel() {
+	r.cancel()
+}
+
+func (r reservation) Done(succeeded bool) {
+	if !succeeded && r.refundFailed {
+		r.cancel()
+	}
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// TokenBucket is a token bucket rate limiter that keeps one bucket per key.
+type TokenBucket struct {
+	rate      float64 // tokens per second
+	burst     float64
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+	lock      sync.Mutex
+}
+
+// NewTokenBucket creates a rate limiter that refills rate tokens per second up to burst tokens.
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Reserve implements Policy interface. A token is taken by every allowed request, failed ones too.
+func (tb *TokenBucket) Reserve(key string) (Reservation, bool, time.Duration) {
+	tb.lock.Lock()
+	defer tb.lock.Unlock()
+
+	now := tb.now()
+	tb.sweep(now)
+
+	b, ok := tb.buckets[key]
+	if !ok {
+		b = &bucket{tokens: tb.burst, last: now}
+		tb.buckets[key] = b
+	}
+
+	b.tokens = math.Min(tb.burst, b.tokens+now.Sub(b.last).Seconds()*tb.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return nil, false, time.Duration((1 - b.tokens) / tb.rate * float64(time.Second))
+	}
+	b.tokens--
+	return reservation{cancel: func() { tb.refund(key) }}, true, 0
+}
+
+// refund puts the token of a cancelled request back into the bucket of the key.
+func (tb *TokenBucket) refund(key string) {
+	tb.lock.Lock()
+	defer tb.lock.Unlock()
+
+	if b, ok := tb.buckets[key]; ok {
+		b.tokens = math.Min(tb.burst, b.tokens+1)
+	}
+}
+
+// sweep removes buckets that are full again, so idle clients do not hold memory.
+func (tb *TokenBucket) sweep(now time.Time) {
+	if now.Sub(tb.lastSweep) < time.Minute {
+		return
+	}
+	tb.lastSweep = now
+
+	for key, b := range tb.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*tb.rate >= tb.burst {
+			delete(tb.buckets, key)
+		}
+	}
+}
+
+// DailyQuota allows up to limit successful requests per key and UTC day.
+// Requests in flight count against the quota until they are done.
+type DailyQuota struct {
+	limit  int
+	day    time.Time
+	counts map[string]int
+	now    func() time.Time
+	lock   sync.Mutex
+}
+
+// NewDailyQuota creates a quota of limit requests per key and day.
+func NewDailyQuota(limit int) *DailyQuota {
+	return &DailyQuota{
+		limit:  limit,
+		counts: make(map[string]int),
+		now:    time.Now,
+	}
+}
+
+// Reserve implements Policy interface. Failed requests are given back, see Reservation.Done.
+func (q *DailyQuota) Reserve(key string) (Reservation, bool, time.Duration) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	now := q.now().UTC()
+	day := now.Truncate(24 * time.Hour)
+	if !day.Equal(q.day) {
+		q.day = day
+		q.counts = make(map[string]int)
+	}
+
+	if q.counts[key] >= q.limit {
+		return nil, false, day.Add(24 * time.Hour).Sub(now)
+	}
+	q.counts[key]++
+	return reservation{cancel: func() { q.refund(key, day) }, refundFailed: true}, true, 0
+}
+
+// refund gives a request of the day back, the counts of past days are gone already.
+func (q *DailyQuota) refund(key string, day time.Time) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if day.Equal(q.day) && q.counts[key] > 0 {
+		q.counts[key]--
+	}
+}
+
+// ParseRate parses a rate limit in the "rate/burst" format, e.g. "5/20" means
+// 5 requests per second with bursts of up to 20 requests.
+func ParseRate(s string) (*TokenBucket, error) {
+	r, b, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid rate limit [%s], expected rate/burst", s)
+	}
+	rate, err := strconv.ParseFloat(r, 64)
+	if err != nil || rate <= 0 {
+		return nil, fmt.Errorf("invalid rate in rate limit [%s]", s)
+	}
+	burst, err := strconv.Atoi(b)
+	if err != nil || burst < 1 {
+		return nil, fmt.Errorf("invalid burst in rate limit [%s]", s)
+	}
+	return NewTokenBucket(rate, burst), nil
+}
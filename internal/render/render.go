@@ -0,0 +1,96 @@
+// Package render formats playing cards for terminals.
+package render
+
+import (
+	"strings"
+	"toggl-card-game/api"
+)
+
+var symbols = map[string]string{
+	"SPADES":   "♠",
+	"DIAMONDS": "♦",
+	"CLUBS":    "♣",
+	"HEARTS":   "♥",
+}
+
+// Symbol returns the Unicode symbol of a suit.
+func Symbol(suit string) string {
+	if s, ok := symbols[suit]; ok {
+		return s
+	}
+	return "?"
+}
+
+// Rank returns the short rank of a card, e.g. A, 10 or K.
+func Rank(c api.Card) string {
+	if len(c.Value) > 2 {
+		return c.Value[:1]
+	}
+	return c.Value
+}
+
+// IsRed returns true for hearts and diamonds.
+func IsRed(c api.Card) bool {
+	return c.Suit == "HEARTS" || c.Suit == "DIAMONDS"
+}
+
+// Back is the rendering of a face down card whose face is hidden.
+const Back = "🂠"
+
+// Short renders a card as rank and suit symbol, e.g. A♠, or its back when the face is hidden.
+func Short(c api.Card) string {
+	if c.FaceDown && c.Code == "" {
+		return Back
+	}
+	return Rank(c) + Symbol(c.Suit)
+}
+
+// Painter colours a rendered card, e.g. with ANSI escape codes.
+type Painter func(c api.Card, s string) string
+
+// Plain is a painter that does not colour anything.
+func Plain(_ api.Card, s string) string {
+	return s
+}
+
+// ANSI is a painter that renders red suits in red.
+func ANSI(c api.Card, s string) string {
+	if IsRed(c) {
+		return "\x1b[31m" + s + "\x1b[0m"
+	}
+	return s
+}
+
+// Boxes renders cards side by side as boxes, wrapping after perRow cards.
+//
+//	┌─────┐
+//	│A    │
+//	│  ♠  │
+//	│    A│
+//	└─────┘
+func Boxes(cards []api.Card, perRow int, paint Painter) string {
+	if perRow < 1 {
+		perRow = len(cards)
+	}
+
+	b := new(strings.Builder)
+	for start := 0; start < len(cards); start += perRow {
+		row := cards[start:min(start+perRow, len(cards))]
+		lines := make([][]string, 5)
+		for _, c := range row {
+			rank := Rank(c)
+			sym := Symbol(c.Suit)
+			pad := strings.Repeat(" ", 5-len(rank))
+			lines[0] = append(lines[0], "┌─────┐")
+			lines[1] = append(lines[1], "│"+paint(c, rank)+pad+"│")
+			lines[2] = append(lines[2], "│  "+paint(c, sym)+"  │")
+			lines[3] = append(lines[3], "│"+pad+paint(c, rank)+"│")
+			lines[4] = append(lines[4], "└─────┘")
+		}
+		for _, l := range lines {
+			b.WriteString(strings.Join(l, " "))
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,52 @@
+package render_test
+
+import (
+	"testing"
+	"toggl-card-game/api"
+	"toggl-card-game/internal/render"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShort(t *testing.T) {
+	tests := []struct {
+		name string
+		card api.Card
+		want string
+	}{
+		{name: "ace of spades", card: api.Card{Value: "ACE", Suit: "SPADES", Code: "AS"}, want: "A♠"},
+		{name: "ten of hearts", card: api.Card{Value: "10", Suit: "HEARTS", Code: "10H"}, want: "10♥"},
+		{name: "queen of diamonds", card: api.Card{Value: "QUEEN", Suit: "DIAMONDS", Code: "QD"}, want: "Q♦"},
+		{name: "back of a face down card", card: api.Card{FaceDown: true}, want: render.Back},
+		{name: "face down card seen by its owner", card: api.Card{Value: "ACE", Suit: "SPADES", Code: "AS", FaceDown: true}, want: "A♠"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, render.Short(tt.card))
+		})
+	}
+}
+
+func TestBoxes(t *testing.T) {
+	cards := []api.Card{
+		{Value: "ACE", Suit: "SPADES", Code: "AS"},
+		{Value: "10", Suit: "HEARTS", Code: "10H"},
+		{Value: "KING", Suit: "CLUBS", Code: "KC"},
+	}
+
+	want := "" +
+		"┌─────┐ ┌─────┐\n" +
+		"│A    │ │10   │\n" +
+		"│  ♠  │ │  ♥  │\n" +
+		"│    A│ │   10│\n" +
+		"└─────┘ └─────┘\n" +
+		"┌─────┐\n" +
+		"│K    │\n" +
+		"│  ♣  │\n" +
+		"│    K│\n" +
+		"└─────┘\n"
+
+	assert.Equal(t, want, render.Boxes(cards, 2, render.Plain))
+	assert.Contains(t, render.Boxes(cards[1:2], 1, render.ANSI), "\x1b[31m♥\x1b[0m")
+}
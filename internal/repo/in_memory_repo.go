@@ -2,32 +2,64 @@ package repo
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"sync"
 	"toggl-card-game/internal/core/deck"
 
 	"github.com/google/uuid"
 )
 
 // InMemoryRepo implements deck.Repo interface.
-// Map is not thread-safe, so we need to use a sync mechanism like mutex to protect it or to use sync.Map.
-// Since this is just an example, I will leave it as it is.
+// The map is protected by a read-write mutex, so the repo is safe for concurrent use.
 // In a real-world application, I would implement CQRS pattern.
+// Versions start at 0 and are incremented by one per update, the latest versions of a deck are kept
+// up to the retention, see WithVersionRetention, older ones are evicted.
+// After Close, writes are rejected with ErrRepoClosed while reads keep working.
 type InMemoryRepo struct {
-	decks map[uuid.UUID]*deck.Deck
+	decks     map[uuid.UUID]*deck.Deck
+	versions  map[uuid.UUID][]*deck.Deck
+	retention int
+	snapshots map[uuid.UUID]map[string]deck.Snapshot
+	closed    bool
+	lock      sync.RWMutex
 }
 
+var ErrRepoClosed = errors.New("repository is closed")
+
 func NewInMemoryRepo() *InMemoryRepo {
 	return &InMemoryRepo{
-		decks: make(map[uuid.UUID]*deck.Deck, 52),
+		decks:     make(map[uuid.UUID]*deck.Deck, 52),
+		versions:  make(map[uuid.UUID][]*deck.Deck, 52),
+		snapshots: make(map[uuid.UUID]map[string]deck.Snapshot),
 	}
 }
 
+// WithVersionRetention sets the number of the latest versions kept per deck, 0 keeps every version.
+func (r *InMemoryRepo) WithVersionRetention(n int) *InMemoryRepo {
+	r.retention = n
+	return r
+}
+
 func (r *InMemoryRepo) Create(ctx context.Context, deck *deck.Deck) (*deck.Deck, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.closed {
+		return nil, ErrRepoClosed
+	}
 	r.decks[deck.Id()] = deck
+	// a created deck starts a new history
+	r.versions[deck.Id()] = append(r.versions[deck.Id()][:0:0], deck)
 	return deck, nil
 }
 
 func (r *InMemoryRepo) Get(ctx context.Context, id uuid.UUID) (*deck.Deck, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
 	deck, ok := r.decks[id]
 	if !ok {
 		return nil, fmt.Errorf("deck with ID [%s] was not found", id.String())
@@ -36,6 +68,101 @@ func (r *InMemoryRepo) Get(ctx context.Context, id uuid.UUID) (*deck.Deck, error
 }
 
 func (r *InMemoryRepo) Update(ctx context.Context, deck *deck.Deck) (*deck.Deck, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.closed {
+		return nil, ErrRepoClosed
+	}
 	r.decks[deck.Id()] = deck
+	versions := append(r.versions[deck.Id()], deck)
+	if r.retention > 0 && len(versions) > r.retention {
+		// the evicted versions are released when append moves the retained ones to a new array
+		versions = versions[len(versions)-r.retention:]
+	}
+	r.versions[deck.Id()] = versions
 	return deck, nil
 }
+
+func (r *InMemoryRepo) GetVersion(ctx context.Context, id uuid.UUID, version int) (*deck.Deck, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	versions := r.versions[id]
+	if version < 0 || len(versions) == 0 || version > versions[len(versions)-1].Version() {
+		return nil, fmt.Errorf("version %d of deck with ID [%s] was not found", version, id.String())
+	}
+	// the versions are consecutive from the oldest retained one
+	i := version - versions[0].Version()
+	if i < 0 {
+		return nil, fmt.Errorf("version %d of deck with ID [%s] is no longer retained", version, id.String())
+	}
+	return versions[i], nil
+}
+
+func (r *InMemoryRepo) SaveSnapshot(ctx context.Context, snapshot deck.Snapshot) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.closed {
+		return ErrRepoClosed
+	}
+	id := snapshot.Deck.Id()
+	if r.snapshots[id] == nil {
+		r.snapshots[id] = make(map[string]deck.Snapshot)
+	}
+	r.snapshots[id][snapshot.Name] = snapshot
+	return nil
+}
+
+func (r *InMemoryRepo) GetSnapshot(ctx context.Context, id uuid.UUID, name string) (deck.Snapshot, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	snapshot, ok := r.snapshots[id][name]
+	if !ok {
+		return deck.Snapshot{}, fmt.Errorf("snapshot %q of deck with ID [%s] was not found", name, id.String())
+	}
+	return snapshot, nil
+}
+
+func (r *InMemoryRepo) ListSnapshots(ctx context.Context, id uuid.UUID) ([]deck.Snapshot, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	snapshots := make([]deck.Snapshot, 0, len(r.snapshots[id]))
+	for _, snapshot := range r.snapshots[id] {
+		snapshots = append(snapshots, snapshot)
+	}
+	slices.SortFunc(snapshots, func(a, b deck.Snapshot) int { return strings.Compare(a.Name, b.Name) })
+	return snapshots, nil
+}
+
+// Count returns the number of stored decks.
+func (r *InMemoryRepo) Count() int {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return len(r.decks)
+}
+
+// Ping returns an error if the repo is not able to serve requests.
+func (r *InMemoryRepo) Ping(ctx context.Context) error {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if r.closed {
+		return ErrRepoClosed
+	}
+	return nil
+}
+
+// Close marks the repo as closed, later writes are rejected so that no change is accepted after the shutdown.
+// There is nothing to flush since decks live only in memory.
+func (r *InMemoryRepo) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.closed = true
+	return nil
+}
@@ -0,0 +1,145 @@
+// Package rpc is the gRPC adapter of the deck service.
+package rpc
+
+import (
+	"context"
+	"toggl-card-game/api"
+	"toggl-card-game/api/deckv1"
+	"toggl-card-game/internal/auth"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/metrics"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DeckServer implements the deck.v1.DeckService on top of the deck service.
+type DeckServer struct {
+	deckv1.UnimplementedDeckServiceServer
+
+	createDeck        deck.TargetFunc[deck.CreateRequest, *deck.CreateResponse]
+	openDeck          deck.TargetFunc[deck.OpenRequest, *deck.OpenResponse]
+	drawCards         deck.TargetFunc[deck.DrawRequest, *deck.DrawResponse]
+	shuffledByDefault bool
+}
+
+// NewDeckServer creates a new gRPC deck server. The metrics are optional.
+func NewDeckServer(svc *deck.Service, m *metrics.Deck, shuffledByDefault bool) *DeckServer {
+	return &DeckServer{
+		createDeck:        m.ObserveCreate(svc.CreateDeck),
+		openDeck:          svc.OpenDeck,
+		drawCards:         m.ObserveDraw(svc.DrawCards),
+		shuffledByDefault: shuffledByDefault,
+	}
+}
+
+func (s *DeckServer) CreateDeck(ctx context.Context, req *deckv1.CreateDeckRequest) (*deckv1.CreateDeckResponse, error) {
+	in := deck.CreateRequest{
+		Shuffled:      s.shuffledByDefault,
+		Cards:         req.GetCards(),
+		Owner:         subject(ctx),
+		FaceDown:      req.GetFaceDown(),
+		FaceUp:        req.GetFaceUp(),
+		OnEmpty:       req.GetOnEmpty(),
+		Decks:         int(req.GetDecks()),
+		Penetration:   int(req.GetPenetration()),
+		AutoReshuffle: req.GetAutoReshuffle(),
+	}
+	if req.Shuffled != nil {
+		in.Shuffled = req.GetShuffled()
+	}
+
+	out, err := s.createDeck(ctx, in)
+	if err != nil {
+		return nil, Status(err)
+	}
+
+	return &deckv1.CreateDeckResponse{DeckId: out.DeckId, Shuffled: out.Shuffled, Remaining: int32(out.Remaining)}, nil
+}
+
+func (s *DeckServer) OpenDeck(ctx context.Context, req *deckv1.OpenDeckRequest) (*deckv1.OpenDeckResponse, error) {
+	if err := validateDeckId(req.GetDeckId()); err != nil {
+		return nil, err
+	}
+
+	out, err := s.openDeck(ctx, deck.OpenRequest{DeckId: req.GetDeckId(), Caller: subject(ctx), Grant: grant(ctx)})
+	if err != nil {
+		return nil, Status(err)
+	}
+
+	return &deckv1.OpenDeckResponse{
+		DeckId:      out.DeckId,
+		Shuffled:    out.Shuffled,
+		Remaining:   int32(out.Remaining),
+		Cards:       toCards(out.Cards),
+		Version:     int32(out.Version),
+		OnEmpty:     out.OnEmpty,
+		Penetration: toPenetration(out.Penetration),
+		FaceDown:    out.FaceDown,
+		Hidden:      int32(out.Hidden),
+	}, nil
+}
+
+func (s *DeckServer) DrawCards(ctx context.Context, req *deckv1.DrawCardsRequest) (*deckv1.DrawCardsResponse, error) {
+	if err := validateDeckId(req.GetDeckId()); err != nil {
+		return nil, err
+	}
+
+	out, err := s.drawCards(ctx, deck.DrawRequest{
+		DeckId: req.GetDeckId(),
+		Count:  int(req.GetCount()),
+		Caller: subject(ctx),
+		Grant:  grant(ctx),
+	})
+	if err != nil {
+		return nil, Status(err)
+	}
+
+	return &deckv1.DrawCardsResponse{
+		Cards:       toCards(out.Cards),
+		Reshuffled:  toReshuffle(out.Reshuffled),
+		Penetration: toPenetration(out.Penetration),
+	}, nil
+}
+
+func validateDeckId(id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid deck id: %s", err)
+	}
+	return nil
+}
+
+func toCards(cards []api.Card) []*deckv1.Card {
+	out := make([]*deckv1.Card, len(cards))
+	for i, c := range cards {
+		out[i] = &deckv1.Card{Value: c.Value, Suit: c.Suit, Code: c.Code, FaceDown: c.FaceDown}
+	}
+	return out
+}
+
+func toReshuffle(r *api.Reshuffle) *deckv1.Reshuffle {
+	if r == nil {
+		return nil
+	}
+	return &deckv1.Reshuffle{Policy: r.Policy, After: int32(r.After), Cards: int32(r.Cards)}
+}
+
+func toPenetration(p *api.Penetration) *deckv1.Penetration {
+	if p == nil {
+		return nil
+	}
+	return &deckv1.Penetration{
+		Percent:         int32(p.Percent),
+		CutCard:         int32(p.CutCard),
+		Dealt:           int32(p.Dealt),
+		ReshuffleNeeded: p.ReshuffleNeeded,
+		AutoReshuffle:   p.AutoReshuffle,
+	}
+}
+
+// subject returns the subject of the authenticated principal or an empty string.
+func subject(ctx context.Context) string {
+	p, _ := auth.FromContext(ctx)
+	return p.Subject
+}
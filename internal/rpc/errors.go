@@ -0,0 +1,34 @@
+package rpc
+
+import (
+	"errors"
+	"toggl-card-game/internal/core/deck"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcCodes maps the kinds of deck errors to gRPC status codes.
+var grpcCodes = map[deck.Kind]codes.Code{
+	deck.KindInternal:  codes.Internal,
+	deck.KindInvalid:   codes.InvalidArgument,
+	deck.KindNotFound:  codes.NotFound,
+	deck.KindForbidden: codes.PermissionDenied,
+	deck.KindConflict:  codes.FailedPrecondition,
+}
+
+// Status converts an error of the deck service to a gRPC status error.
+// The message holds the code and title of the catalogue entry and the detail, internal errors are not exposed.
+func Status(err error) error {
+	var svcErr deck.SvcError
+	if !errors.As(err, &svcErr) {
+		return status.Error(codes.Internal, deck.ErrInternal.Code)
+	}
+
+	e := svcErr.Catalogued()
+	msg := e.Code + ": " + e.Title
+	if svcErr.Detail != "" {
+		msg += ": " + svcErr.Detail
+	}
+	return status.Error(grpcCodes[e.Kind], msg)
+}
@@ -0,0 +1,150 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"runtime/debug"
+	"slices"
+	"time"
+	"toggl-card-game/api/deckv1"
+	"toggl-card-game/internal/auth"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/ratelimit"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Capability scopes of the methods that accept capability tokens.
+// Players open decks with their draw tokens, the deck service redacts what they see.
+var methodScopes = map[string][]deck.Scope{
+	deckv1.DeckService_OpenDeck_FullMethodName:  {deck.ScopeRead, deck.ScopeDraw},
+	deckv1.DeckService_DrawCards_FullMethodName: {deck.ScopeDraw},
+}
+
+type grantKey struct{}
+
+// Access is an interceptor that mirrors the http authentication and capability middlewares.
+// A capability token in the x-capability-token metadata is accepted for methods that allow it,
+// otherwise the credentials in the x-api-key or authorization metadata are verified.
+// A nil signer disables capability tokens and a disabled authenticator lets anonymous calls through.
+func Access(authn *auth.Authenticator, signer *auth.Signer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		if token := first(md, "x-capability-token"); token != "" && signer != nil {
+			scopes, ok := methodScopes[info.FullMethod]
+			if !ok {
+				return nil, status.Error(codes.PermissionDenied, "capability token does not permit this operation")
+			}
+			grant, err := signer.VerifyGrant(token)
+			if err != nil {
+				return nil, status.Error(codes.Unauthenticated, err.Error())
+			}
+			if !slices.Contains(scopes, grant.Scope) {
+				return nil, status.Error(codes.PermissionDenied, "capability token does not permit this operation")
+			}
+			if r, ok := req.(interface{ GetDeckId() string }); ok && r.GetDeckId() != grant.DeckId {
+				return nil, status.Error(codes.PermissionDenied, "capability token is not valid for this deck")
+			}
+			return handler(context.WithValue(ctx, grantKey{}, &grant), req)
+		}
+
+		if authn == nil || !authn.Enabled() {
+			return handler(ctx, req)
+		}
+		p, err := authn.AuthenticateCredentials(first(md, "x-api-key"), first(md, "authorization"))
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return handler(auth.NewContext(ctx, p), req)
+	}
+}
+
+// grant returns the grant of a verified capability token or nil.
+func grant(ctx context.Context) *deck.Grant {
+	g, _ := ctx.Value(grantKey{}).(*deck.Grant)
+	return g
+}
+
+func first(md metadata.MD, key string) string {
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// RateLimit is an interceptor that rejects calls not allowed by the policy of the method with ResourceExhausted.
+// Calls are keyed like http requests, so both transports share the same budget per client.
+// The reservation of a call is settled with its outcome. It has to be placed after the Access interceptor.
+func RateLimit(policies map[string]ratelimit.Policy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		policy, ok := policies[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		reservation, ok, retry := policy.Reserve(clientKey(ctx))
+		if !ok {
+			_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", fmt.Sprint(int(math.Ceil(retry.Seconds())))))
+			return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+		}
+		// a panicking handler did not succeed either
+		succeeded := false
+		defer func() { reservation.Done(succeeded) }()
+		res, err := handler(ctx, req)
+		succeeded = err == nil
+		return res, err
+	}
+}
+
+func clientKey(ctx context.Context) string {
+	if sub := subject(ctx); sub != "" {
+		return "sub:" + sub
+	}
+	if g := grant(ctx); g != nil {
+		return "grant:" + g.Id
+	}
+	var host string
+	if p, ok := peer.FromContext(ctx); ok {
+		host = p.Addr.String()
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+	}
+	return "ip:" + host
+}
+
+// Log is an interceptor that writes an access log entry for every call.
+func Log(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		logger.LogAttrs(ctx, slog.LevelInfo, "grpc call",
+			slog.String("method", info.FullMethod),
+			slog.String("code", status.Code(err).String()),
+			slog.Duration("duration", time.Since(start)),
+		)
+		return resp, err
+	}
+}
+
+// Recover is an interceptor that turns panics into Internal errors.
+func Recover(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				logger.ErrorContext(ctx, "panic", "method", info.FullMethod, "panic", v, "stack", string(debug.Stack()))
+				err = status.Error(codes.Internal, "internal server error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"toggl-card-game/api/deckv1"
+	"toggl-card-game/internal/ratelimit"
+	"toggl-card-game/internal/rpc"
+
+	"google.golang.org/grpc"
+)
+
+// grpcRoutes maps gRPC methods to the http routes whose rate limits they share.
+var grpcRoutes = map[string]string{
+	deckv1.DeckService_CreateDeck_FullMethodName: RouteCreateDeck,
+	deckv1.DeckService_OpenDeck_FullMethodName:   RouteOpenDeck,
+	deckv1.DeckService_DrawCards_FullMethodName:  RouteDrawCards,
+}
+
+// RegisterGRPC creates the gRPC server of the deck service. It applies the same
+// authentication, capability tokens and rate limits as the http routes.
+func (s *Server) RegisterGRPC() *grpc.Server {
+	limits := make(map[string]ratelimit.Policy)
+	for method, route := range grpcRoutes {
+		if policy, ok := s.RateLimits[route]; ok {
+			limits[method] = policy
+		}
+	}
+
+	logger := s.logger()
+	gs := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		rpc.Log(logger),
+		rpc.Recover(logger),
+		rpc.Access(s.Auth, s.Signer),
+		rpc.RateLimit(limits),
+	))
+	deckv1.RegisterDeckServiceServer(gs, rpc.NewDeckServer(s.DeckService, s.Metrics, s.ShuffleByDefault))
+
+	return gs
+}
@@ -1,16 +1,240 @@
 package server
 
 import (
+	"log/slog"
 	"net/http"
+	"path"
+	"strings"
+	"time"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/docs"
 	"toggl-card-game/internal/handlers"
 )
 
+// Routes of version 1 of the api. They are the keys of the rate limits,
+// the routes of the other versions share the rate limit of their version 1 counterpart,
+// batches and restores share the rate limit of draws, exports and snapshots the rate limit of opened decks
+// and clones and imports the rate limit of created decks.
+const (
+	RouteCreateDeck = "POST /api/v1/deck"
+	RouteOpenDeck   = "GET /api/v1/deck/{UUID}"
+	RouteDrawCards  = "POST /api/v1/deck/{UUID}/draw"
+	RouteShareDeck  = "POST /api/v1/deck/{UUID}/share"
+	RouteBatch      = "POST /api/v1/deck/{UUID}/batch"
+	RouteCloneDeck  = "POST /api/v1/deck/{UUID}/clone"
+	RouteExportDeck = "GET /api/v1/deck/{UUID}/export"
+	RouteImportDeck = "POST /api/v1/deck/import"
+
+	RouteSnapshotDeck    = "PUT /api/v1/deck/{UUID}/snapshots/{name}"
+	RouteListSnapshots   = "GET /api/v1/deck/{UUID}/snapshots"
+	RouteRestoreSnapshot = "POST /api/v1/deck/{UUID}/snapshots/{name}/restore"
+
+	// RouteDrawCardsDeprecated is the deprecated alias of RouteDrawCards, the deck id is in the body.
+	RouteDrawCardsDeprecated = "PUT /api/v1/deck"
+)
+
+// LegacyDeprecation is the date the unversioned routes were deprecated, the release that introduced versioning.
+var LegacyDeprecation = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+// Routes of version 2 of the api.
+const (
+	RouteV2CreateDeck = "POST /api/v2/decks"
+	RouteV2OpenDeck   = "GET /api/v2/decks/{UUID}"
+	RouteV2DrawCards  = "POST /api/v2/decks/{UUID}/draw"
+	RouteV2ShareDeck  = "POST /api/v2/decks/{UUID}/share"
+	RouteV2Batch      = "POST /api/v2/decks/{UUID}/batch"
+	RouteV2CloneDeck  = "POST /api/v2/decks/{UUID}/clone"
+	RouteV2ExportDeck = "GET /api/v2/decks/{UUID}/export"
+	RouteV2ImportDeck = "POST /api/v2/decks/import"
+
+	RouteV2SnapshotDeck    = "PUT /api/v2/decks/{UUID}/snapshots/{name}"
+	RouteV2ListSnapshots   = "GET /api/v2/decks/{UUID}/snapshots"
+	RouteV2RestoreSnapshot = "POST /api/v2/decks/{UUID}/snapshots/{name}/restore"
+)
+
+// route is an api route with its access middleware and handler.
+type route struct {
+	pattern string
+	access  handlers.Middleware
+	handler handlers.MyHandlerFunc
+	// limited is the route whose rate limit applies, the pattern when empty.
+	limited string
+	// versionedOnly routes were added after versioning, they have no unversioned alias.
+	versionedOnly bool
+}
+
 func (s *Server) RegisterRoutes() http.Handler {
 	mux := http.NewServeMux()
+	s.routes = nil
+
+	authn := s.authenticate()
+	// players open decks with their draw tokens, the deck service redacts what they see
+	read := s.capability(authn, deck.ScopeRead, deck.ScopeDraw)
+	draw := s.capability(authn, deck.ScopeDraw)
+	// the unversioned aliases are succeeded by their version 1 routes, the deck ID of the deprecated draw is in
+	// its body, so it links to its documentation only
+	deprecated := handlers.Deprecated(LegacyDeprecation, s.Sunset, "/docs", versioned)
+	deprecatedDraw := handlers.Deprecated(LegacyDeprecation, s.Sunset, "/docs", func(*http.Request) string { return "" })
+	// requests that change decks are replayed to their retries
+	idempotent := s.idempotent()
+	create := handlers.Chain(authn, idempotent)
+	drawOnce := handlers.Chain(draw, idempotent)
 
-	mux.HandleFunc("POST /api/deck", handlers.MakeHandler(handlers.Handle(handlers.ParseCreateRequest, s.DeckService.CreateDeck)))
-	mux.HandleFunc("GET /api/deck/{UUID}", handlers.MakeHandler(handlers.Handle(handlers.ParseOpenRequest, s.DeckService.OpenDeck)))
-	mux.HandleFunc("PUT /api/deck", handlers.MakeHandler(handlers.Handle(handlers.ParseDrawRequest, s.DeckService.DrawCards)))
+	parseCreate := handlers.CreateRequestParser(s.ShuffleByDefault)
+	createDeck := s.Metrics.ObserveCreate(s.DeckService.CreateDeck)
+	drawCards := s.Metrics.ObserveDraw(s.DeckService.DrawCards)
+	cloneDeck := s.Metrics.ObserveClone(s.DeckService.CloneDeck)
+	importDeck := s.Metrics.ObserveImport(s.DeckService.ImportDeck)
+	batch := s.Metrics.ObserveBatch(s.DeckService.Batch)
+
+	v1 := []route{
+		{pattern: RouteCreateDeck, access: create, handler: handlers.HandleWith(parseCreate, createDeck, handlers.Created(deckLocation))},
+		{pattern: RouteOpenDeck, access: read, handler: handlers.Handle(handlers.ParseOpenRequest, s.DeckService.OpenDeck)},
+		{pattern: RouteDrawCards, access: drawOnce, handler: handlers.Handle(handlers.ParseDeckDrawRequest, drawCards)},
+		{pattern: RouteShareDeck, access: authn, handler: handlers.Handle(handlers.ParseShareRequest, s.DeckService.ShareDeck)},
+		{pattern: RouteDrawCardsDeprecated, access: handlers.Chain(deprecatedDraw, drawOnce), handler: handlers.Handle(handlers.ParseDrawRequest, drawCards), limited: RouteDrawCards},
+		{pattern: RouteBatch, access: drawOnce, handler: handlers.Handle(handlers.ParseBatchRequest, batch), limited: RouteDrawCards, versionedOnly: true},
+		{pattern: RouteCloneDeck, access: create, handler: handlers.HandleWith(handlers.ParseCloneRequest, cloneDeck, handlers.Created(cloneLocation)), limited: RouteCreateDeck, versionedOnly: true},
+		{pattern: RouteExportDeck, access: authn, handler: handlers.Handle(handlers.ParseExportRequest, s.DeckService.ExportDeck), limited: RouteOpenDeck, versionedOnly: true},
+		{pattern: RouteImportDeck, access: create, handler: handlers.HandleWith(handlers.ParseImportRequest, importDeck, handlers.Created(importLocation)), limited: RouteCreateDeck, versionedOnly: true},
+		{pattern: RouteSnapshotDeck, access: authn, handler: handlers.Handle(handlers.ParseSnapshotRequest, s.DeckService.TakeSnapshot), limited: RouteOpenDeck, versionedOnly: true},
+		{pattern: RouteListSnapshots, access: authn, handler: handlers.Handle(handlers.ParseListSnapshotsRequest, s.DeckService.ListSnapshots), limited: RouteOpenDeck, versionedOnly: true},
+		{pattern: RouteRestoreSnapshot, access: authn, handler: handlers.Handle(handlers.ParseSnapshotRequest, s.DeckService.RestoreSnapshot), limited: RouteDrawCards, versionedOnly: true},
+	}
+	for _, rt := range v1 {
+		if rt.limited == "" {
+			rt.limited = rt.pattern
+		}
+		s.handle(mux, rt.pattern, rt.limited, rt.access, rt.handler)
+		if rt.versionedOnly {
+			continue
+		}
+		// the unversioned routes predate versioning, they are deprecated aliases of version 1
+		s.handle(mux, unversioned(rt.pattern), rt.limited, handlers.Chain(deprecated, rt.access), rt.handler)
+	}
+
+	s.handle(mux, RouteV2CreateDeck, RouteCreateDeck, create,
+		handlers.HandleWith(parseCreate, createDeck, handlers.CreatedV2))
+	s.handle(mux, RouteV2OpenDeck, RouteOpenDeck, read,
+		handlers.HandleWith(handlers.ParseOpenRequest, s.DeckService.OpenDeck, handlers.OpenedV2))
+	s.handle(mux, RouteV2DrawCards, RouteDrawCards, drawOnce,
+		handlers.HandleWith(handlers.ParseDeckDrawRequest, drawCards, handlers.DrawnV2))
+	s.handle(mux, RouteV2ShareDeck, RouteShareDeck, authn,
+		handlers.HandleWith(handlers.ParseShareRequest, s.DeckService.ShareDeck, handlers.SharedV2))
+	s.handle(mux, RouteV2Batch, RouteDrawCards, drawOnce,
+		handlers.HandleWith(handlers.ParseBatchRequestV2, batch, handlers.BatchedV2))
+	s.handle(mux, RouteV2CloneDeck, RouteCreateDeck, create,
+		handlers.HandleWith(handlers.ParseCloneRequest, cloneDeck, handlers.CreatedV2))
+	s.handle(mux, RouteV2ExportDeck, RouteOpenDeck, authn,
+		handlers.HandleWith(handlers.ParseExportRequest, s.DeckService.ExportDeck, handlers.ExportedV2))
+	s.handle(mux, RouteV2ImportDeck, RouteCreateDeck, create,
+		handlers.HandleWith(handlers.ParseImportRequestV2, importDeck, handlers.CreatedV2))
+	s.handle(mux, RouteV2SnapshotDeck, RouteOpenDeck, authn,
+		handlers.HandleWith(handlers.ParseSnapshotRequest, s.DeckService.TakeSnapshot, handlers.SnapshotV2))
+	s.handle(mux, RouteV2ListSnapshots, RouteOpenDeck, authn,
+		handlers.HandleWith(handlers.ParseListSnapshotsRequest, s.DeckService.ListSnapshots, handlers.SnapshotsV2))
+	s.handle(mux, RouteV2RestoreSnapshot, RouteDrawCards, authn,
+		handlers.HandleWith(handlers.ParseSnapshotRequest, s.DeckService.RestoreSnapshot, handlers.RestoredV2))
+
+	s.register(mux, "GET /healthz", handlers.Liveness())
+	s.register(mux, "GET /readyz", handlers.Readiness(s.ready))
+	s.register(mux, "GET /openapi.json", docs.SpecHandler())
+	s.register(mux, "GET /docs", docs.PageHandler())
+
+	if s.Metrics != nil {
+		s.register(mux, "GET /metrics", s.Metrics.Handler())
+	}
+
+	logger := s.logger()
+	return handlers.Chain(
+		handlers.RequestID,
+		handlers.AccessLog(logger),
+		handlers.Recover(logger),
+	)(mux)
+}
+
+// handle registers the handler for the route pattern behind instrumentation,
+// the given access middleware and the rate limit of the limited route.
+func (s *Server) handle(mux *http.ServeMux, pattern, limited string, access handlers.Middleware, h handlers.MyHandlerFunc) {
+	s.register(mux, pattern, handlers.Chain(
+		handlers.Instrument(pattern, s.Metrics),
+		access,
+		s.limit(limited),
+	)(handlers.MakeHandler(h)))
+}
+
+// versioned returns the version 1 path of a request to an unversioned route, e.g. /api/v1/deck/{UUID}.
+func versioned(r *http.Request) string {
+	return strings.Replace(r.URL.Path, "/api/", "/api/v1/", 1)
+}
+
+// unversioned returns the unversioned pattern of a version 1 route, e.g. POST /api/deck.
+func unversioned(pattern string) string {
+	return strings.Replace(pattern, "/api/v1/", "/api/", 1)
+}
+
+// deckLocation returns the location of a created deck below the path of the create request.
+func deckLocation(r *http.Request, res *deck.CreateResponse) string {
+	return r.URL.Path + "/" + res.DeckId
+}
+
+// cloneLocation returns the location of a cloned deck next to the deck it was cloned from.
+func cloneLocation(r *http.Request, res *deck.CreateResponse) string {
+	return path.Dir(path.Dir(r.URL.Path)) + "/" + res.DeckId
+}
+
+// importLocation returns the location of an imported deck next to the import path.
+func importLocation(r *http.Request, res *deck.CreateResponse) string {
+	return path.Dir(r.URL.Path) + "/" + res.DeckId
+}
+
+// register registers the handler and records its route pattern.
+func (s *Server) register(mux *http.ServeMux, pattern string, h http.Handler) {
+	s.routes = append(s.routes, pattern)
+	mux.Handle(pattern, h)
+}
+
+// Routes returns the route patterns registered by RegisterRoutes.
+func (s *Server) Routes() []string {
+	return s.routes
+}
+
+// authenticate returns the authentication middleware or a no-op one when authentication is disabled.
+func (s *Server) authenticate() handlers.Middleware {
+	if s.Auth == nil || !s.Auth.Enabled() {
+		return handlers.Noop
+	}
+	return handlers.Authenticate(s.Auth)
+}
+
+// capability returns the capability middleware or the fallback one when no signer is configured.
+func (s *Server) capability(fallback handlers.Middleware, scopes ...deck.Scope) handlers.Middleware {
+	if s.Signer == nil {
+		return fallback
+	}
+	return handlers.Capability(s.Signer, fallback, scopes...)
+}
+
+// idempotent returns the idempotency middleware or a no-op one when no store is configured.
+func (s *Server) idempotent() handlers.Middleware {
+	if s.Idempotency == nil {
+		return handlers.Noop
+	}
+	return handlers.Idempotent(s.Idempotency)
+}
+
+// limit returns the rate limit middleware of the given route or a no-op one when the route is not limited.
+func (s *Server) limit(route string) handlers.Middleware {
+	policy, ok := s.RateLimits[route]
+	if !ok {
+		return handlers.Noop
+	}
+	return handlers.RateLimit(policy)
+}
 
-	return mux
+func (s *Server) logger() *slog.Logger {
+	if s.Logger == nil {
+		return slog.Default()
+	}
+	return s.Logger
 }
@@ -1,53 +1,226 @@
 package server
 
 import (
+	"context"
+	"crypto/rand"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"net"
 	"net/http"
-	"os"
-	"strings"
+	"sync/atomic"
 	"time"
+	"toggl-card-game/internal/auth"
+	"toggl-card-game/internal/config"
 	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/idempotency"
+	"toggl-card-game/internal/metrics"
+	"toggl-card-game/internal/ratelimit"
 	"toggl-card-game/internal/repo"
 
-	_ "github.com/joho/godotenv/autoload"
+	"google.golang.org/grpc"
 )
 
 type Server struct {
-	port        string
+	httpServer   *http.Server
+	grpcServer   *grpc.Server
+	grpcAddr     string
+	drainTimeout time.Duration
+	draining     atomic.Bool
+	routes       []string
+
+	// Repo is the underlying repository, it is checked by /readyz and closed on shutdown.
+	Repo        deck.Repo
+	Auth        *auth.Authenticator
+	Signer      *auth.Signer
 	DeckService *deck.Service
+	// RateLimits maps route patterns to their rate limit policy, routes without a policy are not limited.
+	RateLimits map[string]ratelimit.Policy
+	// Idempotency stores the responses of requests with an Idempotency-Key header, the header is ignored when nil.
+	Idempotency idempotency.Store
+	// ShuffleByDefault is used when a create request has no shuffled parameter.
+	ShuffleByDefault bool
+	// Sunset is the date after which the deprecated routes may be removed, it is sent in the Sunset header when set.
+	Sunset time.Time
+	Logger *slog.Logger
+	// Metrics is optional, when set the api is instrumented and metrics are served on /metrics.
+	Metrics *metrics.Deck
 }
 
-func New() *http.Server {
-	port := getEnvOr("PORT", "8080")
+func New(cfg *config.Config) (*Server, error) {
+	keys, err := auth.ParseKeys(cfg.APIKeys)
+	if err != nil {
+		return nil, err
+	}
+	// identity tokens are accepted only with a configured secret,
+	// capability tokens fall back to a random secret valid for the process lifetime
+	var identitySigner *auth.Signer
+	secret := []byte(cfg.AuthTokenSecret)
+	if len(secret) > 0 {
+		identitySigner = auth.NewSigner(secret)
+	} else {
+		secret = make([]byte, 32)
+		if _, err = rand.Read(secret); err != nil {
+			return nil, err
+		}
+	}
+	signer := auth.NewSigner(secret)
+
+	limits, err := rateLimits(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	m := metrics.NewDeck(metrics.NewRegistry())
+	memoryRepo := repo.NewInMemoryRepo().WithVersionRetention(cfg.VersionRetention)
+
 	mySrv := &Server{
-		port:        port,
-		DeckService: deck.NewService(repo.NewInMemoryRepo()),
+		drainTimeout: cfg.ShutdownTimeout,
+		Repo:         memoryRepo,
+		Auth:         auth.NewAuthenticator(keys, identitySigner),
+		Signer:       signer,
+		DeckService: deck.NewService(m.Repo(memoryRepo)).
+			WithGrantSigner(signer).
+			WithGrantTTL(cfg.ShareTokenTTL, cfg.ShareTokenMaxTTL),
+		RateLimits:       limits,
+		ShuffleByDefault: cfg.ShuffleByDefault,
+		Sunset:           cfg.LegacySunset,
+		Metrics:          m,
+	}
+
+	if cfg.IdempotencyTTL > 0 {
+		mySrv.Idempotency = idempotency.NewMemoryStore(cfg.IdempotencyTTL)
+	}
+
+	if !mySrv.Auth.Enabled() {
+		slog.Warn("no API_KEYS or AUTH_TOKEN_SECRET configured, authentication is disabled")
 	}
 
 	// Declare Server config
-	server := &http.Server{
-		Addr:         fmt.Sprintf(":%s", mySrv.port),
+	mySrv.httpServer = &http.Server{
+		Addr:         cfg.Addr(),
 		Handler:      mySrv.RegisterRoutes(),
-		IdleTimeout:  time.Minute,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 30 * time.Second,
+		IdleTimeout:  cfg.IdleTimeout,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
 	}
 
-	return server
+	if addr := cfg.GRPCAddr(); addr != "" {
+		mySrv.grpcAddr = addr
+		mySrv.grpcServer = mySrv.RegisterGRPC()
+	}
+
+	return mySrv, nil
 }
 
-func getEnvOr(key string, def string) string {
-	env, ok := os.LookupEnv(key)
-	if ok && isNotBlank(env) {
-		return env
+// Run serves http and gRPC requests until ctx is done, then it stops accepting new connections,
+// waits up to the drain timeout for in-flight requests and closes the repository.
+func (s *Server) Run(ctx context.Context) error {
+	errCh := make(chan error, 2)
+	go func() {
+		s.logger().Info("Server is starting", "port", s.httpServer.Addr)
+		if err := s.httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+	}()
+
+	if s.grpcServer != nil {
+		lis, err := net.Listen("tcp", s.grpcAddr)
+		if err != nil {
+			return errors.Join(fmt.Errorf("unable to listen for gRPC: %w", err), s.httpServer.Close())
+		}
+		go func() {
+			s.logger().Info("gRPC server is starting", "port", s.grpcAddr)
+			if err := s.grpcServer.Serve(lis); err != nil {
+				errCh <- err
+			}
+		}()
 	}
-	return def
+
+	select {
+	case err := <-errCh:
+		s.stopGRPC(context.Background())
+		return errors.Join(err, s.httpServer.Close())
+	case <-ctx.Done():
+	}
+
+	s.logger().Info("Server is shutting down", "drain_timeout", s.drainTimeout)
+	s.draining.Store(true)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.drainTimeout)
+	defer cancel()
+
+	grpcStopped := make(chan struct{})
+	go func() {
+		s.stopGRPC(shutdownCtx)
+		close(grpcStopped)
+	}()
+
+	err := s.httpServer.Shutdown(shutdownCtx)
+	if err != nil {
+		err = fmt.Errorf("unable to drain connections: %w", err)
+	}
+	<-grpcStopped
+
+	return errors.Join(err, s.closeRepo())
 }
 
-func isBlank(s string) bool {
-	return strings.TrimSpace(s) == ""
+// stopGRPC gracefully stops the gRPC server and closes the remaining connections when ctx is done.
+func (s *Server) stopGRPC(ctx context.Context) {
+	if s.grpcServer == nil {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+	}
 }
 
-func isNotBlank(s string) bool {
-	return !isBlank(s)
+// ready is the readiness check, the server is not ready while draining or when the repo does not respond.
+func (s *Server) ready(ctx context.Context) error {
+	if s.draining.Load() {
+		return errors.New("server is shutting down")
+	}
+	if p, ok := s.Repo.(interface{ Ping(context.Context) error }); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (s *Server) closeRepo() error {
+	if c, ok := s.Repo.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			return fmt.Errorf("unable to close repository: %w", err)
+		}
+	}
+	return nil
+}
+
+// rateLimits creates per route rate limits and the daily quota of created decks per client.
+func rateLimits(cfg *config.Config) (map[string]ratelimit.Policy, error) {
+	limits := make(map[string]ratelimit.Policy)
+	for route, rate := range map[string]string{
+		RouteCreateDeck: cfg.RateLimitCreate,
+		RouteOpenDeck:   cfg.RateLimitOpen,
+		RouteDrawCards:  cfg.RateLimitDraw,
+		RouteShareDeck:  cfg.RateLimitShare,
+	} {
+		limiter, err := ratelimit.ParseRate(rate)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", route, err)
+		}
+		limits[route] = limiter
+	}
+	limits[RouteCreateDeck] = ratelimit.All(limits[RouteCreateDeck], ratelimit.NewDailyQuota(cfg.DeckCreateDailyQuota))
+
+	return limits, nil
 }
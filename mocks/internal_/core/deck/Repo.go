@@ -142,6 +142,230 @@ func (_c *Repo_Get_Call) RunAndReturn(run func(context.Context, uuid.UUID) (*dec
 	return _c
 }
 
+// GetSnapshot provides a mock function with given fields: ctx, id, name
+func (_m *Repo) GetSnapshot(ctx context.Context, id uuid.UUID, name string) (deck.Snapshot, error) {
+	ret := _m.Called(ctx, id, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSnapshot")
+	}
+
+	var r0 deck.Snapshot
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) (deck.Snapshot, error)); ok {
+		return rf(ctx, id, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) deck.Snapshot); ok {
+		r0 = rf(ctx, id, name)
+	} else {
+		r0 = ret.Get(0).(deck.Snapshot)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, string) error); ok {
+		r1 = rf(ctx, id, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repo_GetSnapshot_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSnapshot'
+type Repo_GetSnapshot_Call struct {
+	*mock.Call
+}
+
+// GetSnapshot is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uuid.UUID
+//   - name string
+func (_e *Repo_Expecter) GetSnapshot(ctx interface{}, id interface{}, name interface{}) *Repo_GetSnapshot_Call {
+	return &Repo_GetSnapshot_Call{Call: _e.mock.On("GetSnapshot", ctx, id, name)}
+}
+
+func (_c *Repo_GetSnapshot_Call) Run(run func(ctx context.Context, id uuid.UUID, name string)) *Repo_GetSnapshot_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Repo_GetSnapshot_Call) Return(_a0 deck.Snapshot, _a1 error) *Repo_GetSnapshot_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repo_GetSnapshot_Call) RunAndReturn(run func(context.Context, uuid.UUID, string) (deck.Snapshot, error)) *Repo_GetSnapshot_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetVersion provides a mock function with given fields: ctx, id, version
+func (_m *Repo) GetVersion(ctx context.Context, id uuid.UUID, version int) (*deck.Deck, error) {
+	ret := _m.Called(ctx, id, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetVersion")
+	}
+
+	var r0 *deck.Deck
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, int) (*deck.Deck, error)); ok {
+		return rf(ctx, id, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, int) *deck.Deck); ok {
+		r0 = rf(ctx, id, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*deck.Deck)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, int) error); ok {
+		r1 = rf(ctx, id, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repo_GetVersion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetVersion'
+type Repo_GetVersion_Call struct {
+	*mock.Call
+}
+
+// GetVersion is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uuid.UUID
+//   - version int
+func (_e *Repo_Expecter) GetVersion(ctx interface{}, id interface{}, version interface{}) *Repo_GetVersion_Call {
+	return &Repo_GetVersion_Call{Call: _e.mock.On("GetVersion", ctx, id, version)}
+}
+
+func (_c *Repo_GetVersion_Call) Run(run func(ctx context.Context, id uuid.UUID, version int)) *Repo_GetVersion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *Repo_GetVersion_Call) Return(_a0 *deck.Deck, _a1 error) *Repo_GetVersion_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repo_GetVersion_Call) RunAndReturn(run func(context.Context, uuid.UUID, int) (*deck.Deck, error)) *Repo_GetVersion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListSnapshots provides a mock function with given fields: ctx, id
+func (_m *Repo) ListSnapshots(ctx context.Context, id uuid.UUID) ([]deck.Snapshot, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSnapshots")
+	}
+
+	var r0 []deck.Snapshot
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]deck.Snapshot, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []deck.Snapshot); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]deck.Snapshot)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repo_ListSnapshots_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListSnapshots'
+type Repo_ListSnapshots_Call struct {
+	*mock.Call
+}
+
+// ListSnapshots is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uuid.UUID
+func (_e *Repo_Expecter) ListSnapshots(ctx interface{}, id interface{}) *Repo_ListSnapshots_Call {
+	return &Repo_ListSnapshots_Call{Call: _e.mock.On("ListSnapshots", ctx, id)}
+}
+
+func (_c *Repo_ListSnapshots_Call) Run(run func(ctx context.Context, id uuid.UUID)) *Repo_ListSnapshots_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *Repo_ListSnapshots_Call) Return(_a0 []deck.Snapshot, _a1 error) *Repo_ListSnapshots_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repo_ListSnapshots_Call) RunAndReturn(run func(context.Context, uuid.UUID) ([]deck.Snapshot, error)) *Repo_ListSnapshots_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveSnapshot provides a mock function with given fields: ctx, snapshot
+func (_m *Repo) SaveSnapshot(ctx context.Context, snapshot deck.Snapshot) error {
+	ret := _m.Called(ctx, snapshot)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveSnapshot")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, deck.Snapshot) error); ok {
+		r0 = rf(ctx, snapshot)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Repo_SaveSnapshot_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveSnapshot'
+type Repo_SaveSnapshot_Call struct {
+	*mock.Call
+}
+
+// SaveSnapshot is a helper method to define mock.On call
+//   - ctx context.Context
+//   - snapshot deck.Snapshot
+func (_e *Repo_Expecter) SaveSnapshot(ctx interface{}, snapshot interface{}) *Repo_SaveSnapshot_Call {
+	return &Repo_SaveSnapshot_Call{Call: _e.mock.On("SaveSnapshot", ctx, snapshot)}
+}
+
+func (_c *Repo_SaveSnapshot_Call) Run(run func(ctx context.Context, snapshot deck.Snapshot)) *Repo_SaveSnapshot_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(deck.Snapshot))
+	})
+	return _c
+}
+
+func (_c *Repo_SaveSnapshot_Call) Return(_a0 error) *Repo_SaveSnapshot_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Repo_SaveSnapshot_Call) RunAndReturn(run func(context.Context, deck.Snapshot) error) *Repo_SaveSnapshot_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Update provides a mock function with given fields: ctx, _a1
 func (_m *Repo) Update(ctx context.Context, _a1 *deck.Deck) (*deck.Deck, error) {
 	ret := _m.Called(ctx, _a1)
@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"toggl-card-game/internal/auth"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/handlers"
+	"toggl-card-game/internal/repo"
+	"toggl-card-game/internal/server"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthentication(t *testing.T) {
+	signer := auth.NewSigner([]byte("secret"))
+	srv := &server.Server{
+		Auth:        auth.NewAuthenticator(map[string]string{"alice-key": "alice", "bob-key": "bob"}, signer),
+		DeckService: deck.NewService(repo.NewInMemoryRepo()),
+	}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	validToken, _ := signer.Sign(auth.Claims{Subject: "alice", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	expiredToken, _ := signer.Sign(auth.Claims{Subject: "alice", ExpiresAt: time.Now().Add(-time.Hour).Unix()})
+	forgedToken, _ := auth.NewSigner([]byte("other")).Sign(auth.Claims{Subject: "alice"})
+
+	tests := []struct {
+		name     string
+		header   string
+		value    string
+		wantCode int
+	}{
+		{name: "missing credentials test", wantCode: http.StatusUnauthorized},
+		{name: "invalid api key test", header: "X-API-Key", value: "nope", wantCode: http.StatusUnauthorized},
+		{name: "valid api key test", header: "X-API-Key", value: "alice-key", wantCode: http.StatusCreated},
+		{name: "valid bearer token test", header: "Authorization", value: "Bearer " + validToken, wantCode: http.StatusCreated},
+		{name: "expired bearer token test", header: "Authorization", value: "Bearer " + expiredToken, wantCode: http.StatusUnauthorized},
+		{name: "forged bearer token test", header: "Authorization", value: "Bearer " + forgedToken, wantCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, server.URL+"/api/deck", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("error making request to server. Err: %v", err)
+			}
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+			if tt.wantCode == http.StatusUnauthorized {
+				apiErr := new(handlers.ApiError)
+				assert.Nil(t, json.NewDecoder(resp.Body).Decode(apiErr))
+				assert.Equal(t, http.StatusUnauthorized, apiErr.Status)
+			}
+		})
+	}
+}
+
+func TestDeckOwnership(t *testing.T) {
+	srv := &server.Server{
+		Auth:        auth.NewAuthenticator(map[string]string{"alice-key": "alice", "bob-key": "bob"}, nil),
+		DeckService: deck.NewService(repo.NewInMemoryRepo()),
+	}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	do := func(method, route, key string, body []byte) *http.Response {
+		req, _ := http.NewRequest(method, server.URL+route, bytes.NewReader(body))
+		req.Header.Set("X-API-Key", key)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("error making request to server. Err: %v", err)
+		}
+		return resp
+	}
+
+	resp := do(http.MethodPost, "/api/deck", "alice-key", nil)
+	createRes := new(deck.CreateResponse)
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(createRes))
+	resp.Body.Close()
+
+	drawBody, _ := json.Marshal(deck.DrawRequest{DeckId: createRes.DeckId, Count: 1})
+
+	tests := []struct {
+		name     string
+		method   string
+		route    string
+		key      string
+		body     []byte
+		wantCode int
+	}{
+		{name: "owner opens deck test", method: http.MethodGet, route: "/api/deck/" + createRes.DeckId, key: "alice-key", wantCode: http.StatusOK},
+		{name: "other subject opens deck test", method: http.MethodGet, route: "/api/deck/" + createRes.DeckId, key: "bob-key", wantCode: http.StatusForbidden},
+		{name: "owner draws from deck test", method: http.MethodPut, route: "/api/deck", key: "alice-key", body: drawBody, wantCode: http.StatusOK},
+		{name: "other subject draws from deck test", method: http.MethodPut, route: "/api/deck", key: "bob-key", body: drawBody, wantCode: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := do(tt.method, tt.route, tt.key, tt.body)
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("expected status code %d, got %d", tt.wantCode, resp.StatusCode)
+			}
+		})
+	}
+
+	// the forbidden draw must not have consumed any card
+	resp = do(http.MethodGet, fmt.Sprintf("/api/deck/%s", createRes.DeckId), "alice-key", nil)
+	defer resp.Body.Close()
+	openRes := new(deck.OpenResponse)
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(openRes))
+	assert.Equal(t, 51, openRes.Remaining)
+}
@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"toggl-card-game/api"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/repo"
+	"toggl-card-game/internal/server"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatch(t *testing.T) {
+	srv := &server.Server{DeckService: deck.NewService(repo.NewInMemoryRepo())}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	do := func(t *testing.T, method, path string, body any, out any) int {
+		b, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, server.URL+path, bytes.NewReader(b))
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+		return resp.StatusCode
+	}
+	create := func(t *testing.T) string {
+		created := new(api.CreateResponse)
+		require.Equal(t, http.StatusCreated, do(t, http.MethodPost, "/api/v1/deck?cards=AS,KH,10D,QC", nil, created))
+		return created.DeckId
+	}
+	remaining := func(t *testing.T, id string) int {
+		opened := new(api.OpenResponse)
+		require.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/deck/"+id, nil, opened))
+		return opened.Remaining
+	}
+
+	for _, path := range []string{"/api/v1/deck/%s/batch", "/api/v2/decks/%s/batch"} {
+		t.Run(path+" applies all steps test", func(t *testing.T) {
+			id := create(t)
+			res := new(api.BatchResponse)
+			code := do(t, http.MethodPost, fmt.Sprintf(path, id), api.BatchRequest{Steps: []api.BatchStep{
+				{Op: "draw", Count: 2},
+				{Op: "discard", Cards: []string{"KH"}},
+				{Op: "shuffle"},
+			}}, res)
+
+			require.Equal(t, http.StatusOK, code)
+			assert.Equal(t, id, res.DeckId)
+			assert.Equal(t, 2, res.Remaining)
+			require.Len(t, res.Results, 3)
+			assert.Equal(t, "AS", res.Results[0].Cards[0].Code)
+			assert.Equal(t, "KH", res.Results[1].Cards[0].Code)
+			assert.Equal(t, "shuffle", res.Results[2].Op)
+			assert.Equal(t, 2, remaining(t, id))
+		})
+	}
+
+	t.Run("failed step rolls back test", func(t *testing.T) {
+		id := create(t)
+		problem := new(api.Problem)
+		code := do(t, http.MethodPost, "/api/v1/deck/"+id+"/batch", api.BatchRequest{Steps: []api.BatchStep{
+			{Op: "draw", Count: 1},
+			{Op: "discard", Cards: []string{"QC"}},
+		}}, problem)
+
+		assert.Equal(t, http.StatusConflict, code)
+		assert.Equal(t, "/problems/card-not-discardable", problem.Type)
+		assert.Equal(t, "step 1 (discard) failed, no step was applied: card QC was not drawn from the deck", problem.Detail)
+		assert.Equal(t, 4, remaining(t, id))
+	})
+
+	t.Run("card not of the deck test", func(t *testing.T) {
+		id := create(t)
+		problem := new(api.Problem)
+		code := do(t, http.MethodPost, "/api/v1/deck/"+id+"/batch", api.BatchRequest{Steps: []api.BatchStep{
+			{Op: "discard", Cards: []string{"2C"}},
+		}}, problem)
+
+		assert.Equal(t, http.StatusConflict, code)
+		assert.Equal(t, "/problems/card-not-discardable", problem.Type)
+		assert.Equal(t, "step 0 (discard) failed, no step was applied: card 2C is not a card of the deck", problem.Detail)
+		assert.Equal(t, 4, remaining(t, id))
+	})
+
+	t.Run("invalid step test", func(t *testing.T) {
+		id := create(t)
+		problem := new(api.Problem)
+		code := do(t, http.MethodPost, "/api/v1/deck/"+id+"/batch", api.BatchRequest{Steps: []api.BatchStep{{Op: "cut"}}}, problem)
+
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, []api.FieldError{{Field: "steps[0].op", Reason: "must be one of draw, discard or shuffle"}}, problem.Errors)
+	})
+}
@@ -0,0 +1,159 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"toggl-card-game/internal/auth"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/repo"
+	"toggl-card-game/internal/server"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapabilityTokens(t *testing.T) {
+	signer := auth.NewSigner([]byte("secret"))
+	srv := &server.Server{
+		Auth:        auth.NewAuthenticator(map[string]string{"alice-key": "alice", "bob-key": "bob"}, nil),
+		Signer:      signer,
+		DeckService: deck.NewService(repo.NewInMemoryRepo()).WithGrantSigner(signer),
+	}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	do := func(method, route string, headers map[string]string, body any) *http.Response {
+		var b []byte
+		if body != nil {
+			b, _ = json.Marshal(body)
+		}
+		req, _ := http.NewRequest(method, server.URL+route, bytes.NewReader(b))
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("error making request to server. Err: %v", err)
+		}
+		return resp
+	}
+
+	create := func() string {
+		resp := do(http.MethodPost, "/api/deck", map[string]string{"X-API-Key": "alice-key"}, nil)
+		defer resp.Body.Close()
+		createRes := new(deck.CreateResponse)
+		assert.Nil(t, json.NewDecoder(resp.Body).Decode(createRes))
+		return createRes.DeckId
+	}
+
+	share := func(deckId, key string, req deck.ShareRequest) (*deck.ShareResponse, int) {
+		resp := do(http.MethodPost, "/api/deck/"+deckId+"/share", map[string]string{"X-API-Key": key}, req)
+		defer resp.Body.Close()
+		shareRes := new(deck.ShareResponse)
+		json.NewDecoder(resp.Body).Decode(shareRes)
+		return shareRes, resp.StatusCode
+	}
+
+	deckId := create()
+	otherDeckId := create()
+
+	read, code := share(deckId, "alice-key", deck.ShareRequest{Scope: deck.ScopeRead})
+	assert.Equal(t, http.StatusOK, code)
+	draw, code := share(deckId, "alice-key", deck.ShareRequest{Scope: deck.ScopeDraw, MaxCards: 2})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 2, draw.MaxCards)
+
+	_, code = share(deckId, "bob-key", deck.ShareRequest{Scope: deck.ScopeRead})
+	assert.Equal(t, http.StatusForbidden, code)
+	_, code = share(deckId, "alice-key", deck.ShareRequest{Scope: "admin"})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	tests := []struct {
+		name     string
+		method   string
+		route    string
+		headers  map[string]string
+		body     any
+		wantCode int
+	}{
+		{
+			name:     "spectator opens deck with query token test",
+			method:   http.MethodGet,
+			route:    "/api/deck/" + deckId + "?token=" + read.Token,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "spectator opens deck with header token test",
+			method:   http.MethodGet,
+			route:    "/api/deck/" + deckId,
+			headers:  map[string]string{"X-Capability-Token": read.Token},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "spectator opens another deck test",
+			method:   http.MethodGet,
+			route:    "/api/deck/" + otherDeckId + "?token=" + read.Token,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "spectator draws from deck test",
+			method:   http.MethodPut,
+			route:    "/api/deck?token=" + read.Token,
+			body:     deck.DrawRequest{DeckId: deckId, Count: 1},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "player opens deck test",
+			method:   http.MethodGet,
+			route:    "/api/deck/" + deckId + "?token=" + draw.Token,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "player draws from another deck test",
+			method:   http.MethodPut,
+			route:    "/api/deck?token=" + draw.Token,
+			body:     deck.DrawRequest{DeckId: otherDeckId, Count: 1},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "player draws within limit test",
+			method:   http.MethodPut,
+			route:    "/api/deck?token=" + draw.Token,
+			body:     deck.DrawRequest{DeckId: deckId, Count: 2},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "player draws over limit test",
+			method:   http.MethodPut,
+			route:    "/api/deck?token=" + draw.Token,
+			body:     deck.DrawRequest{DeckId: deckId, Count: 1},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "capability token cannot create deck test",
+			method:   http.MethodPost,
+			route:    "/api/deck",
+			headers:  map[string]string{"Authorization": "Bearer " + read.Token},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "tampered token test",
+			method:   http.MethodGet,
+			route:    "/api/deck/" + deckId + "?token=" + read.Token + "x",
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := do(tt.method, tt.route, tt.headers, tt.body)
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("expected status code %d, got %d", tt.wantCode, resp.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"toggl-card-game/api"
+	"toggl-card-game/api/apiv2"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/repo"
+	"toggl-card-game/internal/server"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloneDeck(t *testing.T) {
+	srv := &server.Server{DeckService: deck.NewService(repo.NewInMemoryRepo())}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	do := func(t *testing.T, method, path string, body any, out any) *http.Response {
+		b, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, server.URL+path, bytes.NewReader(b))
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+		return resp
+	}
+	remaining := func(t *testing.T, id string) int {
+		opened := new(api.OpenResponse)
+		require.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/deck/"+id, nil, opened).StatusCode)
+		return opened.Remaining
+	}
+
+	created := new(api.CreateResponse)
+	require.Equal(t, http.StatusCreated, do(t, http.MethodPost, "/api/v1/deck?cards=AS,KH,QC,JD", nil, created).StatusCode)
+	id := created.DeckId
+	batch := api.BatchRequest{Steps: []api.BatchStep{{Op: "draw", Count: 1}, {Op: "discard", Cards: []string{"AS"}}}}
+	require.Equal(t, http.StatusOK, do(t, http.MethodPost, "/api/v1/deck/"+id+"/batch", batch, new(api.BatchResponse)).StatusCode)
+
+	t.Run("clone is independent test", func(t *testing.T) {
+		cloned := new(api.CreateResponse)
+		resp := do(t, http.MethodPost, "/api/v1/deck/"+id+"/clone", nil, cloned)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, "/api/v1/deck/"+cloned.DeckId, resp.Header.Get("Location"))
+		assert.NotEqual(t, id, cloned.DeckId)
+		assert.Equal(t, 3, cloned.Remaining)
+
+		drawn := new(api.DrawResponse)
+		require.Equal(t, http.StatusOK, do(t, http.MethodPost, "/api/v1/deck/"+cloned.DeckId+"/draw?count=2", nil, drawn).StatusCode)
+		assert.Equal(t, []string{"KH", "QC"}, []string{drawn.Cards[0].Code, drawn.Cards[1].Code})
+		assert.Equal(t, 1, remaining(t, cloned.DeckId))
+		assert.Equal(t, 3, remaining(t, id))
+	})
+
+	t.Run("clone keeps the discard pile test", func(t *testing.T) {
+		cloned := new(api.CreateResponse)
+		require.Equal(t, http.StatusCreated, do(t, http.MethodPost, "/api/v1/deck/"+id+"/clone", nil, cloned).StatusCode)
+
+		problem := new(api.Problem)
+		discard := api.BatchRequest{Steps: []api.BatchStep{{Op: "discard", Cards: []string{"AS"}}}}
+		resp := do(t, http.MethodPost, "/api/v1/deck/"+cloned.DeckId+"/batch", discard, problem)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		assert.Equal(t, "/problems/card-not-discardable", problem.Type)
+	})
+
+	t.Run("reshuffled clone in version 2 test", func(t *testing.T) {
+		cloned := new(apiv2.Deck)
+		resp := do(t, http.MethodPost, "/api/v2/decks/"+id+"/clone?reshuffle=true", nil, cloned)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, "/api/v2/decks/"+cloned.Id, resp.Header.Get("Location"))
+		assert.True(t, cloned.Shuffled)
+		assert.Equal(t, 3, cloned.Remaining)
+	})
+
+	t.Run("invalid reshuffle test", func(t *testing.T) {
+		problem := new(api.Problem)
+		resp := do(t, http.MethodPost, "/api/v1/deck/"+id+"/clone?reshuffle=maybe", nil, problem)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, []api.FieldError{{Field: "reshuffle", Reason: "must be a boolean"}}, problem.Errors)
+	})
+}
@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+	"toggl-card-game/api/apiv2"
+	"toggl-card-game/internal/auth"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/repo"
+	"toggl-card-game/internal/server"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// contract pins the JSON members of the responses of an api version.
+type contract struct {
+	prefix     string
+	create     []string
+	open       []string
+	draw       []string
+	share      []string
+	card       []string
+	deckId     string // member of the create response holding the deck id
+	deprecated bool
+}
+
+var (
+	contractV1 = contract{
+		prefix: "/api/v1/deck",
+		create: []string{"deck_id", "remaining", "shuffled"},
+		open:   []string{"cards", "deck_id", "remaining", "shuffled", "version"},
+		draw:   []string{"cards"},
+		share:  []string{"deck_id", "expires_at", "max_cards", "scope", "token"},
+		card:   []string{"code", "suit", "value"},
+		deckId: "deck_id",
+	}
+	contractV2 = contract{
+		prefix: "/api/v2/decks",
+		create: []string{"id", "links", "remaining", "shuffled"},
+		open:   []string{"cards", "id", "links", "remaining", "shuffled", "version"},
+		draw:   []string{"cards", "deck_id"},
+		share:  []string{"deck_id", "expires_at", "max_cards", "scope", "token"},
+		card:   []string{"code", "suit", "value"},
+		deckId: "id",
+	}
+	contractUnversioned = func() contract {
+		c := contractV1
+		c.prefix = "/api/deck"
+		c.deprecated = true
+		return c
+	}()
+)
+
+func TestVersionContracts(t *testing.T) {
+	sunset := time.Date(2027, time.June, 30, 0, 0, 0, 0, time.UTC)
+	signer := auth.NewSigner([]byte("secret"))
+	srv := &server.Server{
+		Signer:      signer,
+		DeckService: deck.NewService(repo.NewInMemoryRepo()).WithGrantSigner(signer),
+		Sunset:      sunset,
+	}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	do := func(t *testing.T, method, path, body string, wantCode int, c contract) map[string]any {
+		req, _ := http.NewRequest(method, server.URL+path, bytes.NewBufferString(body))
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, wantCode, resp.StatusCode)
+		if c.deprecated {
+			assert.Equal(t, "@1792368000", resp.Header.Get("Deprecation"))
+			assert.Equal(t, "Wed, 30 Jun 2027 00:00:00 GMT", resp.Header.Get("Sunset"))
+			assert.Contains(t, resp.Header.Get("Link"), `</docs>; rel="deprecation"`)
+			successor := strings.Replace(req.URL.Path, "/api/", "/api/v1/", 1)
+			assert.Contains(t, resp.Header.Get("Link"), "<"+successor+`>; rel="successor-version"`)
+		} else {
+			assert.Empty(t, resp.Header.Get("Deprecation"))
+			assert.Empty(t, resp.Header.Get("Sunset"))
+		}
+
+		res := make(map[string]any)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+		return res
+	}
+
+	for _, c := range []contract{contractV1, contractV2, contractUnversioned} {
+		t.Run(c.prefix, func(t *testing.T) {
+			created := do(t, http.MethodPost, c.prefix+"?cards=AS,KH,2C", "", http.StatusCreated, c)
+			assert.Equal(t, c.create, keys(created))
+			deckId := created[c.deckId].(string)
+
+			opened := do(t, http.MethodGet, c.prefix+"/"+deckId, "", http.StatusOK, c)
+			assert.Equal(t, c.open, keys(opened))
+			assert.Equal(t, c.card, keys(opened["cards"].([]any)[0].(map[string]any)))
+
+			drawn := do(t, http.MethodPost, c.prefix+"/"+deckId+"/draw", `{"count":1}`, http.StatusOK, c)
+			assert.Equal(t, c.draw, keys(drawn))
+
+			shared := do(t, http.MethodPost, c.prefix+"/"+deckId+"/share", `{"scope":"draw","max_cards":1}`, http.StatusOK, c)
+			assert.Equal(t, c.share, keys(shared))
+		})
+	}
+}
+
+func TestVersionsShareDecks(t *testing.T) {
+	srv := &server.Server{DeckService: deck.NewService(repo.NewInMemoryRepo())}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/api/v2/decks?cards=AS,KH", "application/json", nil)
+	require.NoError(t, err)
+	var created apiv2.Deck
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, apiv2.DeckLinks(created.Id), created.Links)
+	assert.Equal(t, created.Links.Self, resp.Header.Get("Location"))
+
+	resp, err = http.Post(server.URL+"/api/v1/deck/"+created.Id+"/draw?count=1", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + created.Links.Self)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var opened apiv2.DeckDetail
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&opened))
+	assert.Equal(t, 1, opened.Remaining)
+	assert.Equal(t, "KH", opened.Cards[0].Code)
+}
+
+// keys returns the sorted members of a JSON object.
+func keys(m map[string]any) []string {
+	ks := make([]string, 0, len(m))
+	for k := range m {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	return ks
+}
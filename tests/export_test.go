@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"toggl-card-game/api"
+	"toggl-card-game/api/apiv2"
+	"toggl-card-game/internal/auth"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/handlers"
+	"toggl-card-game/internal/repo"
+	"toggl-card-game/internal/server"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImport(t *testing.T) {
+	// decks are exported from one environment and imported into another one
+	newServer := func() *httptest.Server {
+		srv := &server.Server{
+			Auth:        auth.NewAuthenticator(map[string]string{"alice-key": "alice", "bob-key": "bob"}, nil),
+			DeckService: deck.NewService(repo.NewInMemoryRepo()),
+		}
+		return httptest.NewServer(srv.RegisterRoutes())
+	}
+	staging, production := newServer(), newServer()
+	defer staging.Close()
+	defer production.Close()
+
+	do := func(t *testing.T, server *httptest.Server, method, path, key string, body any, out any) *http.Response {
+		var b []byte
+		if body != nil {
+			b, _ = json.Marshal(body)
+		}
+		req, _ := http.NewRequest(method, server.URL+path, bytes.NewReader(b))
+		req.Header.Set("X-API-Key", key)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+		return resp
+	}
+
+	created := new(api.CreateResponse)
+	require.Equal(t, http.StatusCreated, do(t, staging, http.MethodPost, "/api/v1/deck?cards=AS,KH,QC,JD&face_down=true&face_up=QC", "alice-key", nil, created).StatusCode)
+	id := created.DeckId
+	batch := api.BatchRequest{Steps: []api.BatchStep{{Op: "draw", Count: 1}, {Op: "discard", Cards: []string{"AS"}}}}
+	require.Equal(t, http.StatusOK, do(t, staging, http.MethodPost, "/api/v1/deck/"+id+"/batch", "alice-key", batch, new(api.BatchResponse)).StatusCode)
+
+	exported := new(api.DeckExport)
+	require.Equal(t, http.StatusOK, do(t, staging, http.MethodGet, "/api/v1/deck/"+id+"/export", "alice-key", nil, exported).StatusCode)
+	assert.Equal(t, api.DeckExport{
+		Format:   "toggl-card-game/deck",
+		Version:  1,
+		Id:       id,
+		FaceDown: true,
+		Cards: []api.Card{
+			{Value: "KING", Suit: "HEARTS", Code: "KH", FaceDown: true},
+			{Value: "QUEEN", Suit: "CLUBS", Code: "QC"},
+			{Value: "JACK", Suit: "DIAMONDS", Code: "JD", FaceDown: true},
+		},
+		Discards: []api.Card{{Value: "ACE", Suit: "SPADES", Code: "AS"}},
+		Composition: []api.Card{
+			{Value: "ACE", Suit: "SPADES", Code: "AS", FaceDown: true},
+			{Value: "KING", Suit: "HEARTS", Code: "KH", FaceDown: true},
+			{Value: "QUEEN", Suit: "CLUBS", Code: "QC"},
+			{Value: "JACK", Suit: "DIAMONDS", Code: "JD", FaceDown: true},
+		},
+	}, *exported)
+
+	t.Run("import into another environment test", func(t *testing.T) {
+		imported := new(api.CreateResponse)
+		resp := do(t, production, http.MethodPost, "/api/v1/deck/import", "bob-key", exported, imported)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, "/api/v1/deck/"+id, resp.Header.Get("Location"))
+		assert.Equal(t, api.CreateResponse{DeckId: id, Remaining: 3}, *imported)
+
+		// the importer owns the deck
+		again := new(api.DeckExport)
+		require.Equal(t, http.StatusOK, do(t, production, http.MethodGet, "/api/v2/decks/"+id+"/export", "bob-key", nil, again).StatusCode)
+		assert.Equal(t, exported, again)
+
+		// the history stays behind, the imported deck starts anew
+		opened := new(api.OpenResponse)
+		require.Equal(t, http.StatusOK, do(t, production, http.MethodGet, "/api/v1/deck/"+id, "bob-key", nil, opened).StatusCode)
+		assert.Zero(t, opened.Version)
+	})
+
+	t.Run("restock the composition of an imported deck test", func(t *testing.T) {
+		for _, policy := range []string{"reshuffle_all", "refill"} {
+			created := new(api.CreateResponse)
+			require.Equal(t, http.StatusCreated, do(t, staging, http.MethodPost, "/api/v1/deck?cards=AS,KH,QC&on_empty="+policy, "alice-key", nil, created).StatusCode)
+			require.Equal(t, http.StatusOK, do(t, staging, http.MethodPost, "/api/v1/deck/"+created.DeckId+"/draw?count=2", "alice-key", nil, new(api.DrawResponse)).StatusCode)
+
+			partly := new(api.DeckExport)
+			require.Equal(t, http.StatusOK, do(t, staging, http.MethodGet, "/api/v1/deck/"+created.DeckId+"/export", "alice-key", nil, partly).StatusCode)
+			require.Equal(t, http.StatusCreated, do(t, production, http.MethodPost, "/api/v1/deck/import", "bob-key", partly, new(api.CreateResponse)).StatusCode)
+
+			drawn := new(api.DrawResponse)
+			require.Equal(t, http.StatusOK, do(t, production, http.MethodPost, "/api/v1/deck/"+created.DeckId+"/draw?count=2", "bob-key", nil, drawn).StatusCode)
+			assert.Len(t, drawn.Cards, 2, policy)
+			assert.Equal(t, &api.Reshuffle{Policy: policy, After: 1, Cards: 3}, drawn.Reshuffled, policy)
+		}
+	})
+
+	t.Run("import existing deck test", func(t *testing.T) {
+		problem := new(api.Problem)
+		resp := do(t, staging, http.MethodPost, "/api/v1/deck/import", "alice-key", exported, problem)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		assert.Equal(t, "/problems/deck-exists", problem.Type)
+	})
+
+	t.Run("no unversioned routes test", func(t *testing.T) {
+		for _, route := range []struct{ method, path string }{
+			{http.MethodGet, "/api/deck/" + id + "/export"},
+			{http.MethodPost, "/api/deck/import"},
+		} {
+			req, _ := http.NewRequest(route.method, staging.URL+route.path, nil)
+			req.Header.Set("X-API-Key", "alice-key")
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Contains(t, []int{http.StatusNotFound, http.StatusMethodNotAllowed}, resp.StatusCode, route.path)
+		}
+	})
+
+	t.Run("import without id in version 2 test", func(t *testing.T) {
+		anonymous := *exported
+		anonymous.Id = ""
+		imported := new(apiv2.Deck)
+		resp := do(t, staging, http.MethodPost, "/api/v2/decks/import", "alice-key", anonymous, imported)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.NotEqual(t, id, imported.Id)
+		assert.Equal(t, "/api/v2/decks/"+imported.Id, resp.Header.Get("Location"))
+	})
+
+	t.Run("import unsupported version test", func(t *testing.T) {
+		future := *exported
+		future.Version = 2
+		problem := new(api.Problem)
+		resp := do(t, production, http.MethodPost, "/api/v1/deck/import", "bob-key", future, problem)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, []api.FieldError{{Field: "version", Reason: "unsupported version 2, expected 1"}}, problem.Errors)
+	})
+
+	t.Run("import more cards than the largest shoe test", func(t *testing.T) {
+		huge := *exported
+		huge.Id = ""
+		for len(huge.Cards)+len(huge.Discards) <= deck.MaxShoeCards {
+			huge.Discards = append(huge.Discards, exported.Discards[0])
+		}
+		problem := new(api.Problem)
+		resp := do(t, production, http.MethodPost, "/api/v1/deck/import", "bob-key", huge, problem)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, []api.FieldError{{Field: "cards", Reason: "must hold at most 416 cards together with the discards, got 417"}}, problem.Errors)
+	})
+
+	t.Run("import body too large test", func(t *testing.T) {
+		body := strings.Repeat(" ", handlers.MaxBodyBytes) + "{}"
+		req, _ := http.NewRequest(http.MethodPost, production.URL+"/api/v1/deck/import", strings.NewReader(body))
+		req.Header.Set("X-API-Key", "bob-key")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		problem := new(api.Problem)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(problem))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+		assert.Equal(t, "/problems/request-too-large", problem.Type)
+	})
+
+	t.Run("export deck of another owner test", func(t *testing.T) {
+		problem := new(api.Problem)
+		resp := do(t, staging, http.MethodGet, "/api/v1/deck/"+id+"/export", "bob-key", nil, problem)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
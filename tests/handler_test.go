@@ -172,7 +172,7 @@ func TestHandleOpenDeck(t *testing.T) {
 		{
 			name:     "open non-existent deck test",
 			route:    fmt.Sprintf("/api/deck/%s", uuid.New().String()),
-			wantCode: http.StatusBadRequest,
+			wantCode: http.StatusNotFound,
 			verify: func(t *testing.T, res *http.Response) {
 				resBody := res.Body
 				defer resBody.Close()
@@ -180,6 +180,7 @@ func TestHandleOpenDeck(t *testing.T) {
 				apiErr := new(handlers.ApiError)
 				err := json.NewDecoder(resBody).Decode(apiErr)
 				assert.Nil(t, err)
+				assert.Equal(t, "/problems/deck-not-found", apiErr.Type)
 			},
 		},
 	}
@@ -280,7 +281,7 @@ func TestHandleDrawCards(t *testing.T) {
 				DeckId: uuid.NewString(),
 				Count:  3,
 			},
-			wantCode: http.StatusBadRequest,
+			wantCode: http.StatusNotFound,
 			verify: func(t *testing.T, res *http.Response) {
 				resBody := res.Body
 				defer resBody.Close()
@@ -288,6 +289,7 @@ func TestHandleDrawCards(t *testing.T) {
 				apiErr := new(handlers.ApiError)
 				err := json.NewDecoder(resBody).Decode(apiErr)
 				assert.Nil(t, err)
+				assert.Equal(t, "/problems/deck-not-found", apiErr.Type)
 			},
 		},
 	}
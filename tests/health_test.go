@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"toggl-card-game/internal/config"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/repo"
+	"toggl-card-game/internal/server"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthProbes(t *testing.T) {
+	memoryRepo := repo.NewInMemoryRepo()
+	srv := &server.Server{
+		Repo:        memoryRepo,
+		DeckService: deck.NewService(memoryRepo),
+	}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	get := func(route string) int {
+		resp, err := http.Get(server.URL + route)
+		if err != nil {
+			t.Fatalf("error making request to server. Err: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, get("/healthz"))
+	assert.Equal(t, http.StatusOK, get("/readyz"))
+
+	memoryRepo.Close()
+
+	assert.Equal(t, http.StatusOK, get("/healthz"))
+	assert.Equal(t, http.StatusServiceUnavailable, get("/readyz"))
+}
+
+func TestGracefulShutdown(t *testing.T) {
+	port := freePort(t)
+
+	cfg := config.Default()
+	cfg.Host = "127.0.0.1"
+	cfg.Port = port
+	cfg.GRPCPort = freePort(t)
+	cfg.ShutdownTimeout = 2 * time.Second
+
+	srv, err := server.New(cfg)
+	if err != nil {
+		t.Fatalf("unable to create server. Err: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Run(ctx) }()
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/readyz", port)
+	assert.Eventually(t, func() bool {
+		resp, err := http.Get(url)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 2*time.Second, 10*time.Millisecond)
+
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.Nil(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+
+	closed := srv.Repo.(*repo.InMemoryRepo)
+	assert.ErrorIs(t, closed.Ping(context.Background()), repo.ErrRepoClosed)
+	d, _ := deck.NewBuilder().Build()
+	_, err = closed.Create(context.Background(), d)
+	assert.ErrorIs(t, err, repo.ErrRepoClosed)
+}
+
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to find a free port. Err: %v", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
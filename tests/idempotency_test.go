@@ -0,0 +1,193 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"toggl-card-game/api"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/handlers"
+	"toggl-card-game/internal/idempotency"
+	"toggl-card-game/internal/ratelimit"
+	"toggl-card-game/internal/repo"
+	"toggl-card-game/internal/server"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotency(t *testing.T) {
+	srv := &server.Server{
+		DeckService: deck.NewService(repo.NewInMemoryRepo()),
+		Idempotency: idempotency.NewMemoryStore(time.Hour),
+	}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	do := func(t *testing.T, method, path, key string, out any) *http.Response {
+		req, _ := http.NewRequest(method, server.URL+path, nil)
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+		return resp
+	}
+	create := func(t *testing.T, key string) string {
+		created := new(api.CreateResponse)
+		resp := do(t, http.MethodPost, "/api/v1/deck?cards=AS,KH,QC", key, created)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		return created.DeckId
+	}
+	draw := func(t *testing.T, id, count, key string) (*http.Response, *api.DrawResponse) {
+		drawn := new(api.DrawResponse)
+		resp := do(t, http.MethodPost, "/api/v1/deck/"+id+"/draw?count="+count, key, drawn)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		return resp, drawn
+	}
+	remaining := func(t *testing.T, id string) int {
+		opened := new(api.OpenResponse)
+		require.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/deck/"+id, "", opened).StatusCode)
+		return opened.Remaining
+	}
+
+	t.Run("retried draw is replayed test", func(t *testing.T) {
+		id := create(t, "")
+		first, drawn := draw(t, id, "1", "draw-1")
+		retry, replayed := draw(t, id, "1", "draw-1")
+
+		assert.Empty(t, first.Header.Get("Idempotent-Replayed"))
+		assert.Equal(t, "true", retry.Header.Get("Idempotent-Replayed"))
+		assert.Equal(t, drawn, replayed)
+		assert.Equal(t, 2, remaining(t, id))
+		assert.NotEqual(t, first.Header.Get("X-Request-ID"), retry.Header.Get("X-Request-ID"))
+	})
+
+	t.Run("retried create is replayed test", func(t *testing.T) {
+		assert.Equal(t, create(t, "create-1"), create(t, "create-1"))
+	})
+
+	t.Run("key reused for another request test", func(t *testing.T) {
+		id := create(t, "")
+		draw(t, id, "1", "draw-2")
+
+		problem := new(api.Problem)
+		resp := do(t, http.MethodPost, "/api/v1/deck/"+id+"/draw?count=2", "draw-2", problem)
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+		assert.Equal(t, "/problems/idempotency-key-reused", problem.Type)
+	})
+
+	t.Run("keys are scoped to the deck test", func(t *testing.T) {
+		id, other := create(t, ""), create(t, "")
+		draw(t, id, "1", "draw-3")
+		retry, _ := draw(t, other, "1", "draw-3")
+		assert.Empty(t, retry.Header.Get("Idempotent-Replayed"))
+		assert.Equal(t, 2, remaining(t, other))
+	})
+
+	t.Run("requests without key are applied test", func(t *testing.T) {
+		id := create(t, "")
+		draw(t, id, "1", "")
+		draw(t, id, "1", "")
+		assert.Equal(t, 1, remaining(t, id))
+	})
+
+	t.Run("invalid key test", func(t *testing.T) {
+		problem := new(api.Problem)
+		resp := do(t, http.MethodPost, "/api/v1/deck/"+create(t, "")+"/draw?count=1", strings.Repeat("k", 129), problem)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, []api.FieldError{{Field: "Idempotency-Key", Reason: "must be 1 to 128 printable characters"}}, problem.Errors)
+	})
+
+	t.Run("body too large test", func(t *testing.T) {
+		body := `{"steps": [{"op": "draw", "count": 1}]}` + strings.Repeat(" ", handlers.MaxBodyBytes)
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/api/v1/deck/"+create(t, "")+"/batch", strings.NewReader(body))
+		req.Header.Set("Idempotency-Key", "large")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		problem := new(api.Problem)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(problem))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+		assert.Equal(t, "/problems/request-too-large", problem.Type)
+	})
+}
+
+// rejectFirst is a rate limit policy that rejects only the first request.
+type rejectFirst struct {
+	calls int
+}
+
+func (p *rejectFirst) Reserve(string) (ratelimit.Reservation, bool, time.Duration) {
+	p.calls++
+	return p, p.calls > 1, time.Second
+}
+
+func (p *rejectFirst) Cancel()   {}
+func (p *rejectFirst) Done(bool) {}
+
+func TestIdempotencyRetriesFailedRequests(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     handlers.Middleware
+		handler   func(calls int)
+		wantFirst int
+	}{
+		{
+			name:      "rate limited first try test",
+			limit:     handlers.RateLimit(new(rejectFirst)),
+			handler:   func(int) {},
+			wantFirst: http.StatusTooManyRequests,
+		},
+		{
+			name:  "panicking handler test",
+			limit: handlers.Noop,
+			handler: func(calls int) {
+				if calls == 1 {
+					panic("boom")
+				}
+			},
+			wantFirst: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				tt.handler(calls)
+				w.WriteHeader(http.StatusCreated)
+			})
+			server := httptest.NewServer(handlers.Chain(
+				handlers.Recover(slog.New(slog.NewTextHandler(io.Discard, nil))),
+				handlers.Idempotent(idempotency.NewMemoryStore(time.Hour)),
+				tt.limit,
+			)(handler))
+			defer server.Close()
+
+			post := func() *http.Response {
+				req, _ := http.NewRequest(http.MethodPost, server.URL+"/api/v1/deck", nil)
+				req.Header.Set("Idempotency-Key", "create-1")
+				resp, err := http.DefaultClient.Do(req)
+				require.NoError(t, err)
+				resp.Body.Close()
+				return resp
+			}
+
+			assert.Equal(t, tt.wantFirst, post().StatusCode)
+			retry := post()
+			assert.Equal(t, http.StatusCreated, retry.StatusCode)
+			assert.Empty(t, retry.Header.Get("Idempotent-Replayed"))
+			replayed := post()
+			assert.Equal(t, http.StatusCreated, replayed.StatusCode)
+			assert.Equal(t, "true", replayed.Header.Get("Idempotent-Replayed"))
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/metrics"
+	"toggl-card-game/internal/repo"
+	"toggl-card-game/internal/server"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	m := metrics.NewDeck(metrics.NewRegistry())
+	srv := &server.Server{
+		DeckService: deck.NewService(m.Repo(repo.NewInMemoryRepo())),
+		Metrics:     m,
+	}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/api/deck", "application/json", nil)
+	if err != nil {
+		t.Fatalf("error making request to server. Err: %v", err)
+	}
+	createRes := new(deck.CreateResponse)
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(createRes))
+	resp.Body.Close()
+
+	resp, err = http.Post(server.URL+"/api/deck/"+createRes.DeckId+"/draw?count=3", "application/json", nil)
+	if err != nil {
+		t.Fatalf("error making request to server. Err: %v", err)
+	}
+	resp.Body.Close()
+
+	resp, err = http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("error making request to server. Err: %v", err)
+	}
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/plain")
+
+	out, _ := io.ReadAll(resp.Body)
+	exposition := string(out)
+	for _, want := range []string{
+		"# TYPE http_requests_total counter",
+		`http_requests_total{route="/api/deck",method="POST",code="201"} 1`,
+		`http_requests_total{route="/api/deck/{UUID}/draw",method="POST",code="200"} 1`,
+		"# TYPE http_request_duration_seconds histogram",
+		`http_request_duration_seconds_count{route="/api/deck",method="POST"} 1`,
+		`http_request_duration_seconds_bucket{route="/api/deck",method="POST",le="+Inf"} 1`,
+		"decks_created_total 1",
+		"cards_drawn_total 3",
+		"active_decks 1",
+		`repo_operation_duration_seconds_count{operation="create",result="ok"} 1`,
+		`repo_operation_duration_seconds_count{operation="update",result="ok"} 1`,
+	} {
+		assert.Contains(t, exposition, want)
+	}
+}
+
+func TestMetricsBatch(t *testing.T) {
+	m := metrics.NewDeck(metrics.NewRegistry())
+	srv := &server.Server{
+		DeckService: deck.NewService(repo.NewInMemoryRepo()),
+		Metrics:     m,
+	}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/api/v1/deck?shuffled=false", "application/json", nil)
+	assert.NoError(t, err)
+	createRes := new(deck.CreateResponse)
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(createRes))
+	resp.Body.Close()
+
+	// only draw steps count, the discard and shuffle steps draw no cards
+	for _, batch := range []struct {
+		url  string
+		body string
+	}{
+		{
+			url:  server.URL + "/api/v1/deck/" + createRes.DeckId + "/batch",
+			body: `{"steps": [{"op": "draw", "count": 2}, {"op": "discard", "cards": ["AS"]}, {"op": "shuffle"}, {"op": "draw", "count": 1}]}`,
+		},
+		{
+			url:  server.URL + "/api/v2/decks/" + createRes.DeckId + "/batch",
+			body: `{"steps": [{"op": "draw", "count": 3}]}`,
+		},
+	} {
+		resp, err = http.Post(batch.url, "application/json", strings.NewReader(batch.body))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode, batch.url)
+		resp.Body.Close()
+	}
+
+	resp, err = http.Get(server.URL + "/metrics")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	out, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(out), "cards_drawn_total 6")
+}
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/handlers"
+	"toggl-card-game/internal/repo"
+	"toggl-card-game/internal/server"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddlewareChain(t *testing.T) {
+	logs := new(bytes.Buffer)
+	logger := slog.New(slog.NewJSONHandler(logs, nil))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	mux.HandleFunc("GET /fail", handlers.MakeHandler(func(w http.ResponseWriter, r *http.Request) error {
+		return handlers.NewApiError("failed", http.StatusConflict)
+	}))
+	mux.HandleFunc("GET /ok", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(handlers.RequestIDFrom(r.Context())))
+	})
+
+	server := httptest.NewServer(handlers.Chain(
+		handlers.RequestID,
+		handlers.AccessLog(logger),
+		handlers.Recover(logger),
+	)(mux))
+	defer server.Close()
+
+	get := func(route, requestId string) *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+route, nil)
+		if requestId != "" {
+			req.Header.Set(handlers.RequestIDHeader, requestId)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("error making request to server. Err: %v", err)
+		}
+		return resp
+	}
+
+	t.Run("propagate request id test", func(t *testing.T) {
+		resp := get("/ok", "abc-123")
+		defer resp.Body.Close()
+
+		body := new(bytes.Buffer)
+		body.ReadFrom(resp.Body)
+		assert.Equal(t, "abc-123", resp.Header.Get(handlers.RequestIDHeader))
+		assert.Equal(t, "abc-123", body.String())
+	})
+
+	t.Run("generate request id test", func(t *testing.T) {
+		resp := get("/ok", "")
+		defer resp.Body.Close()
+
+		_, err := uuid.Parse(resp.Header.Get(handlers.RequestIDHeader))
+		assert.Nil(t, err)
+	})
+
+	t.Run("replace invalid request id test", func(t *testing.T) {
+		resp := get("/ok", "bad id\twith spaces")
+		defer resp.Body.Close()
+
+		_, err := uuid.Parse(resp.Header.Get(handlers.RequestIDHeader))
+		assert.Nil(t, err)
+	})
+
+	t.Run("api error contains request id test", func(t *testing.T) {
+		resp := get("/fail", "req-1")
+		defer resp.Body.Close()
+
+		apiErr := new(handlers.ApiError)
+		assert.Nil(t, json.NewDecoder(resp.Body).Decode(apiErr))
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		assert.Equal(t, "req-1", apiErr.RequestId)
+	})
+
+	t.Run("recover from panic test", func(t *testing.T) {
+		logs.Reset()
+		resp := get("/panic", "req-2")
+		defer resp.Body.Close()
+
+		apiErr := new(handlers.ApiError)
+		assert.Nil(t, json.NewDecoder(resp.Body).Decode(apiErr))
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.Equal(t, http.StatusInternalServerError, apiErr.Status)
+		assert.Equal(t, "req-2", apiErr.RequestId)
+		assert.Contains(t, logs.String(), `"panic":"boom"`)
+	})
+
+	t.Run("access log test", func(t *testing.T) {
+		logs.Reset()
+		resp := get("/fail", "req-3")
+		resp.Body.Close()
+
+		entry := map[string]any{}
+		assert.Nil(t, json.Unmarshal(logs.Bytes(), &entry))
+		assert.Equal(t, "req-3", entry["request_id"])
+		assert.Equal(t, "/fail", entry["path"])
+		assert.Equal(t, float64(http.StatusConflict), entry["status"])
+		assert.Contains(t, entry, "latency")
+	})
+}
+
+func TestRoutesRequestId(t *testing.T) {
+	srv := &server.Server{
+		DeckService: deck.NewService(repo.NewInMemoryRepo()),
+		Logger:      slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)),
+	}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/deck/" + uuid.NewString())
+	if err != nil {
+		t.Fatalf("error making request to server. Err: %v", err)
+	}
+	defer resp.Body.Close()
+
+	apiErr := new(handlers.ApiError)
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(apiErr))
+	assert.NotEmpty(t, apiErr.RequestId)
+	assert.Equal(t, resp.Header.Get(handlers.RequestIDHeader), apiErr.RequestId)
+}
@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"toggl-card-game/api"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/repo"
+	"toggl-card-game/internal/server"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentNegotiation(t *testing.T) {
+	srv := &server.Server{DeckService: deck.NewService(repo.NewInMemoryRepo())}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	do := func(t *testing.T, method, path, accept string, body string) *http.Response {
+		req, _ := http.NewRequest(method, server.URL+path, bytes.NewBufferString(body))
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	read := func(t *testing.T, resp *http.Response) string {
+		b, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	created := new(api.CreateResponse)
+	resp := do(t, http.MethodPost, "/api/deck?cards=AS,KH,10D", "", "")
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(created))
+
+	tests := []struct {
+		name            string
+		method          string
+		path            string
+		body            string
+		accept          string
+		wantCode        int
+		wantContentType string
+		verify          func(t *testing.T, body string)
+	}{
+		{
+			name:            "open deck as xml test",
+			method:          http.MethodGet,
+			path:            "/api/deck/" + created.DeckId,
+			accept:          "application/xml",
+			wantCode:        http.StatusOK,
+			wantContentType: "application/xml",
+			verify: func(t *testing.T, body string) {
+				var res api.OpenResponse
+				require.NoError(t, xml.Unmarshal([]byte(body), &res))
+				assert.Equal(t, created.DeckId, res.DeckId)
+				assert.Len(t, res.Cards, 3)
+				assert.Equal(t, "AS", res.Cards[0].Code)
+			},
+		},
+		{
+			name:            "open deck as csv test",
+			method:          http.MethodGet,
+			path:            "/api/deck/" + created.DeckId,
+			accept:          "text/csv",
+			wantCode:        http.StatusOK,
+			wantContentType: "text/csv; charset=utf-8",
+			verify: func(t *testing.T, body string) {
+				assert.Equal(t, "value,suit,code\nACE,SPADES,AS\nKING,HEARTS,KH\n10,DIAMONDS,10D\n", body)
+			},
+		},
+		{
+			name:            "draw cards as text test",
+			method:          http.MethodPut,
+			path:            "/api/deck",
+			body:            `{"deck_id":"` + created.DeckId + `","count":2}`,
+			accept:          "text/plain",
+			wantCode:        http.StatusOK,
+			wantContentType: "text/plain; charset=utf-8",
+			verify: func(t *testing.T, body string) {
+				assert.Equal(t, "cards: A♠ K♥\n", body)
+			},
+		},
+		{
+			name:            "create deck as msgpack test",
+			method:          http.MethodPost,
+			path:            "/api/deck",
+			accept:          "application/msgpack",
+			wantCode:        http.StatusCreated,
+			wantContentType: "application/msgpack",
+			verify: func(t *testing.T, body string) {
+				assert.Equal(t, byte(0x83), body[0])
+				assert.Contains(t, body, "deck_id")
+			},
+		},
+		{
+			name:            "error as xml test",
+			method:          http.MethodGet,
+			path:            "/api/deck/" + uuid.NewString(),
+			accept:          "application/xml",
+			wantCode:        http.StatusNotFound,
+			wantContentType: "application/problem+xml",
+			verify: func(t *testing.T, body string) {
+				var res api.Problem
+				require.NoError(t, xml.Unmarshal([]byte(body), &res))
+				assert.Equal(t, http.StatusNotFound, res.Status)
+				assert.Equal(t, "/problems/deck-not-found", res.Type)
+			},
+		},
+		{
+			name:            "not acceptable test",
+			method:          http.MethodPost,
+			path:            "/api/deck",
+			accept:          "text/csv",
+			wantCode:        http.StatusNotAcceptable,
+			wantContentType: "application/problem+json",
+			verify: func(t *testing.T, body string) {
+				var res api.Problem
+				require.NoError(t, json.Unmarshal([]byte(body), &res))
+				assert.Equal(t, http.StatusNotAcceptable, res.Status)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := do(t, tt.method, tt.path, tt.accept, tt.body)
+
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+			assert.Equal(t, tt.wantContentType, resp.Header.Get("Content-Type"))
+			assert.Contains(t, resp.Header.Values("Vary"), "Accept")
+			tt.verify(t, read(t, resp))
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"toggl-card-game/api/apiv2"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/docs"
+	"toggl-card-game/internal/handlers"
+	"toggl-card-game/internal/metrics"
+	"toggl-card-game/internal/repo"
+	"toggl-card-game/internal/server"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type openAPIDoc struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"schemas"`
+	} `json:"components"`
+}
+
+func loadOpenAPI(t *testing.T) openAPIDoc {
+	var doc openAPIDoc
+	if err := json.Unmarshal(docs.OpenAPI, &doc); err != nil {
+		t.Fatalf("unable to parse openapi document. Err: %v", err)
+	}
+	return doc
+}
+
+// TestOpenAPIRoutes fails when a route is registered but not documented or vice versa.
+func TestOpenAPIRoutes(t *testing.T) {
+	doc := loadOpenAPI(t)
+
+	srv := &server.Server{
+		DeckService: deck.NewService(repo.NewInMemoryRepo()),
+		Metrics:     metrics.NewDeck(metrics.NewRegistry()),
+	}
+	srv.RegisterRoutes()
+
+	registered := append([]string(nil), srv.Routes()...)
+
+	documented := make([]string, 0)
+	for path, ops := range doc.Paths {
+		for method := range ops {
+			documented = append(documented, strings.ToUpper(method)+" "+path)
+		}
+	}
+
+	sort.Strings(registered)
+	sort.Strings(documented)
+	assert.Equal(t, registered, documented)
+}
+
+// TestOpenAPISchemas fails when the JSON shape of a DTO differs from its documented schema.
+func TestOpenAPISchemas(t *testing.T) {
+	doc := loadOpenAPI(t)
+
+	for name, dto := range map[string]any{
+		"Card":            deck.CardDto{},
+		"CreateResponse":  deck.CreateResponse{},
+		"OpenResponse":    deck.OpenResponse{},
+		"DrawRequest":     deck.DrawRequest{},
+		"DrawResponse":    deck.DrawResponse{},
+		"Reshuffle":       deck.Reshuffle{},
+		"Penetration":     deck.Penetration{},
+		"ShareRequest":    deck.ShareRequest{},
+		"ShareResponse":   deck.ShareResponse{},
+		"Problem":         handlers.ApiError{},
+		"FieldError":      deck.FieldError{},
+		"Page":            deck.Page{},
+		"SuitCount":       deck.SuitCount{},
+		"BatchStep":       deck.BatchStep{},
+		"BatchRequest":    deck.BatchRequest{},
+		"StepResult":      deck.StepResult{},
+		"BatchResponse":   deck.BatchResponse{},
+		"DeckExport":      deck.DeckExport{},
+		"Snapshot":        deck.SnapshotDto{},
+		"SnapshotList":    deck.SnapshotList{},
+		"RestoreResponse": deck.RestoreResponse{},
+		"LinksV2":         apiv2.Links{},
+		"DeckV2":          apiv2.Deck{},
+		"DeckDetailV2":    apiv2.DeckDetail{},
+		"DrawRequestV2":   apiv2.DrawRequest{},
+		"DrawV2":          apiv2.Draw{},
+		"ShareRequestV2":  apiv2.ShareRequest{},
+		"ShareV2":         apiv2.Share{},
+		"DeckExportV2":    apiv2.DeckExport{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			schema, ok := doc.Components.Schemas[name]
+			if !ok {
+				t.Fatalf("schema %s is not documented", name)
+			}
+
+			documented := make([]string, 0, len(schema.Properties))
+			for prop := range schema.Properties {
+				documented = append(documented, prop)
+			}
+
+			sort.Strings(documented)
+			assert.Equal(t, jsonFields(reflect.TypeOf(dto)), documented)
+		})
+	}
+}
+
+func TestOpenAPIEndpoints(t *testing.T) {
+	srv := &server.Server{DeckService: deck.NewService(repo.NewInMemoryRepo())}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	for route, contentType := range map[string]string{
+		"/openapi.json": "application/json",
+		"/docs":         "text/html",
+	} {
+		resp, err := http.Get(server.URL + route)
+		if err != nil {
+			t.Fatalf("error making request to server. Err: %v", err)
+		}
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, resp.Header.Get("Content-Type"), contentType)
+	}
+}
+
+// TestDocsPage fails when the documentation page loads code from outside the server.
+func TestDocsPage(t *testing.T) {
+	srv := &server.Server{DeckService: deck.NewService(repo.NewInMemoryRepo())}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/docs")
+	if err != nil {
+		t.Fatalf("error making request to server. Err: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	assert.NotContains(t, string(body), "src=\"http")
+	assert.NotContains(t, string(body), "{{script}}")
+	assert.Contains(t, resp.Header.Get("Content-Security-Policy"), "script-src 'sha256-")
+}
+
+// jsonFields returns the sorted JSON field names of a struct type.
+func jsonFields(typ reflect.Type) []string {
+	fields := make([]string, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"toggl-card-game/api"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/handlers"
+	"toggl-card-game/internal/repo"
+	"toggl-card-game/internal/server"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblemDetails(t *testing.T) {
+	srv := &server.Server{DeckService: deck.NewService(repo.NewInMemoryRepo())}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantType   string
+		wantFields []string
+	}{
+		{
+			name:       "invalid card codes test",
+			method:     http.MethodPost,
+			path:       "/api/deck?cards=AS,XX,KH,1Z",
+			wantStatus: http.StatusBadRequest,
+			wantType:   "/problems/invalid-request",
+			wantFields: []string{"cards[1]", "cards[3]"},
+		},
+		{
+			name:       "malformed json test",
+			method:     http.MethodPut,
+			path:       "/api/deck",
+			body:       `{"deck_id":`,
+			wantStatus: http.StatusBadRequest,
+			wantType:   "/problems/invalid-request",
+		},
+		{
+			name:       "non positive count test",
+			method:     http.MethodPut,
+			path:       "/api/deck",
+			body:       `{"deck_id":"7f0c2a54-8f4e-4b4e-8a3e-2f5d9c1b6a70","count":0}`,
+			wantStatus: http.StatusBadRequest,
+			wantType:   "/problems/invalid-request",
+			wantFields: []string{"count"},
+		},
+		{
+			name:       "invalid deck id test",
+			method:     http.MethodGet,
+			path:       "/api/deck/not-a-uuid",
+			wantStatus: http.StatusBadRequest,
+			wantType:   "/problems/invalid-request",
+			wantFields: []string{"UUID"},
+		},
+		{
+			name:       "unknown deck test",
+			method:     http.MethodGet,
+			path:       "/api/deck/7f0c2a54-8f4e-4b4e-8a3e-2f5d9c1b6a70",
+			wantStatus: http.StatusNotFound,
+			wantType:   "/problems/deck-not-found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, server.URL+tt.path, bytes.NewBufferString(tt.body))
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			assert.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+
+			var problem api.Problem
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+			assert.Equal(t, tt.wantType, problem.Type)
+			assert.Equal(t, tt.wantStatus, problem.Status)
+			assert.NotEmpty(t, problem.Title)
+			assert.NotEmpty(t, problem.Detail)
+			assert.Equal(t, strings.SplitN(tt.path, "?", 2)[0], problem.Instance)
+
+			fields := make([]string, len(problem.Errors))
+			for i, f := range problem.Errors {
+				fields[i] = f.Field
+				assert.NotEmpty(t, f.Reason)
+			}
+			assert.ElementsMatch(t, tt.wantFields, fields)
+		})
+	}
+}
+
+func TestProblemTypesAreDocumented(t *testing.T) {
+	readme, err := os.ReadFile("../README.md")
+	require.NoError(t, err)
+
+	for _, code := range handlers.Problems() {
+		assert.Contains(t, string(readme), "`"+handlers.ProblemType(code)+"`", "problem type %s is not documented", code)
+	}
+}
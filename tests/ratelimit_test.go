@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"toggl-card-game/internal/auth"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/handlers"
+	"toggl-card-game/internal/ratelimit"
+	"toggl-card-game/internal/repo"
+	"toggl-card-game/internal/server"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    ratelimit.Policy
+		requests  []string // api keys used for consecutive requests
+		wantCodes []int
+		minRetry  int
+	}{
+		{
+			name:      "token bucket limits burst per client test",
+			policy:    ratelimit.NewTokenBucket(0.001, 2),
+			requests:  []string{"alice-key", "alice-key", "alice-key", "bob-key"},
+			wantCodes: []int{http.StatusCreated, http.StatusCreated, http.StatusTooManyRequests, http.StatusCreated},
+			minRetry:  1,
+		},
+		{
+			name:      "daily quota limits created decks per client test",
+			policy:    ratelimit.All(ratelimit.NewTokenBucket(100, 100), ratelimit.NewDailyQuota(1)),
+			requests:  []string{"alice-key", "alice-key", "bob-key"},
+			wantCodes: []int{http.StatusCreated, http.StatusTooManyRequests, http.StatusCreated},
+			minRetry:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &server.Server{
+				Auth:        auth.NewAuthenticator(map[string]string{"alice-key": "alice", "bob-key": "bob"}, nil),
+				DeckService: deck.NewService(repo.NewInMemoryRepo()),
+				RateLimits:  map[string]ratelimit.Policy{server.RouteCreateDeck: tt.policy},
+			}
+			server := httptest.NewServer(srv.RegisterRoutes())
+			defer server.Close()
+
+			for i, key := range tt.requests {
+				req, _ := http.NewRequest(http.MethodPost, server.URL+"/api/deck", nil)
+				req.Header.Set("X-API-Key", key)
+
+				resp, err := http.DefaultClient.Do(req)
+				if err != nil {
+					t.Fatalf("error making request to server. Err: %v", err)
+				}
+
+				assert.Equal(t, tt.wantCodes[i], resp.StatusCode)
+				if resp.StatusCode == http.StatusTooManyRequests {
+					retry, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+					assert.Nil(t, err)
+					assert.GreaterOrEqual(t, retry, tt.minRetry)
+
+					apiErr := new(handlers.ApiError)
+					assert.Nil(t, json.NewDecoder(resp.Body).Decode(apiErr))
+					assert.Equal(t, http.StatusTooManyRequests, apiErr.Status)
+				}
+				resp.Body.Close()
+			}
+		})
+	}
+}
+
+func TestRateLimitCharges(t *testing.T) {
+	t.Run("rejected requests spend no tokens test", func(t *testing.T) {
+		bucket := ratelimit.NewTokenBucket(0.001, 1)
+		quota := ratelimit.NewDailyQuota(1)
+		policy := ratelimit.All(bucket, quota)
+
+		// the quota of alice is taken by another route that shares it
+		_, ok, _ := quota.Reserve("alice")
+		assert.True(t, ok)
+		_, ok, _ = policy.Reserve("alice")
+		assert.False(t, ok)
+		// the token taken before the quota rejected the request was given back
+		_, ok, _ = bucket.Reserve("alice")
+		assert.True(t, ok)
+	})
+
+	t.Run("failed creates do not count against the quota test", func(t *testing.T) {
+		srv := &server.Server{
+			Auth:        auth.NewAuthenticator(map[string]string{"alice-key": "alice"}, nil),
+			DeckService: deck.NewService(repo.NewInMemoryRepo()),
+			RateLimits:  map[string]ratelimit.Policy{server.RouteCreateDeck: ratelimit.NewDailyQuota(1)},
+		}
+		server := httptest.NewServer(srv.RegisterRoutes())
+		defer server.Close()
+
+		for i, tt := range []struct {
+			query    string
+			wantCode int
+		}{
+			{"?cards=XX", http.StatusBadRequest},
+			{"?cards=AS", http.StatusCreated},
+			{"?cards=AS", http.StatusTooManyRequests},
+		} {
+			req, _ := http.NewRequest(http.MethodPost, server.URL+"/api/v1/deck"+tt.query, nil)
+			req.Header.Set("X-API-Key", "alice-key")
+			resp, err := http.DefaultClient.Do(req)
+			assert.Nil(t, err)
+			resp.Body.Close()
+			assert.Equal(t, tt.wantCode, resp.StatusCode, "request %d", i)
+		}
+	})
+}
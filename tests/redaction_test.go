@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"toggl-card-game/api"
+	"toggl-card-game/internal/auth"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/repo"
+	"toggl-card-game/internal/server"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFaceDownRedaction(t *testing.T) {
+	signer := auth.NewSigner([]byte("secret"))
+	srv := &server.Server{
+		Auth:        auth.NewAuthenticator(map[string]string{"alice-key": "alice"}, nil),
+		Signer:      signer,
+		DeckService: deck.NewService(repo.NewInMemoryRepo()).WithGrantSigner(signer),
+	}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	do := func(t *testing.T, method, route string, headers map[string]string, body any, out any) int {
+		var b []byte
+		if body != nil {
+			b, _ = json.Marshal(body)
+		}
+		req, _ := http.NewRequest(method, server.URL+route, bytes.NewReader(b))
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		if out != nil {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+		}
+		return resp.StatusCode
+	}
+	owner := map[string]string{"X-API-Key": "alice-key"}
+
+	created := new(api.CreateResponse)
+	code := do(t, http.MethodPost, "/api/v1/deck?cards=AS,QH,10D&face_down=true&face_up=QH", owner, nil, created)
+	require.Equal(t, http.StatusCreated, code)
+
+	token := func(scope deck.Scope) map[string]string {
+		shared := new(api.ShareResponse)
+		require.Equal(t, http.StatusOK, do(t, http.MethodPost, "/api/v1/deck/"+created.DeckId+"/share", owner, api.ShareRequest{Scope: string(scope)}, shared))
+		return map[string]string{"X-Capability-Token": shared.Token}
+	}
+	back := api.Card{FaceDown: true}
+	queen := api.Card{Value: "QUEEN", Suit: "HEARTS", Code: "QH"}
+
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantCards  []api.Card
+		wantHidden int
+	}{
+		{
+			name:    "owner test",
+			headers: owner,
+			wantCards: []api.Card{
+				{Value: "ACE", Suit: "SPADES", Code: "AS", FaceDown: true},
+				queen,
+				{Value: "10", Suit: "DIAMONDS", Code: "10D", FaceDown: true},
+			},
+		},
+		{
+			name:       "player test",
+			headers:    token(deck.ScopeDraw),
+			wantCards:  []api.Card{back, queen, back},
+			wantHidden: 2,
+		},
+		{
+			name:       "spectator test",
+			headers:    token(deck.ScopeRead),
+			wantCards:  []api.Card{queen},
+			wantHidden: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opened := new(api.OpenResponse)
+			require.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/deck/"+created.DeckId, tt.headers, nil, opened))
+
+			assert.True(t, opened.FaceDown)
+			assert.Equal(t, 3, opened.Remaining)
+			assert.Equal(t, tt.wantHidden, opened.Hidden)
+			assert.Equal(t, tt.wantCards, opened.Cards)
+		})
+	}
+
+	t.Run("player draws faces test", func(t *testing.T) {
+		drawn := new(api.DrawResponse)
+		require.Equal(t, http.StatusOK, do(t, http.MethodPost, "/api/v1/deck/"+created.DeckId+"/draw?count=1", token(deck.ScopeDraw), nil, drawn))
+		assert.Equal(t, []api.Card{{Value: "ACE", Suit: "SPADES", Code: "AS"}}, drawn.Cards)
+	})
+
+	t.Run("face up card not in deck test", func(t *testing.T) {
+		problem := new(api.Problem)
+		code := do(t, http.MethodPost, "/api/v1/deck?cards=AS&face_down=true&face_up=KH", owner, nil, problem)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, []api.FieldError{{Field: "face_up[0]", Reason: `is not a card of the deck: "KH"`}}, problem.Errors)
+	})
+}
+
+func TestFaceDownWithoutAuth(t *testing.T) {
+	srv := &server.Server{DeckService: deck.NewService(repo.NewInMemoryRepo())}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	tests := []struct {
+		name  string
+		route string
+		body  any
+	}{
+		{
+			name:  "create test",
+			route: "/api/v1/deck?cards=AS,QH&face_down=true&face_up=QH",
+		},
+		{
+			name:  "import face down deck test",
+			route: "/api/v1/deck/import",
+			body: api.DeckExport{Format: deck.ExportFormat, Version: deck.ExportVersion, FaceDown: true,
+				Cards: []api.Card{{Code: "AS", FaceDown: true}}},
+		},
+		{
+			name:  "import face down card test",
+			route: "/api/v1/deck/import",
+			body: api.DeckExport{Format: deck.ExportFormat, Version: deck.ExportVersion,
+				Cards: []api.Card{{Code: "AS"}}, Discards: []api.Card{{Code: "KH", FaceDown: true}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b []byte
+			if tt.body != nil {
+				b, _ = json.Marshal(tt.body)
+			}
+			resp, err := http.Post(server.URL+tt.route, "application/json", bytes.NewReader(b))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			problem := new(api.Problem)
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(problem))
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			require.Len(t, problem.Errors, 1)
+			assert.Equal(t, "face_down", problem.Errors[0].Field)
+		})
+	}
+}
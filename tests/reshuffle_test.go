@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"toggl-card-game/api"
+	"toggl-card-game/api/apiv2"
+	"toggl-card-game/internal/auth"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/repo"
+	"toggl-card-game/internal/server"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReshuffleOnEmpty(t *testing.T) {
+	srv := &server.Server{
+		Auth:        auth.NewAuthenticator(map[string]string{"dealer-key": "dealer"}, nil),
+		DeckService: deck.NewService(repo.NewInMemoryRepo()),
+	}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	do := func(t *testing.T, method, path string, body any, out any) *http.Response {
+		var b []byte
+		if body != nil {
+			b, _ = json.Marshal(body)
+		}
+		req, _ := http.NewRequest(method, server.URL+path, bytes.NewReader(b))
+		req.Header.Set("X-API-Key", "dealer-key")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+		return resp
+	}
+
+	created := new(api.CreateResponse)
+	require.Equal(t, http.StatusCreated, do(t, http.MethodPost, "/api/v1/deck?cards=AS,KH,QC,JD&on_empty=reshuffle_discards", nil, created).StatusCode)
+	id := created.DeckId
+
+	// a round is dealt and its cards are discarded
+	batch := api.BatchRequest{Steps: []api.BatchStep{{Op: "draw", Count: 3}, {Op: "discard", Cards: []string{"AS", "KH", "QC"}}}}
+	require.Equal(t, http.StatusOK, do(t, http.MethodPost, "/api/v1/deck/"+id+"/batch", batch, new(api.BatchResponse)).StatusCode)
+
+	t.Run("draw past the last card test", func(t *testing.T) {
+		drawn := new(api.DrawResponse)
+		require.Equal(t, http.StatusOK, do(t, http.MethodPost, "/api/v1/deck/"+id+"/draw?count=2", nil, drawn).StatusCode)
+		require.Len(t, drawn.Cards, 2)
+		assert.Equal(t, "JD", drawn.Cards[0].Code)
+		assert.Contains(t, []string{"AS", "KH", "QC"}, drawn.Cards[1].Code)
+		assert.Equal(t, &api.Reshuffle{Policy: "reshuffle_discards", After: 1, Cards: 3}, drawn.Reshuffled)
+
+		opened := new(api.OpenResponse)
+		require.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/deck/"+id, nil, opened).StatusCode)
+		assert.Equal(t, "reshuffle_discards", opened.OnEmpty)
+		assert.Equal(t, 2, opened.Remaining)
+		assert.True(t, opened.Shuffled)
+	})
+
+	t.Run("draw without discards in version 2 test", func(t *testing.T) {
+		drawn := new(apiv2.Draw)
+		require.Equal(t, http.StatusOK, do(t, http.MethodPost, "/api/v2/decks/"+id+"/draw?count=3", nil, drawn).StatusCode)
+		assert.Len(t, drawn.Cards, 2)
+		assert.Nil(t, drawn.Reshuffled)
+	})
+
+	t.Run("unknown policy test", func(t *testing.T) {
+		problem := new(api.Problem)
+		resp := do(t, http.MethodPost, "/api/v2/decks?on_empty=sometimes", nil, problem)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, []api.FieldError{{Field: "on_empty", Reason: "must be one of none, reshuffle_discards, reshuffle_all or refill"}}, problem.Errors)
+	})
+}
@@ -0,0 +1,190 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"toggl-card-game/api"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/ratelimit"
+	"toggl-card-game/internal/repo"
+	"toggl-card-game/internal/server"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateDeckLocation(t *testing.T) {
+	srv := &server.Server{DeckService: deck.NewService(repo.NewInMemoryRepo())}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/api/v1/deck?cards=AS,KH", "application/json", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var created api.CreateResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "/api/v1/deck/"+created.DeckId, resp.Header.Get("Location"))
+
+	opened, err := http.Get(server.URL + resp.Header.Get("Location"))
+	require.NoError(t, err)
+	defer opened.Body.Close()
+	assert.Equal(t, http.StatusOK, opened.StatusCode)
+}
+
+func TestDrawSubresource(t *testing.T) {
+	srv := &server.Server{DeckService: deck.NewService(repo.NewInMemoryRepo())}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		query      string
+		body       string
+		wantCode   int
+		wantCards  int
+		wantFields []string
+	}{
+		{name: "count in query test", query: "?count=2", wantCode: http.StatusOK, wantCards: 2},
+		{name: "count in body test", body: `{"count":3}`, wantCode: http.StatusOK, wantCards: 3},
+		{name: "same count in query and body test", query: "?count=1", body: `{"count":1}`, wantCode: http.StatusOK, wantCards: 1},
+		{name: "different counts in query and body test", query: "?count=1", body: `{"count":2}`, wantCode: http.StatusBadRequest, wantFields: []string{"count"}},
+		{name: "missing count test", wantCode: http.StatusBadRequest, wantFields: []string{"count"}},
+		{name: "count not an integer test", query: "?count=two", wantCode: http.StatusBadRequest, wantFields: []string{"count"}},
+		{name: "malformed body test", body: `{"count":`, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(server.URL+"/api/v1/deck", "application/json", nil)
+			require.NoError(t, err)
+			var created api.CreateResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+			resp.Body.Close()
+
+			resp, err = http.Post(server.URL+"/api/v1/deck/"+created.DeckId+"/draw"+tt.query, "application/json", bytes.NewBufferString(tt.body))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+			if tt.wantCode != http.StatusOK {
+				var problem api.Problem
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+				fields := make([]string, len(problem.Errors))
+				for i, f := range problem.Errors {
+					fields[i] = f.Field
+				}
+				assert.ElementsMatch(t, tt.wantFields, fields)
+				return
+			}
+
+			var drawn api.DrawResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&drawn))
+			assert.Len(t, drawn.Cards, tt.wantCards)
+			assert.Empty(t, resp.Header.Get("Deprecation"))
+		})
+	}
+}
+
+func TestDeprecatedDrawRoute(t *testing.T) {
+	srv := &server.Server{
+		DeckService: deck.NewService(repo.NewInMemoryRepo()),
+		RateLimits:  map[string]ratelimit.Policy{server.RouteDrawCards: ratelimit.NewTokenBucket(0.001, 1)},
+	}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/api/v1/deck", "application/json", nil)
+	require.NoError(t, err)
+	var created api.CreateResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	resp.Body.Close()
+
+	body, _ := json.Marshal(api.DrawRequest{DeckId: created.DeckId, Count: 2})
+	req, _ := http.NewRequest(http.MethodPut, server.URL+"/api/v1/deck", bytes.NewReader(body))
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var drawn api.DrawResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&drawn))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, drawn.Cards, 2)
+	assert.Equal(t, "@1792368000", resp.Header.Get("Deprecation"))
+	assert.Equal(t, `</docs>; rel="deprecation"`, resp.Header.Get("Link"))
+
+	// the alias shares the rate limit of its successor
+	resp, err = http.Post(server.URL+"/api/v1/deck/"+created.DeckId+"/draw?count=1", "application/json", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+}
+
+func TestOpenDeckPages(t *testing.T) {
+	srv := &server.Server{DeckService: deck.NewService(repo.NewInMemoryRepo())}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/api/v1/deck", "application/json", nil)
+	require.NoError(t, err)
+	var created api.CreateResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	resp.Body.Close()
+
+	open := func(t *testing.T, query string) (*api.OpenResponse, *api.Problem) {
+		resp, err := http.Get(server.URL + "/api/v1/deck/" + created.DeckId + query)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			problem := new(api.Problem)
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(problem))
+			return nil, problem
+		}
+		res := new(api.OpenResponse)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(res))
+		return res, nil
+	}
+
+	t.Run("walk pages of card codes test", func(t *testing.T) {
+		var codes []string
+		for offset, pages := 0, 0; ; pages++ {
+			res, problem := open(t, fmt.Sprintf("?offset=%d&limit=20&fields=code", offset))
+			require.Nil(t, problem)
+			for _, c := range res.Cards {
+				assert.Empty(t, c.Value)
+				assert.Empty(t, c.Suit)
+				codes = append(codes, c.Code)
+			}
+			if res.Page.NextOffset == 0 {
+				assert.Equal(t, 2, pages)
+				break
+			}
+			offset = res.Page.NextOffset
+		}
+		assert.Len(t, codes, 52)
+	})
+
+	t.Run("summary test", func(t *testing.T) {
+		res, problem := open(t, "?summary=true")
+		require.Nil(t, problem)
+		assert.Nil(t, res.Cards)
+		assert.Len(t, res.Suits, 4)
+		assert.Equal(t, 52, res.Remaining)
+	})
+
+	t.Run("invalid parameters test", func(t *testing.T) {
+		_, problem := open(t, "?offset=first&limit=-1&fields=code,colour&summary=yes")
+		require.NotNil(t, problem)
+		assert.Equal(t, http.StatusBadRequest, problem.Status)
+		fields := make([]string, len(problem.Errors))
+		for i, f := range problem.Errors {
+			fields[i] = f.Field
+		}
+		assert.Equal(t, []string{"offset", "summary"}, fields)
+	})
+}
@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"toggl-card-game/api"
+	"toggl-card-game/api/apiv2"
+	"toggl-card-game/internal/auth"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/repo"
+	"toggl-card-game/internal/server"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShoe(t *testing.T) {
+	srv := &server.Server{
+		Auth:        auth.NewAuthenticator(map[string]string{"dealer-key": "dealer"}, nil),
+		DeckService: deck.NewService(repo.NewInMemoryRepo()),
+	}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	do := func(t *testing.T, method, path string, body any, out any) *http.Response {
+		var b []byte
+		if body != nil {
+			b, _ = json.Marshal(body)
+		}
+		req, _ := http.NewRequest(method, server.URL+path, bytes.NewReader(b))
+		req.Header.Set("X-API-Key", "dealer-key")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+		return resp
+	}
+
+	created := new(api.CreateResponse)
+	require.Equal(t, http.StatusCreated, do(t, http.MethodPost, "/api/v1/deck?decks=6&shuffled=true&penetration=75&auto_reshuffle=true", nil, created).StatusCode)
+	assert.Equal(t, 312, created.Remaining)
+	id := created.DeckId
+
+	t.Run("deal to the cut card test", func(t *testing.T) {
+		drawn := new(api.DrawResponse)
+		require.Equal(t, http.StatusOK, do(t, http.MethodPost, "/api/v1/deck/"+id+"/draw?count=233", nil, drawn).StatusCode)
+		assert.Equal(t, &api.Penetration{Percent: 75, CutCard: 234, Dealt: 233, AutoReshuffle: true}, drawn.Penetration)
+
+		// the cut card comes out in the last round, which is dealt to its end
+		require.Equal(t, http.StatusOK, do(t, http.MethodPost, "/api/v1/deck/"+id+"/draw?count=4", nil, drawn).StatusCode)
+		assert.Len(t, drawn.Cards, 4)
+		assert.Nil(t, drawn.Reshuffled)
+
+		opened := new(api.OpenResponse)
+		require.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/deck/"+id+"?summary=true", nil, opened).StatusCode)
+		assert.Equal(t, 75, opened.Remaining)
+		assert.Equal(t, &api.Penetration{Percent: 75, CutCard: 234, Dealt: 237, ReshuffleNeeded: true, AutoReshuffle: true}, opened.Penetration)
+	})
+
+	t.Run("reshuffle before the next round in version 2 test", func(t *testing.T) {
+		drawn := new(apiv2.Draw)
+		require.Equal(t, http.StatusOK, do(t, http.MethodPost, "/api/v2/decks/"+id+"/draw?count=2", nil, drawn).StatusCode)
+		assert.Equal(t, &apiv2.Reshuffle{Policy: "cut_card", Cards: 312}, drawn.Reshuffled)
+		assert.Equal(t, &apiv2.Penetration{Percent: 75, CutCard: 234, Dealt: 2, AutoReshuffle: true}, drawn.Penetration)
+
+		opened := new(apiv2.DeckDetail)
+		require.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v2/decks/"+id+"?summary=true", nil, opened).StatusCode)
+		assert.Equal(t, 310, opened.Remaining)
+		assert.False(t, opened.Penetration.ReshuffleNeeded)
+	})
+
+	t.Run("deck without cut card test", func(t *testing.T) {
+		created := new(api.CreateResponse)
+		require.Equal(t, http.StatusCreated, do(t, http.MethodPost, "/api/v1/deck", nil, created).StatusCode)
+		opened := new(api.OpenResponse)
+		require.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v1/deck/"+created.DeckId, nil, opened).StatusCode)
+		assert.Nil(t, opened.Penetration)
+	})
+
+	t.Run("invalid shoe test", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			query      string
+			wantFields []api.FieldError
+		}{
+			{"too many decks", "decks=9", []api.FieldError{{Field: "decks", Reason: "must be between 0 and 8, 0 for a single deck"}}},
+			{"penetration above 100", "penetration=120", []api.FieldError{{Field: "penetration", Reason: "must be between 0 and 100, 0 for no cut card"}}},
+			{"auto reshuffle without penetration", "auto_reshuffle=true", []api.FieldError{{Field: "auto_reshuffle", Reason: "requires a penetration"}}},
+			{"penetration not an integer", "penetration=most", []api.FieldError{{Field: "penetration", Reason: "must be an integer"}}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name+" test", func(t *testing.T) {
+				problem := new(api.Problem)
+				resp := do(t, http.MethodPost, "/api/v1/deck?"+tt.query, nil, problem)
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				assert.Equal(t, tt.wantFields, problem.Errors)
+			})
+		}
+	})
+}
@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"toggl-card-game/api"
+	"toggl-card-game/api/apiv2"
+	"toggl-card-game/internal/auth"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/repo"
+	"toggl-card-game/internal/server"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshots(t *testing.T) {
+	srv := &server.Server{
+		Auth:        auth.NewAuthenticator(map[string]string{"referee-key": "referee", "player-key": "player"}, nil),
+		DeckService: deck.NewService(repo.NewInMemoryRepo()),
+	}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	do := func(t *testing.T, method, path, key string, out any) *http.Response {
+		req, _ := http.NewRequest(method, server.URL+path, nil)
+		req.Header.Set("X-API-Key", key)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+		return resp
+	}
+	codes := func(t *testing.T, path string) []string {
+		opened := new(api.OpenResponse)
+		require.Equal(t, http.StatusOK, do(t, http.MethodGet, path, "referee-key", opened).StatusCode)
+		codes := make([]string, len(opened.Cards))
+		for i, c := range opened.Cards {
+			codes[i] = c.Code
+		}
+		return codes
+	}
+
+	created := new(api.CreateResponse)
+	require.Equal(t, http.StatusCreated, do(t, http.MethodPost, "/api/v1/deck?cards=AS,KH,QC,JD", "referee-key", created).StatusCode)
+	id := created.DeckId
+	require.Equal(t, http.StatusOK, do(t, http.MethodPost, "/api/v1/deck/"+id+"/draw?count=1", "referee-key", new(api.DrawResponse)).StatusCode)
+
+	snapshot := new(api.Snapshot)
+	require.Equal(t, http.StatusOK, do(t, http.MethodPut, "/api/v1/deck/"+id+"/snapshots/round-1", "referee-key", snapshot).StatusCode)
+	assert.Equal(t, "round-1", snapshot.Name)
+	assert.Equal(t, 1, snapshot.Version)
+	assert.Equal(t, 3, snapshot.Remaining)
+
+	// the dealer deals one card too many
+	require.Equal(t, http.StatusOK, do(t, http.MethodPost, "/api/v1/deck/"+id+"/draw?count=2", "referee-key", new(api.DrawResponse)).StatusCode)
+
+	t.Run("restore snapshot test", func(t *testing.T) {
+		restored := new(api.RestoreResponse)
+		require.Equal(t, http.StatusOK, do(t, http.MethodPost, "/api/v1/deck/"+id+"/snapshots/round-1/restore", "referee-key", restored).StatusCode)
+		assert.Equal(t, api.RestoreResponse{DeckId: id, Snapshot: "round-1", Version: 3, Remaining: 3}, *restored)
+		assert.Equal(t, []string{"KH", "QC", "JD"}, codes(t, "/api/v1/deck/"+id))
+	})
+
+	t.Run("open past versions test", func(t *testing.T) {
+		assert.Equal(t, []string{"AS", "KH", "QC", "JD"}, codes(t, "/api/v1/deck/"+id+"?version=0"))
+		assert.Equal(t, []string{"JD"}, codes(t, "/api/v1/deck/"+id+"?version=2"))
+
+		opened := new(apiv2.DeckDetail)
+		require.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v2/decks/"+id+"?version=1", "referee-key", opened).StatusCode)
+		assert.Equal(t, 1, opened.Version)
+		assert.Equal(t, 3, opened.Remaining)
+	})
+
+	t.Run("list snapshots in version 2 test", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, do(t, http.MethodPut, "/api/v2/decks/"+id+"/snapshots/round-0", "referee-key", new(apiv2.Snapshot)).StatusCode)
+
+		list := new(apiv2.SnapshotList)
+		require.Equal(t, http.StatusOK, do(t, http.MethodGet, "/api/v2/decks/"+id+"/snapshots", "referee-key", list).StatusCode)
+		require.Len(t, list.Snapshots, 2)
+		assert.Equal(t, []string{"round-0", "round-1"}, []string{list.Snapshots[0].Name, list.Snapshots[1].Name})
+		assert.Equal(t, 3, list.Snapshots[0].Version)
+	})
+
+	t.Run("errors test", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			method   string
+			path     string
+			key      string
+			wantCode int
+			wantType string
+		}{
+			{"unknown snapshot", http.MethodPost, "/api/v1/deck/" + id + "/snapshots/round-9/restore", "referee-key", http.StatusNotFound, "/problems/snapshot-not-found"},
+			{"future version", http.MethodGet, "/api/v1/deck/" + id + "?version=9", "referee-key", http.StatusNotFound, "/problems/version-not-found"},
+			{"invalid version", http.MethodGet, "/api/v1/deck/" + id + "?version=latest", "referee-key", http.StatusBadRequest, "/problems/invalid-request"},
+			{"invalid name", http.MethodPut, "/api/v1/deck/" + id + "/snapshots/round%201", "referee-key", http.StatusBadRequest, "/problems/invalid-request"},
+			{"snapshot by another subject", http.MethodPut, "/api/v1/deck/" + id + "/snapshots/round-2", "player-key", http.StatusForbidden, "/problems/forbidden"},
+			{"restore by another subject", http.MethodPost, "/api/v1/deck/" + id + "/snapshots/round-1/restore", "player-key", http.StatusForbidden, "/problems/forbidden"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name+" test", func(t *testing.T) {
+				problem := new(api.Problem)
+				resp := do(t, tt.method, tt.path, tt.key, problem)
+				assert.Equal(t, tt.wantCode, resp.StatusCode)
+				assert.Equal(t, tt.wantType, problem.Type)
+			})
+		}
+	})
+}
+
+func TestVersionRetention(t *testing.T) {
+	srv := &server.Server{DeckService: deck.NewService(repo.NewInMemoryRepo().WithVersionRetention(2))}
+	server := httptest.NewServer(srv.RegisterRoutes())
+	defer server.Close()
+
+	do := func(t *testing.T, method, path string, out any) int {
+		req, _ := http.NewRequest(method, server.URL+path, nil)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+		return resp.StatusCode
+	}
+
+	created := new(api.CreateResponse)
+	require.Equal(t, http.StatusCreated, do(t, http.MethodPost, "/api/v1/deck?cards=AS,KH,QC,JD", created))
+	id := created.DeckId
+	require.Equal(t, http.StatusOK, do(t, http.MethodPut, "/api/v1/deck/"+id+"/snapshots/created", new(api.Snapshot)))
+	for range 3 {
+		require.Equal(t, http.StatusOK, do(t, http.MethodPost, "/api/v1/deck/"+id+"/draw?count=1", new(api.DrawResponse)))
+	}
+
+	tests := []struct {
+		name          string
+		version       string
+		wantCode      int
+		wantRemaining int
+	}{
+		{name: "evicted version test", version: "0", wantCode: http.StatusNotFound},
+		{name: "evicted version after create test", version: "1", wantCode: http.StatusNotFound},
+		{name: "oldest retained version test", version: "2", wantCode: http.StatusOK, wantRemaining: 2},
+		{name: "latest version test", version: "3", wantCode: http.StatusOK, wantRemaining: 1},
+		{name: "future version test", version: "4", wantCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opened api.OpenResponse
+			var problem api.Problem
+			out := any(&opened)
+			if tt.wantCode != http.StatusOK {
+				out = &problem
+			}
+			require.Equal(t, tt.wantCode, do(t, http.MethodGet, "/api/v1/deck/"+id+"?version="+tt.version, out))
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, tt.wantRemaining, opened.Remaining)
+			} else {
+				assert.Equal(t, "/problems/version-not-found", problem.Type)
+			}
+		})
+	}
+
+	t.Run("snapshots outlive evicted versions test", func(t *testing.T) {
+		restored := new(api.RestoreResponse)
+		require.Equal(t, http.StatusOK, do(t, http.MethodPost, "/api/v1/deck/"+id+"/snapshots/created/restore", restored))
+		assert.Equal(t, api.RestoreResponse{DeckId: id, Snapshot: "created", Version: 4, Remaining: 4}, *restored)
+	})
+}
@@ -0,0 +1,432 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"toggl-card-game/api"
+	"toggl-card-game/api/deckv1"
+	"toggl-card-game/client"
+	"toggl-card-game/internal/auth"
+	"toggl-card-game/internal/core/deck"
+	"toggl-card-game/internal/repo"
+	"toggl-card-game/internal/server"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// transport is a client of one of the api transports, the behavioural tests run against every transport.
+type transport interface {
+	create(ctx context.Context, opts client.CreateOptions) (*api.CreateResponse, error)
+	open(ctx context.Context, id string) (*api.OpenResponse, error)
+	draw(ctx context.Context, id string, count int) (*api.DrawResponse, error)
+}
+
+// credentials of a transport client, both are optional.
+type credentials struct {
+	apiKey     string
+	capability string
+}
+
+// outcome classifies the result of a call independently of the transport.
+type outcome string
+
+const (
+	outcomeOK              outcome = "ok"
+	outcomeUnauthenticated outcome = "unauthenticated"
+	outcomeForbidden       outcome = "forbidden"
+	outcomeRejected        outcome = "rejected"
+)
+
+func outcomeOf(err error) outcome {
+	if err == nil {
+		return outcomeOK
+	}
+
+	var problem api.Problem
+	if errors.As(err, &problem) {
+		switch problem.Status {
+		case http.StatusUnauthorized:
+			return outcomeUnauthenticated
+		case http.StatusForbidden:
+			return outcomeForbidden
+		case http.StatusBadRequest, http.StatusNotFound:
+			return outcomeRejected
+		}
+	}
+
+	switch status.Code(err) {
+	case codes.Unauthenticated:
+		return outcomeUnauthenticated
+	case codes.PermissionDenied:
+		return outcomeForbidden
+	case codes.InvalidArgument, codes.NotFound:
+		return outcomeRejected
+	}
+	return outcome(err.Error())
+}
+
+type httpTransport struct {
+	c *client.Client
+}
+
+func (x httpTransport) create(ctx context.Context, opts client.CreateOptions) (*api.CreateResponse, error) {
+	return x.c.CreateDeck(ctx, opts)
+}
+
+func (x httpTransport) open(ctx context.Context, id string) (*api.OpenResponse, error) {
+	return x.c.OpenDeck(ctx, id)
+}
+
+func (x httpTransport) draw(ctx context.Context, id string, count int) (*api.DrawResponse, error) {
+	return x.c.DrawCards(ctx, id, count)
+}
+
+type grpcTransport struct {
+	c  deckv1.DeckServiceClient
+	md metadata.MD
+}
+
+func (x grpcTransport) create(ctx context.Context, opts client.CreateOptions) (*api.CreateResponse, error) {
+	res, err := x.c.CreateDeck(metadata.NewOutgoingContext(ctx, x.md), &deckv1.CreateDeckRequest{
+		Shuffled:      opts.Shuffled,
+		Cards:         opts.Cards,
+		FaceDown:      opts.FaceDown,
+		FaceUp:        opts.FaceUp,
+		OnEmpty:       opts.OnEmpty,
+		Decks:         int32(opts.Decks),
+		Penetration:   int32(opts.Penetration),
+		AutoReshuffle: opts.AutoReshuffle,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &api.CreateResponse{DeckId: res.DeckId, Shuffled: res.Shuffled, Remaining: int(res.Remaining)}, nil
+}
+
+func (x grpcTransport) open(ctx context.Context, id string) (*api.OpenResponse, error) {
+	res, err := x.c.OpenDeck(metadata.NewOutgoingContext(ctx, x.md), &deckv1.OpenDeckRequest{DeckId: id})
+	if err != nil {
+		return nil, err
+	}
+	return &api.OpenResponse{
+		DeckId:      res.DeckId,
+		Shuffled:    res.Shuffled,
+		Remaining:   int(res.Remaining),
+		Version:     int(res.Version),
+		OnEmpty:     res.OnEmpty,
+		Penetration: penetrationFromPb(res.Penetration),
+		FaceDown:    res.FaceDown,
+		Hidden:      int(res.Hidden),
+		Cards:       fromPb(res.Cards),
+	}, nil
+}
+
+func (x grpcTransport) draw(ctx context.Context, id string, count int) (*api.DrawResponse, error) {
+	res, err := x.c.DrawCards(metadata.NewOutgoingContext(ctx, x.md), &deckv1.DrawCardsRequest{DeckId: id, Count: int32(count)})
+	if err != nil {
+		return nil, err
+	}
+	out := &api.DrawResponse{Cards: fromPb(res.Cards), Penetration: penetrationFromPb(res.Penetration)}
+	if r := res.Reshuffled; r != nil {
+		out.Reshuffled = &api.Reshuffle{Policy: r.Policy, After: int(r.After), Cards: int(r.Cards)}
+	}
+	return out, nil
+}
+
+func fromPb(cards []*deckv1.Card) []api.Card {
+	out := make([]api.Card, len(cards))
+	for i, c := range cards {
+		out[i] = api.Card{Value: c.Value, Suit: c.Suit, Code: c.Code, FaceDown: c.FaceDown}
+	}
+	return out
+}
+
+func penetrationFromPb(p *deckv1.Penetration) *api.Penetration {
+	if p == nil {
+		return nil
+	}
+	return &api.Penetration{
+		Percent:         int(p.Percent),
+		CutCard:         int(p.CutCard),
+		Dealt:           int(p.Dealt),
+		ReshuffleNeeded: p.ReshuffleNeeded,
+		AutoReshuffle:   p.AutoReshuffle,
+	}
+}
+
+// sequenced are the options of an unshuffled deck of the cards, a full deck when there are none.
+func sequenced(cards ...string) client.CreateOptions {
+	return client.CreateOptions{Cards: cards, Shuffled: new(bool)}
+}
+
+// startTransports serves the server over http and gRPC and returns a client factory per transport.
+func startTransports(t *testing.T, srv *server.Server) map[string]func(credentials) transport {
+	httpServer := httptest.NewServer(srv.RegisterRoutes())
+	t.Cleanup(httpServer.Close)
+
+	lis := bufconn.Listen(1 << 20)
+	grpcServer := srv.RegisterGRPC()
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return map[string]func(credentials) transport{
+		"http": func(cr credentials) transport {
+			opts := []client.Option{client.WithRetries(0, 0)}
+			if cr.apiKey != "" {
+				opts = append(opts, client.WithAPIKey(cr.apiKey))
+			}
+			if cr.capability != "" {
+				opts = append(opts, client.WithCapabilityToken(cr.capability))
+			}
+			c, err := client.New(httpServer.URL, opts...)
+			require.NoError(t, err)
+			return httpTransport{c: c}
+		},
+		"grpc": func(cr credentials) transport {
+			md := metadata.MD{}
+			if cr.apiKey != "" {
+				md.Set("x-api-key", cr.apiKey)
+			}
+			if cr.capability != "" {
+				md.Set("x-capability-token", cr.capability)
+			}
+			return grpcTransport{c: deckv1.NewDeckServiceClient(conn), md: md}
+		},
+	}
+}
+
+func TestTransports(t *testing.T) {
+	signer := auth.NewSigner([]byte("secret"))
+	svc := deck.NewService(repo.NewInMemoryRepo()).WithGrantSigner(signer)
+	srv := &server.Server{
+		Auth:        auth.NewAuthenticator(map[string]string{"alice-key": "alice", "bob-key": "bob"}, nil),
+		Signer:      signer,
+		DeckService: svc,
+	}
+	alice := credentials{apiKey: "alice-key"}
+	bob := credentials{apiKey: "bob-key"}
+
+	share := func(t *testing.T, id string, scope deck.Scope) credentials {
+		res, err := svc.ShareDeck(context.Background(), deck.ShareRequest{DeckId: id, Caller: "alice", Scope: scope})
+		require.NoError(t, err)
+		return credentials{capability: res.Token}
+	}
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T, dial func(credentials) transport)
+	}{
+		{
+			name: "create open and draw a full sequenced deck test",
+			run: func(t *testing.T, dial func(credentials) transport) {
+				ctx := context.Background()
+				c := dial(alice)
+
+				created, err := c.create(ctx, sequenced())
+				require.NoError(t, err)
+				assert.Equal(t, 52, created.Remaining)
+				assert.False(t, created.Shuffled)
+
+				drawn, err := c.draw(ctx, created.DeckId, 2)
+				require.NoError(t, err)
+				assert.Equal(t, []string{"AS", "2S"}, cardCodes(drawn.Cards))
+
+				opened, err := c.open(ctx, created.DeckId)
+				require.NoError(t, err)
+				assert.Equal(t, 50, opened.Remaining)
+				assert.Len(t, opened.Cards, 50)
+			},
+		},
+		{
+			name: "create a partial deck test",
+			run: func(t *testing.T, dial func(credentials) transport) {
+				ctx := context.Background()
+				c := dial(alice)
+
+				created, err := c.create(ctx, sequenced("KH", "10D"))
+				require.NoError(t, err)
+				assert.Equal(t, 2, created.Remaining)
+
+				opened, err := c.open(ctx, created.DeckId)
+				require.NoError(t, err)
+				assert.Equal(t, []string{"KH", "10D"}, cardCodes(opened.Cards))
+			},
+		},
+		{
+			name: "face down deck test",
+			run: func(t *testing.T, dial func(credentials) transport) {
+				ctx := context.Background()
+				opts := sequenced("AS", "KH", "QD")
+				opts.FaceDown, opts.FaceUp = true, []string{"KH"}
+				created, err := dial(alice).create(ctx, opts)
+				require.NoError(t, err)
+
+				opened, err := dial(alice).open(ctx, created.DeckId)
+				require.NoError(t, err)
+				assert.True(t, opened.FaceDown)
+				assert.Equal(t, []api.Card{
+					{Value: "ACE", Suit: "SPADES", Code: "AS", FaceDown: true},
+					{Value: "KING", Suit: "HEARTS", Code: "KH"},
+					{Value: "QUEEN", Suit: "DIAMONDS", Code: "QD", FaceDown: true},
+				}, opened.Cards)
+
+				spectator, err := dial(share(t, created.DeckId, deck.ScopeRead)).open(ctx, created.DeckId)
+				require.NoError(t, err)
+				assert.Equal(t, 2, spectator.Hidden)
+				assert.Equal(t, []string{"KH"}, cardCodes(spectator.Cards))
+
+				drawn, err := dial(share(t, created.DeckId, deck.ScopeDraw)).draw(ctx, created.DeckId, 1)
+				require.NoError(t, err)
+				assert.Equal(t, []api.Card{{Value: "ACE", Suit: "SPADES", Code: "AS"}}, drawn.Cards, "drawn cards are turned face up")
+			},
+		},
+		{
+			name: "refill an empty deck test",
+			run: func(t *testing.T, dial func(credentials) transport) {
+				ctx := context.Background()
+				opts := sequenced("AS", "2S")
+				opts.OnEmpty = "refill"
+				created, err := dial(alice).create(ctx, opts)
+				require.NoError(t, err)
+
+				drawn, err := dial(alice).draw(ctx, created.DeckId, 3)
+				require.NoError(t, err)
+				assert.Len(t, drawn.Cards, 3)
+				assert.Equal(t, &api.Reshuffle{Policy: "refill", After: 2, Cards: 2}, drawn.Reshuffled)
+
+				opened, err := dial(alice).open(ctx, created.DeckId)
+				require.NoError(t, err)
+				assert.Equal(t, "refill", opened.OnEmpty)
+				assert.Equal(t, 1, opened.Version)
+				assert.Equal(t, 1, opened.Remaining)
+			},
+		},
+		{
+			name: "shoe with a cut card test",
+			run: func(t *testing.T, dial func(credentials) transport) {
+				ctx := context.Background()
+				opts := sequenced()
+				opts.Decks, opts.Penetration, opts.AutoReshuffle = 2, 50, true
+				created, err := dial(alice).create(ctx, opts)
+				require.NoError(t, err)
+				assert.Equal(t, 104, created.Remaining)
+
+				drawn, err := dial(alice).draw(ctx, created.DeckId, 3)
+				require.NoError(t, err)
+				want := &api.Penetration{Percent: 50, CutCard: 52, Dealt: 3, AutoReshuffle: true}
+				assert.Equal(t, want, drawn.Penetration)
+
+				opened, err := dial(alice).open(ctx, created.DeckId)
+				require.NoError(t, err)
+				assert.Equal(t, want, opened.Penetration)
+			},
+		},
+		{
+			name: "missing credentials test",
+			run: func(t *testing.T, dial func(credentials) transport) {
+				_, err := dial(credentials{}).create(context.Background(), sequenced())
+				assert.Equal(t, outcomeUnauthenticated, outcomeOf(err))
+			},
+		},
+		{
+			name: "unknown and invalid deck test",
+			run: func(t *testing.T, dial func(credentials) transport) {
+				ctx := context.Background()
+				c := dial(alice)
+
+				_, err := c.open(ctx, uuid.NewString())
+				assert.Equal(t, outcomeRejected, outcomeOf(err))
+				_, err = c.draw(ctx, uuid.NewString(), 1)
+				assert.Equal(t, outcomeRejected, outcomeOf(err))
+				_, err = c.open(ctx, "not-a-uuid")
+				assert.Equal(t, outcomeRejected, outcomeOf(err))
+			},
+		},
+		{
+			name: "deck of another client test",
+			run: func(t *testing.T, dial func(credentials) transport) {
+				ctx := context.Background()
+				created, err := dial(alice).create(ctx, client.CreateOptions{})
+				require.NoError(t, err)
+
+				_, err = dial(bob).open(ctx, created.DeckId)
+				assert.Equal(t, outcomeForbidden, outcomeOf(err))
+				_, err = dial(bob).draw(ctx, created.DeckId, 1)
+				assert.Equal(t, outcomeForbidden, outcomeOf(err))
+			},
+		},
+		{
+			name: "capability tokens test",
+			run: func(t *testing.T, dial func(credentials) transport) {
+				ctx := context.Background()
+				created, err := dial(alice).create(ctx, sequenced())
+				require.NoError(t, err)
+
+				reader := dial(share(t, created.DeckId, deck.ScopeRead))
+				_, err = reader.open(ctx, created.DeckId)
+				assert.Equal(t, outcomeOK, outcomeOf(err))
+				_, err = reader.draw(ctx, created.DeckId, 1)
+				assert.Equal(t, outcomeForbidden, outcomeOf(err))
+
+				drawer := dial(share(t, created.DeckId, deck.ScopeDraw))
+				drawn, err := drawer.draw(ctx, created.DeckId, 1)
+				require.NoError(t, err)
+				assert.Equal(t, []string{"AS"}, cardCodes(drawn.Cards))
+			},
+		},
+	}
+
+	for name, dial := range startTransports(t, srv) {
+		t.Run(name, func(t *testing.T) {
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					tt.run(t, dial)
+				})
+			}
+		})
+	}
+}
+
+func TestTransportsShareDecks(t *testing.T) {
+	srv := &server.Server{DeckService: deck.NewService(repo.NewInMemoryRepo())}
+	transports := startTransports(t, srv)
+	ctx := context.Background()
+
+	created, err := transports["http"](credentials{}).create(ctx, sequenced())
+	require.NoError(t, err)
+
+	drawn, err := transports["grpc"](credentials{}).draw(ctx, created.DeckId, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AS", "2S", "3S"}, cardCodes(drawn.Cards))
+
+	opened, err := transports["http"](credentials{}).open(ctx, created.DeckId)
+	require.NoError(t, err)
+	assert.Equal(t, 49, opened.Remaining)
+}
+
+func cardCodes(cards []api.Card) []string {
+	out := make([]string, len(cards))
+	for i, c := range cards {
+		out[i] = c.Code
+	}
+	return out
+}